@@ -78,6 +78,30 @@ func (e BottomLiteralExpr) n() nodetype {
 	return nt
 }
 
+type BytesLiteralExpr struct {
+	Value tokens.Token
+}
+
+func (e BytesLiteralExpr) e() nodetype {
+	return nt
+}
+
+func (e BytesLiteralExpr) n() nodetype {
+	return nt
+}
+
+type RegexLiteralExpr struct {
+	Value tokens.Token
+}
+
+func (e RegexLiteralExpr) e() nodetype {
+	return nt
+}
+
+func (e RegexLiteralExpr) n() nodetype {
+	return nt
+}
+
 type BooleanLiteralExpr struct {
 	Value tokens.Token
 }
@@ -102,6 +126,18 @@ func (e TupleLiteralExpr) n() nodetype {
 	return nt
 }
 
+type SetLiteralExpr struct {
+	Contents []Expr
+}
+
+func (e SetLiteralExpr) e() nodetype {
+	return nt
+}
+
+func (e SetLiteralExpr) n() nodetype {
+	return nt
+}
+
 type SpreadExpr struct {
 	Expr Expr
 }
@@ -145,6 +181,7 @@ type FuncExpr struct {
 		Specified bool
 		Tk        *tokens.Token
 	}
+	Generator bool // The body yields, so calls return an iterator
 }
 
 func (e FuncExpr) e() nodetype {
@@ -157,6 +194,7 @@ func (e FuncExpr) n() nodetype {
 
 type CallExpr struct {
 	Callee    Expr
+	Paren     tokens.Token
 	Arguments []Expr
 }
 
@@ -180,6 +218,18 @@ func (e MeExpr) n() nodetype {
 	return nt
 }
 
+type SuperExpr struct {
+	Token tokens.Token
+}
+
+func (e SuperExpr) e() nodetype {
+	return nt
+}
+
+func (e SuperExpr) n() nodetype {
+	return nt
+}
+
 type QuestionExpr struct {
 	Token tokens.Token
 }
@@ -194,6 +244,8 @@ func (e QuestionExpr) n() nodetype {
 
 type ProtoExpr struct {
 	MethodSet []ProtoMethod
+	Parents   []Expr
+	Protocols []Expr
 }
 
 func (e ProtoExpr) e() nodetype {
@@ -221,6 +273,7 @@ func (e RecordLiteralExpr) n() nodetype {
 
 type GetExpr struct {
 	Gettee Expr
+	Arrow  tokens.Token
 	Field  Expr
 }
 
@@ -232,6 +285,34 @@ func (e GetExpr) n() nodetype {
 	return nt
 }
 
+type RangeExpr struct {
+	Start    Expr
+	End      Expr
+	Step     Expr
+	Operator tokens.Token
+}
+
+func (e RangeExpr) e() nodetype {
+	return nt
+}
+
+func (e RangeExpr) n() nodetype {
+	return nt
+}
+
+type LoopExpr struct {
+	Label *tokens.Token
+	Body  Block
+}
+
+func (e LoopExpr) e() nodetype {
+	return nt
+}
+
+func (e LoopExpr) n() nodetype {
+	return nt
+}
+
 type VarDeclStmt struct {
 	Names  []Identifier
 	Values []Expr
@@ -288,6 +369,7 @@ func (s ReturnStmt) n() nodetype {
 }
 
 type WhileStmt struct {
+	Label     *tokens.Token
 	Decls     VarDeclStmt
 	Condition Expr
 	Block     Node
@@ -297,14 +379,76 @@ func (s WhileStmt) n() nodetype {
 	return nt
 }
 
-type ContinueStmt struct{}
+type ContinueStmt struct {
+	Label *tokens.Token
+}
 
 func (s ContinueStmt) n() nodetype {
 	return nt
 }
 
-type BreakStmt struct{}
+type BreakStmt struct {
+	Label *tokens.Token
+	Expr  Expr
+}
 
 func (s BreakStmt) n() nodetype {
 	return nt
 }
+
+type DeferStmt struct {
+	Expr Expr
+}
+
+func (s DeferStmt) n() nodetype {
+	return nt
+}
+
+type ThrowStmt struct {
+	Token tokens.Token
+	Expr  Expr
+	Data  Expr
+}
+
+func (s ThrowStmt) n() nodetype {
+	return nt
+}
+
+type TryStmt struct {
+	Body  Block
+	Name  *tokens.Token
+	Catch Block
+}
+
+func (s TryStmt) n() nodetype {
+	return nt
+}
+
+type YieldStmt struct {
+	Token tokens.Token
+	Expr  Expr
+}
+
+func (s YieldStmt) n() nodetype {
+	return nt
+}
+
+type AssertStmt struct {
+	Token   tokens.Token
+	Expr    Expr
+	Message Expr
+	Source  string // Source text of `Expr` for failure messages
+}
+
+func (s AssertStmt) n() nodetype {
+	return nt
+}
+
+type ProtocolStmt struct {
+	Name    tokens.Token
+	Methods []ProtocolMethod
+}
+
+func (s ProtocolStmt) n() nodetype {
+	return nt
+}
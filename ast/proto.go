@@ -1,7 +1,15 @@
 package ast
 
+import "calabash/lexer/tokens"
+
 type ProtoMethod struct {
-	K Expr // Method name
-	M Expr // Method
+	K Expr // Member name
+	M Expr // Method or plain value
 	I bool // Auto-inherits
+	G bool // Getter; runs when accessed without being called
+}
+
+type ProtocolMethod struct {
+	K     Expr         // Method name
+	Arity tokens.Token // Number of parameters the method must take
 }
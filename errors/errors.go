@@ -1,5 +1,7 @@
 package errors
 
+import "calabash/lexer/tokens"
+
 type ScanError struct {
 	Msg string
 }
@@ -16,8 +18,11 @@ func (e ParseError) Error() string {
 	return e.Msg
 }
 
+// RuntimeError is an error raised while running a script. Token is the
+// innermost token evaluated when the error was raised, if there is one.
 type RuntimeError struct {
-	Msg string
+	Msg   string
+	Token *tokens.Token
 }
 
 func (e RuntimeError) Error() string {
@@ -38,14 +43,28 @@ func (e ReturnError) Error() string {
 	return ""
 }
 
-type BreakError struct{}
+type BreakError struct {
+	Label string
+	Value interface{}
+}
 
 func (e BreakError) Error() string {
 	return ""
 }
 
-type ContinueError struct{}
+type ContinueError struct {
+	Label string
+}
 
 func (e ContinueError) Error() string {
 	return ""
 }
+
+type ThrowError struct {
+	Msg   string
+	Value interface{}
+}
+
+func (e ThrowError) Error() string {
+	return e.Msg
+}
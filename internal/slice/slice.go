@@ -33,3 +33,13 @@ func Last[T any](as []T) (T, bool) {
 
 	return as[len(as)-1], true
 }
+
+func Contains[T comparable](as []T, a T) bool {
+	for _, x := range as {
+		if x == a {
+			return true
+		}
+	}
+
+	return false
+}
@@ -38,6 +38,16 @@ func (s *Stack[T]) HasWith(f func(v T) bool) bool {
 	return false
 }
 
+// Walk visits the stack's values from the top down, stopping early if `f`
+// returns false
+func (s *Stack[T]) Walk(f func(v T) bool) {
+	for i := s.c - 1; i >= 0; i-- {
+		if !f(s.arr[i]) {
+			return
+		}
+	}
+}
+
 func New[T any](vs ...T) *Stack[T] {
 	return &Stack[T]{
 		arr: vs,
@@ -9,7 +9,9 @@ const (
 	RIGHT_BRACKET
 	LEFT_BRACE
 	RIGHT_BRACE
+	HASH_BRACE
 	COMMA
+	COLON
 	SEMICOLON
 	LESS
 	LESS_EQUAL
@@ -39,6 +41,8 @@ const (
 	NUMBER
 	IDENTIFIER
 	STRING
+	BYTES
+	REGEX
 	IF
 	ELSE
 	FOR
@@ -51,9 +55,22 @@ const (
 	MUT
 	ME
 	PROTO
+	SUPER
+	EXTENDS
+	PROTOCOL
+	IMPLEMENTS
 	WHILE
 	CONTINUE
 	BREAK
+	LOOP
+	DEFER
+	TRY
+	CATCH
+	THROW
+	ASSERT
+	YIELD
+	DOT_DOT
+	DOT_DOT_EQUAL
 	DOT_DOT_DOT
 	EOF
 )
@@ -26,6 +26,28 @@ func (v *Boolean) Inherit(p *Proto) Value {
 	return b
 }
 
+func (v *Boolean) String() string {
+	return Display(v)
+}
+
+func (v *Boolean) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Boolean) Equal(o Value) bool {
+	b, ok := o.(*Boolean)
+
+	return ok && b.Value == v.Value
+}
+
+func (v *Boolean) HashCode() uint64 {
+	if v.Value {
+		return mix(seedBoolean, 1)
+	}
+
+	return mix(seedBoolean, 0)
+}
+
 func NewBoolean(v bool) *Boolean {
 	return &Boolean{
 		Value: v,
@@ -34,7 +56,7 @@ func NewBoolean(v bool) *Boolean {
 }
 
 var ProtoBoolean = &Proto{
-	Methods: map[string]Caller{},
+	Members: map[string]Value{},
 }
 
 // Compile time checks
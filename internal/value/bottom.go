@@ -1,5 +1,7 @@
 package value
 
+import "fmt"
+
 type Bottom struct{}
 
 func (v *Bottom) v() vtype {
@@ -11,12 +13,34 @@ func (v *Bottom) Hash() string {
 }
 
 func (v *Bottom) Proto() *Proto {
-	return nil
+	return ProtoBottom
 }
 
 func (v *Bottom) Inherit(_ *Proto) Value {
 	return v
 }
 
+func (v *Bottom) String() string {
+	return Display(v)
+}
+
+func (v *Bottom) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Bottom) Equal(o Value) bool {
+	_, ok := o.(*Bottom)
+
+	return ok
+}
+
+func (v *Bottom) HashCode() uint64 {
+	return seedBottom
+}
+
+var ProtoBottom = &Proto{
+	Members: map[string]Value{},
+}
+
 // Compile time checks
 var _ Value = (*Bottom)(nil)
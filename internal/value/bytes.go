@@ -0,0 +1,75 @@
+package value
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Bytes is an immutable sequence of raw bytes, such as the contents of a
+// file or a network payload. Its items are the bytes as integers.
+type Bytes struct {
+	Value []byte
+	proto *Proto
+}
+
+func (v *Bytes) v() vtype {
+	return value
+}
+
+func (v *Bytes) Hash() string {
+	return fmt.Sprintf("b:%x", v.Value)
+}
+
+func (v *Bytes) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Bytes) Inherit(p *Proto) Value {
+	return &Bytes{
+		Value: v.Value,
+		proto: p,
+	}
+}
+
+func (v *Bytes) String() string {
+	return Display(v)
+}
+
+func (v *Bytes) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Bytes) Equal(o Value) bool {
+	b, ok := o.(*Bytes)
+
+	return ok && bytes.Equal(b.Value, v.Value)
+}
+
+func (v *Bytes) HashCode() uint64 {
+	return hashString(seedBytes, string(v.Value))
+}
+
+func (v *Bytes) Len() int {
+	return len(v.Value)
+}
+
+func (v *Bytes) At(i int) Value {
+	return NewInteger(int64(v.Value[i]))
+}
+
+// NewBytes creates a bytes value that owns `bs`, which must not be changed
+// afterwards
+func NewBytes(bs []byte) *Bytes {
+	return &Bytes{
+		Value: bs,
+		proto: ProtoBytes,
+	}
+}
+
+var ProtoBytes = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Bytes)(nil)
+var _ Sequence = (*Bytes)(nil)
@@ -0,0 +1,167 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ProtoBytesNamespace holds the members of the `bytes` namespace
+var ProtoBytesNamespace = &Proto{
+	Members: map[string]Value{},
+}
+
+func init() {
+	Globals["bytes"] = NewRecord(nil).Inherit(ProtoBytesNamespace)
+
+	ProtoBytes.Define(NewString("len"), bytesMethod(nil, func(b *Bytes, _ Evaluator) (interface{}, error) {
+		return NewInteger(int64(b.Len())), nil
+	}))
+
+	ProtoBytes.Define(NewString("at"), bytesMethod(params("i"), func(b *Bytes, e Evaluator) (interface{}, error) {
+		i, ok := ToInt(arg(e, "i"))
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Expect an index to be an integer"}
+		}
+
+		// Negative indices count from the end, as they do for 'slice'
+		if i < 0 {
+			i += b.Len()
+		}
+
+		if i < 0 || i >= b.Len() {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("Index %s is out of range for %d bytes", arg(e, "i"), b.Len())}
+		}
+
+		return b.At(i), nil
+	}))
+
+	ProtoBytes.Define(NewString("slice"), bytesMethod(params("start", "...end"), func(b *Bytes, e Evaluator) (interface{}, error) {
+		start, err := index(arg(e, "start"), b.Len())
+
+		if err != nil {
+			return nil, err
+		}
+
+		end := b.Len()
+
+		if rest := arg(e, "end").(*Tuple); rest.Len() > 0 {
+			end, err = index(rest.At(0), b.Len())
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if end < start {
+			end = start
+		}
+
+		// Bytes are never changed, so slices can share them
+		return NewBytes(b.Value[start:end:end]), nil
+	}))
+
+	ProtoBytes.Define(NewString("concat"), bytesMethod(params("...bs"), func(b *Bytes, e Evaluator) (interface{}, error) {
+		c := append([]byte(nil), b.Value...)
+
+		for _, o := range arg(e, "bs").(*Tuple).Items() {
+			ob, ok := o.(*Bytes)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Can only concatenate bytes with other bytes"}
+			}
+
+			c = append(c, ob.Value...)
+		}
+
+		return NewBytes(c), nil
+	}))
+
+	ProtoBytes.Define(NewString("toHex"), bytesMethod(nil, func(b *Bytes, _ Evaluator) (interface{}, error) {
+		return NewString(hex.EncodeToString(b.Value)), nil
+	}))
+
+	ProtoBytes.Define(NewString("toBase64"), bytesMethod(nil, func(b *Bytes, _ Evaluator) (interface{}, error) {
+		return NewString(base64.StdEncoding.EncodeToString(b.Value)), nil
+	}))
+
+	ProtoBytes.Define(NewString("toTuple"), bytesMethod(nil, func(b *Bytes, _ Evaluator) (interface{}, error) {
+		vs := make([]Value, b.Len())
+
+		for i := range vs {
+			vs[i] = b.At(i)
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoBytes.Define(NewString("decodeUtf8"), bytesMethod(nil, func(b *Bytes, _ Evaluator) (interface{}, error) {
+		if !utf8.Valid(b.Value) {
+			return nil, errors.RuntimeError{Msg: "Bytes are not valid UTF-8"}
+		}
+
+		return NewString(string(b.Value)), nil
+	}))
+
+	ProtoString.Define(NewString("toBytes"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return NewBytes([]byte(s.Value)), nil
+	}))
+
+	ProtoBytesNamespace.Define(NewString("fromHex"), namespaceMethod(params("s"), func(e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		bs, err := hex.DecodeString(s)
+
+		if err != nil {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("%q is not hexadecimal: %s", s, err)}
+		}
+
+		return NewBytes(bs), nil
+	}))
+
+	ProtoBytesNamespace.Define(NewString("fromBase64"), namespaceMethod(params("s"), func(e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		bs, err := base64.StdEncoding.DecodeString(s)
+
+		if err != nil {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("%q is not base64: %s", s, err)}
+		}
+
+		return NewBytes(bs), nil
+	}))
+
+	ProtoBytesNamespace.Define(NewString("of"), namespaceMethod(params("...ns"), func(e Evaluator) (interface{}, error) {
+		ns := arg(e, "ns").(*Tuple).Items()
+		bs := make([]byte, len(ns))
+
+		for i, n := range ns {
+			b, ok := ToInt(n)
+
+			if !ok || b < 0 || b > 255 {
+				return nil, errors.RuntimeError{Msg: fmt.Sprintf("Expect a byte to be an integer from 0 to 255, got %s", n)}
+			}
+
+			bs[i] = byte(b)
+		}
+
+		return NewBytes(bs), nil
+	}))
+}
+
+// bytesMethod declares a built-in bytes method
+func bytesMethod(ps []ast.Identifier, f func(b *Bytes, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("bytes", ps, f)
+}
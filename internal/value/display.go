@@ -0,0 +1,453 @@
+package value
+
+import (
+	"calabash/errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Display renders `v` in the canonical format: the way the value would be
+// written in source code, where that is possible
+func Display(v Value) string {
+	s, _ := Render(v, nil)
+
+	return s
+}
+
+// Render renders `v` like `Display`. When `call` is given, values whose proto
+// overrides "stringify" are rendered by calling the override through it,
+// including values nested in tuples, records and errors.
+func Render(v Value, call func(Caller) (Value, error)) (string, error) {
+	p := &printer{call: call, open: map[Value]bool{}}
+
+	if err := p.print(v); err != nil {
+		return "", err
+	}
+
+	return p.b.String(), nil
+}
+
+type printer struct {
+	b    strings.Builder
+	call func(Caller) (Value, error)
+	open map[Value]bool // Values being printed, to cut cycles short
+}
+
+func (p *printer) print(v Value) error {
+	if p.open[v] {
+		p.b.WriteString("<cycle>")
+		return nil
+	}
+
+	if ok, err := p.custom(v); ok || err != nil {
+		return err
+	}
+
+	return p.plain(v)
+}
+
+// plain prints `v` in the canonical format without looking for a
+// "stringify" override on `v` itself
+func (p *printer) plain(v Value) error {
+	p.open[v] = true
+	defer delete(p.open, v)
+
+	switch v := v.(type) {
+	case *Integer:
+		p.b.WriteString(v.Text())
+
+	case *Number:
+		p.b.WriteString(formatFloat(v.Value))
+
+	case *String:
+		p.b.WriteString(quote(v.Value))
+
+	case *Bytes:
+		// Text literals cannot hold every byte, so bytes are shown in hex
+		p.b.WriteString(fmt.Sprintf("x'%x'", v.Value))
+
+	case *Boolean:
+		p.b.WriteString(strconv.FormatBool(v.Value))
+
+	case *Bottom:
+		p.b.WriteString("bottom")
+
+	case *Tuple:
+		p.b.WriteString("[")
+
+		for i, item := range v.Items() {
+			if i > 0 {
+				p.b.WriteString(", ")
+			}
+
+			if err := p.print(item); err != nil {
+				return err
+			}
+		}
+
+		p.b.WriteString("]")
+
+	case *Record:
+		if err := p.record(v); err != nil {
+			return err
+		}
+
+	case *Set:
+		p.b.WriteString("#{")
+
+		for i, x := range v.Items() {
+			if i > 0 {
+				p.b.WriteString(", ")
+			}
+
+			if err := p.print(x); err != nil {
+				return err
+			}
+		}
+
+		p.b.WriteString("}")
+
+	case *Range:
+		start, end, step := rangeBounds(v)
+		p.b.WriteString(start)
+
+		if v.Inclusive {
+			p.b.WriteString("..=")
+		} else {
+			p.b.WriteString("..")
+		}
+
+		p.b.WriteString(end)
+
+		if step != "1" && step != "1.0" {
+			p.b.WriteString(" step " + step)
+		}
+
+	// Instants and durations have no literals, so they are shown as the
+	// calls that make them
+	case *Instant:
+		p.b.WriteString("time->'instant'(" + quote(v.Value.Format(time.RFC3339Nano)) + ")")
+
+	case *Duration:
+		p.b.WriteString("time->'duration'(" + quote(v.Value.String()) + ")")
+
+	case *Regex:
+		p.b.WriteString(regexLiteral(v.Value.String()))
+
+	// Iterators cannot be written down, so they are only named
+	case *Iterator:
+		p.b.WriteString("iterator")
+
+	case *Done:
+		p.b.WriteString("done")
+
+	case *Error:
+		p.b.WriteString("error(" + quote(v.Message))
+
+		if _, ok := v.Data.(*Bottom); !ok {
+			p.b.WriteString(", ")
+
+			if err := p.print(v.Data); err != nil {
+				return err
+			}
+		}
+
+		p.b.WriteString(")")
+
+	case *Function, *ProtoMethod:
+		p.signature(v.(Caller))
+
+	case *Proto:
+		if err := p.proto(v); err != nil {
+			return err
+		}
+
+	case *Protocol:
+		p.protocol(v)
+
+	default:
+		p.b.WriteString(v.Hash())
+	}
+
+	// Values that inherit a proto of their own are shown the way they are
+	// built: `value < proto`
+	if b := Builtin(v); b != nil && v.Proto() != b && v.Proto() != nil {
+		p.b.WriteString(" < ")
+
+		return p.print(v.Proto())
+	}
+
+	return nil
+}
+
+// custom prints `v` with the "stringify" method its proto overrides, if any.
+// It reports whether it did.
+func (p *printer) custom(v Value) (bool, error) {
+	if p.call == nil || v.Proto() == nil || v.Proto() == Builtin(v) {
+		return false, nil
+	}
+
+	m, ok := v.Proto().Lookup(NewString("stringify").Hash())
+	pm, isMethod := m.(*ProtoMethod)
+
+	if !ok || !isMethod {
+		return false, nil
+	}
+
+	r, err := p.call(pm.Bind(v))
+
+	if err != nil {
+		return false, err
+	}
+
+	s, ok := r.(*String)
+
+	if !ok {
+		return false, errors.RuntimeError{Msg: "Expect 'stringify' to return a string"}
+	}
+
+	p.b.WriteString(s.Value)
+
+	return true, nil
+}
+
+func (p *printer) record(r *Record) error {
+	if r.Len() == 0 {
+		p.b.WriteString("{}")
+		return nil
+	}
+
+	p.b.WriteString("{ ")
+
+	for i, en := range r.Entries() {
+		if i > 0 {
+			p.b.WriteString(", ")
+		}
+
+		if err := p.entry(en.K, en.V); err != nil {
+			return err
+		}
+	}
+
+	p.b.WriteString(" }")
+
+	return nil
+}
+
+func (p *printer) entry(k Value, v Value) error {
+	if err := p.print(k); err != nil {
+		return err
+	}
+
+	p.b.WriteString(" -> ")
+
+	return p.print(v)
+}
+
+// signature prints the parameters a function still expects
+func (p *printer) signature(c Caller) {
+	ps := c.Params()
+	applied := len(c.Args())
+
+	// Extra arguments are gathered by the rest parameter, which is never
+	// used up
+	if applied > len(ps) {
+		applied = len(ps)
+	}
+
+	if c.Rest() && applied == len(ps) {
+		applied--
+	}
+
+	names := make([]string, 0, len(ps)-applied)
+
+	for _, id := range ps[applied:] {
+		if id.Rest {
+			names = append(names, "..."+id.Name.Lexeme)
+			continue
+		}
+
+		names = append(names, id.Name.Lexeme)
+	}
+
+	p.b.WriteString("fn (" + strings.Join(names, ", ") + ")")
+}
+
+func (p *printer) proto(pr *Proto) error {
+	p.b.WriteString("proto ")
+
+	if pr.Parent != nil {
+		p.b.WriteString("extends ")
+
+		if err := p.print(pr.Parent); err != nil {
+			return err
+		}
+
+		p.b.WriteString(" ")
+	}
+
+	if len(pr.Protocols) > 0 {
+		ns := make([]string, len(pr.Protocols))
+
+		for i, pc := range pr.Protocols {
+			ns[i] = pc.Name
+		}
+
+		p.b.WriteString("implements " + strings.Join(ns, ", ") + " ")
+	}
+
+	if len(pr.Keys) == 0 {
+		p.b.WriteString("{}")
+		return nil
+	}
+
+	p.b.WriteString("{ ")
+
+	for i, k := range pr.Keys {
+		if i > 0 {
+			p.b.WriteString(", ")
+		}
+
+		if kv, ok := pr.KeyValue(k); ok {
+			if err := p.print(kv); err != nil {
+				return err
+			}
+		} else {
+			p.b.WriteString(keyText(k))
+		}
+
+		p.b.WriteString(" -> ")
+
+		if pm, ok := pr.Members[k].(*ProtoMethod); ok {
+			if pm.Inheritable != nil {
+				p.b.WriteString("< ")
+			}
+
+			if pm.Getter {
+				p.b.WriteString("get ")
+			}
+		}
+
+		if err := p.print(pr.Members[k]); err != nil {
+			return err
+		}
+	}
+
+	p.b.WriteString(" }")
+
+	return nil
+}
+
+func (p *printer) protocol(pc *Protocol) {
+	p.b.WriteString("protocol " + pc.Name + " ")
+
+	if len(pc.Keys) == 0 {
+		p.b.WriteString("{}")
+		return
+	}
+
+	ms := make([]string, len(pc.Keys))
+
+	for i, k := range pc.Keys {
+		ms[i] = fmt.Sprintf("%s -> %d", keyText(k), pc.Arities[k])
+	}
+
+	p.b.WriteString("{ " + strings.Join(ms, ", ") + " }")
+}
+
+// keyText recovers the text of a string or number key from its hash. Other
+// keys are only known by their hash, which is shown as is.
+func keyText(h string) string {
+	if s, err := strconv.Unquote(strings.TrimPrefix(h, "s:")); err == nil && strings.HasPrefix(h, "s:") {
+		return quote(s)
+	}
+
+	return strings.TrimPrefix(h, "n:")
+}
+
+func rangeBounds(r *Range) (string, string, string) {
+	if r.ints != nil {
+		return r.ints.start.Text(), r.ints.end.Text(), r.ints.step.Text()
+	}
+
+	return formatFloat(r.Start), formatFloat(r.End), formatFloat(r.Step)
+}
+
+// formatFloat writes floats so that they cannot be mistaken for integers
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+
+	case math.IsInf(f, 1):
+		return "inf"
+
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// quote writes `s` as a single-quoted string, escaping quotes, backslashes
+// and non-printable characters
+func quote(s string) string {
+	q := strconv.Quote(s)
+	q = strings.ReplaceAll(q[1:len(q)-1], `\"`, `"`)
+
+	return "'" + strings.ReplaceAll(q, "'", `\'`) + "'"
+}
+
+// regexLiteral writes `pattern` as a regex literal. Escapes are kept as they
+// are, since the pattern gives them their meaning, and only bare quotes are
+// escaped.
+func regexLiteral(pattern string) string {
+	var b strings.Builder
+	b.WriteString("r'")
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			b.WriteByte('\\')
+
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+
+		case '\'':
+			b.WriteString(`\'`)
+
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+
+	b.WriteString("'")
+
+	return b.String()
+}
+
+// format implements `fmt.Formatter` for values: `%v` and `%s` print the
+// canonical rendering and `%q` quotes it
+func format(f fmt.State, verb rune, v Value) {
+	switch verb {
+	case 'v', 's':
+		fmt.Fprint(f, Display(v))
+
+	case 'q':
+		fmt.Fprint(f, strconv.Quote(Display(v)))
+
+	default:
+		fmt.Fprintf(f, "%%!%c(%s=%s)", verb, Kind(v), Display(v))
+	}
+}
@@ -0,0 +1,84 @@
+package value_test
+
+import (
+	"calabash/internal/value"
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestDisplay(t *testing.T) {
+	p := &value.Proto{Members: map[string]value.Value{}}
+	p.Define(value.NewString("x"), value.NewInteger(1))
+
+	// A proto holding a value that inherits it
+	cyclic := &value.Proto{Members: map[string]value.Value{}}
+	cyclic.Define(value.NewString("me"), value.NewTuple(nil).Inherit(cyclic))
+
+	table := []struct {
+		name string
+		v    value.Value
+		want string
+	}{
+		{name: "integers", v: value.NewInteger(-42), want: "-42"},
+		{name: "whole floats keep a decimal point", v: value.NewNumber(2), want: "2.0"},
+		{name: "fractional floats", v: value.NewNumber(0.25), want: "0.25"},
+		{name: "large floats use an exponent", v: value.NewNumber(1e21), want: "1e+21"},
+		{name: "non-finite floats", v: value.NewTuple([]value.Value{value.NewNumber(math.Inf(-1)), value.NewNumber(math.NaN())}), want: "[-inf, NaN]"},
+		{name: "strings are quoted and escaped", v: value.NewString("it's\n"), want: `'it\'s\n'`},
+		{name: "bottom", v: &value.Bottom{}, want: "bottom"},
+		{name: "nested tuples", v: value.NewTuple([]value.Value{value.NewBoolean(true), value.NewTuple([]value.Value{})}), want: "[true, []]"},
+		{name: "records keep their key order", v: value.NewRecord([]struct {
+			K value.Value
+			V value.Value
+		}{
+			{K: value.NewString("b"), V: value.NewInteger(1)},
+			{K: value.NewInteger(2), V: value.NewRecord(nil)},
+		}), want: "{ 'b' -> 1, 2 -> {} }"},
+		{name: "sets keep their insertion order", v: value.NewSet([]value.Value{value.NewString("b"), value.NewInteger(1), value.NewNumber(1)}), want: "#{'b', 1}"},
+		{name: "empty sets", v: value.NewSet(nil), want: "#{}"},
+		{name: "bytes in hexadecimal", v: value.NewBytes([]byte("a\x00\xff")), want: "x'6100ff'"},
+		{name: "instants as RFC 3339", v: value.NewInstant(time.Date(2024, 2, 29, 12, 30, 0, 5, time.UTC)), want: "time->'instant'('2024-02-29T12:30:00.000000005Z')"},
+		{name: "durations", v: value.NewDuration(90 * time.Minute), want: "time->'duration'('1h30m0s')"},
+		{name: "regexes with bare quotes escaped", v: value.NewRegex(regexp.MustCompile(`it's\.`)), want: `r'it\'s\.'`},
+		{name: "iterators by name", v: value.NewIterator(func() (value.Value, bool, error) { return nil, false, nil }, nil), want: "iterator"},
+		{name: "done", v: &value.Done{}, want: "done"},
+		{name: "ranges", v: value.NewRange(0, 1, 0.5, true), want: "0.0..=1.0 step 0.5"},
+		{name: "errors with data", v: value.NewError("boom", value.NewInteger(1), nil), want: "error('boom', 1)"},
+		{name: "values inheriting a proto", v: value.NewInteger(1).Inherit(p), want: "1 < proto { 'x' -> 1 }"},
+		{name: "protocols", v: value.ProtocolContainer, want: "protocol Container { 'contains' -> 1 }"},
+		{name: "cycles are cut short", v: cyclic, want: "proto { 'me' -> [] < <cycle> }"},
+	}
+
+	for _, e := range table {
+		if got := value.Display(e.v); got != e.want {
+			t.Errorf("%q: expected %s, got %s", e.name, e.want, got)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	v := value.NewTuple([]value.Value{value.NewString("a")})
+
+	table := []struct {
+		format string
+		want   string
+	}{
+		{format: "%v", want: "['a']"},
+		{format: "%s", want: "['a']"},
+		{format: "%q", want: `"['a']"`},
+		{format: "%d", want: "%!d(tuple=['a'])"},
+	}
+
+	for _, e := range table {
+		if got := fmt.Sprintf(e.format, v); got != e.want {
+			t.Errorf("%q: expected %s, got %s", e.format, e.want, got)
+		}
+	}
+
+	if v.String() != "['a']" {
+		t.Errorf("String should match Display, got %s", v.String())
+	}
+}
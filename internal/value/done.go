@@ -0,0 +1,48 @@
+package value
+
+import "fmt"
+
+// Done is the marker an iterator's "next" method returns once it has no more
+// values. It is the global `done`.
+type Done struct{}
+
+func (v *Done) v() vtype {
+	return value
+}
+
+func (v *Done) Hash() string {
+	return "done"
+}
+
+func (v *Done) Proto() *Proto {
+	return ProtoDone
+}
+
+func (v *Done) Inherit(_ *Proto) Value {
+	return v
+}
+
+func (v *Done) String() string {
+	return Display(v)
+}
+
+func (v *Done) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Done) Equal(o Value) bool {
+	_, ok := o.(*Done)
+
+	return ok
+}
+
+func (v *Done) HashCode() uint64 {
+	return seedDone
+}
+
+var ProtoDone = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Done)(nil)
@@ -0,0 +1,64 @@
+package value
+
+import (
+	"fmt"
+	"time"
+)
+
+// Duration is a span of time with nanosecond precision, up to about 292
+// years either way
+type Duration struct {
+	Value time.Duration
+	proto *Proto
+}
+
+func (v *Duration) v() vtype {
+	return value
+}
+
+func (v *Duration) Hash() string {
+	return "d:" + v.Value.String()
+}
+
+func (v *Duration) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Duration) Inherit(p *Proto) Value {
+	return &Duration{
+		Value: v.Value,
+		proto: p,
+	}
+}
+
+func (v *Duration) String() string {
+	return Display(v)
+}
+
+func (v *Duration) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Duration) Equal(o Value) bool {
+	d, ok := o.(*Duration)
+
+	return ok && d.Value == v.Value
+}
+
+func (v *Duration) HashCode() uint64 {
+	return mix(seedDuration, uint64(v.Value))
+}
+
+func NewDuration(d time.Duration) *Duration {
+	return &Duration{
+		Value: d,
+		proto: ProtoDuration,
+	}
+}
+
+var ProtoDuration = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Duration)(nil)
@@ -0,0 +1,138 @@
+package value_test
+
+import (
+	"calabash/internal/value"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestEqual(t *testing.T) {
+	nan := value.NewNumber(math.NaN())
+	fn := &value.Function{}
+	p := &value.Proto{Members: map[string]value.Value{}}
+	next := func() (value.Value, bool, error) { return nil, false, nil }
+	it := value.NewIterator(next, nil)
+	p.Define(value.NewString("x"), value.NewInteger(1))
+	q := &value.Proto{Members: map[string]value.Value{}}
+	q.Define(value.NewString("x"), value.NewNumber(1))
+
+	record := func(kvs ...value.Value) *value.Record {
+		es := []struct {
+			K value.Value
+			V value.Value
+		}{}
+
+		for i := 0; i < len(kvs); i += 2 {
+			es = append(es, struct {
+				K value.Value
+				V value.Value
+			}{K: kvs[i], V: kvs[i+1]})
+		}
+
+		return value.NewRecord(es)
+	}
+
+	tuple := func(vs ...value.Value) *value.Tuple {
+		return value.NewTuple(vs)
+	}
+
+	table := []struct {
+		name string
+		a, b value.Value
+		want bool
+	}{
+		{name: "integers and floats of the same value", a: value.NewInteger(1), b: value.NewNumber(1), want: true},
+		{name: "zero and negative zero", a: value.NewNumber(0), b: value.NewNumber(math.Copysign(0, -1)), want: true},
+		{name: "a NaN and itself", a: nan, b: nan, want: true},
+		{name: "two NaNs", a: nan, b: value.NewNumber(math.NaN()), want: false},
+		{name: "strings and numbers", a: value.NewString("1"), b: value.NewInteger(1), want: false},
+		{name: "bottoms", a: &value.Bottom{}, b: &value.Bottom{}, want: true},
+		{name: "nested tuples", a: tuple(value.NewInteger(1), tuple(value.NewString("a"))), b: tuple(value.NewNumber(1), tuple(value.NewString("a"))), want: true},
+		{name: "tuples that are not a prefix of each other", a: tuple(value.NewInteger(1)), b: tuple(value.NewInteger(1), value.NewInteger(2)), want: false},
+		{name: "records in any order", a: record(value.NewString("a"), value.NewInteger(1), value.NewString("b"), value.NewInteger(2)), b: record(value.NewString("b"), value.NewInteger(2), value.NewString("a"), value.NewInteger(1)), want: true},
+		{name: "records with different values", a: record(value.NewString("a"), value.NewInteger(1)), b: record(value.NewString("a"), value.NewInteger(2)), want: false},
+		{name: "sets in any order", a: value.NewSet([]value.Value{value.NewInteger(1), value.NewString("a")}), b: value.NewSet([]value.Value{value.NewString("a"), value.NewNumber(1), value.NewInteger(1)}), want: true},
+		{name: "sets and tuples with the same elements", a: value.NewSet([]value.Value{value.NewInteger(1)}), b: tuple(value.NewInteger(1)), want: false},
+		{name: "bytes with the same contents", a: value.NewBytes([]byte("ab")), b: value.NewBytes([]byte{'a', 'b'}), want: true},
+		{name: "bytes and strings", a: value.NewBytes([]byte("ab")), b: value.NewString("ab"), want: false},
+		{name: "instants in different time zones", a: value.NewInstant(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)), b: value.NewInstant(time.Date(2024, 1, 1, 13, 0, 0, 0, time.FixedZone("CET", 3600))), want: true},
+		{name: "different instants", a: value.NewInstant(time.Unix(0, 0)), b: value.NewInstant(time.Unix(0, 1)), want: false},
+		{name: "durations and integers", a: value.NewDuration(1), b: value.NewInteger(1), want: false},
+		{name: "regexes with the same pattern", a: value.NewRegex(regexp.MustCompile("a+")), b: value.NewRegex(regexp.MustCompile("a+")), want: true},
+		{name: "regexes with different patterns", a: value.NewRegex(regexp.MustCompile("a+")), b: value.NewRegex(regexp.MustCompile("aa*")), want: false},
+		{name: "an iterator and itself", a: it, b: it, want: true},
+		{name: "different iterators", a: it, b: value.NewIterator(next, nil), want: false},
+		{name: "done and done", a: &value.Done{}, b: &value.Done{}, want: true},
+		{name: "done and bottom", a: &value.Done{}, b: &value.Bottom{}, want: false},
+		{name: "values inheriting different protos", a: value.NewString("a").Inherit(p), b: value.NewString("a"), want: true},
+		{name: "a function and itself", a: fn, b: fn, want: true},
+		{name: "different functions", a: fn, b: &value.Function{}, want: false},
+		{name: "protos with equal members", a: p, b: q, want: true},
+		{name: "errors", a: value.NewError("a", value.NewInteger(1), nil), b: value.NewError("a", value.NewNumber(1), nil), want: true},
+		{name: "ranges", a: value.NewRange(0, 1, 1, false), b: value.NewRange(0, 1, 1, true), want: false},
+		{name: "integer ranges", a: value.NewIntegerRange(value.NewInteger(0), value.NewInteger(1), value.NewInteger(1), false), b: value.NewIntegerRange(value.NewInteger(0), value.NewInteger(1), value.NewInteger(1), false), want: true},
+		{name: "integer and float ranges", a: value.NewIntegerRange(value.NewInteger(0), value.NewInteger(1), value.NewInteger(1), false), b: value.NewRange(0, 1, 1, false), want: false},
+	}
+
+	for _, e := range table {
+		if got := e.a.Equal(e.b); got != e.want {
+			t.Errorf("%q: expected Equal to be %t, got %t", e.name, e.want, got)
+		}
+
+		if got := e.b.Equal(e.a); got != e.want {
+			t.Errorf("%q: expected Equal to be symmetric", e.name)
+		}
+
+		if e.want && e.a.HashCode() != e.b.HashCode() {
+			t.Errorf("%q: equal values should have the same hash code", e.name)
+		}
+	}
+}
+
+func TestRecordKeys(t *testing.T) {
+	es := []struct {
+		K value.Value
+		V value.Value
+	}{}
+
+	for i := 0; i < 1000; i++ {
+		es = append(es, struct {
+			K value.Value
+			V value.Value
+		}{K: value.NewTuple([]value.Value{value.NewInteger(int64(i)), value.NewString("k")}), V: value.NewInteger(int64(i))})
+	}
+
+	// Setting a key again keeps a single entry
+	es = append(es, struct {
+		K value.Value
+		V value.Value
+	}{K: value.NewTuple([]value.Value{value.NewNumber(7), value.NewString("k")}), V: value.NewString("seven")})
+
+	r := value.NewRecord(es)
+
+	if r.Len() != 1000 {
+		t.Fatalf("Expected 1000 entries, got %d", r.Len())
+	}
+
+	for i := 0; i < 1000; i++ {
+		v, ok := r.Get(value.NewTuple([]value.Value{value.NewNumber(float64(i)), value.NewString("k")}))
+
+		if !ok {
+			t.Fatalf("Key %d was not found", i)
+		}
+
+		if i != 7 && !v.Equal(value.NewInteger(int64(i))) {
+			t.Errorf("Key %d holds %v", i, v)
+		}
+	}
+
+	if v, _ := r.Get(value.NewTuple([]value.Value{value.NewInteger(7), value.NewString("k")})); !v.Equal(value.NewString("seven")) {
+		t.Errorf("Setting a key again should replace its value, got %v", v)
+	}
+
+	if _, ok := r.Get(value.NewString("k")); ok {
+		t.Error("Missing keys should not be found")
+	}
+}
@@ -0,0 +1,70 @@
+package value
+
+import (
+	"calabash/lexer/tokens"
+	"fmt"
+)
+
+type Error struct {
+	Message string
+	Data    Value
+	Token   *tokens.Token // Where the error was raised; nil if unknown
+	proto   *Proto
+}
+
+func (v *Error) v() vtype {
+	return value
+}
+
+func (v *Error) Hash() string {
+	return fmt.Sprintf("err:%q:%s", v.Message, v.Data.Hash())
+}
+
+func (v *Error) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Error) Inherit(p *Proto) Value {
+	e := NewError(v.Message, v.Data, v.Token)
+	e.proto = p
+
+	return e
+}
+
+func (v *Error) String() string {
+	return Display(v)
+}
+
+func (v *Error) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Error) Equal(o Value) bool {
+	e, ok := o.(*Error)
+
+	return ok && e.Message == v.Message && e.Data.Equal(v.Data)
+}
+
+func (v *Error) HashCode() uint64 {
+	return mix(hashString(seedError, v.Message), v.Data.HashCode())
+}
+
+func NewError(msg string, data Value, tk *tokens.Token) *Error {
+	if data == nil {
+		data = &Bottom{}
+	}
+
+	return &Error{
+		Message: msg,
+		Data:    data,
+		Token:   tk,
+		proto:   ProtoError,
+	}
+}
+
+var ProtoError = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Error)(nil)
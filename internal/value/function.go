@@ -6,6 +6,7 @@ import (
 	"calabash/internal/slice"
 	"calabash/internal/uuid"
 	"calabash/lexer/tokens"
+	"fmt"
 	"strconv"
 )
 
@@ -16,8 +17,9 @@ type Function struct {
 		Specified bool
 		Tk        *tokens.Token
 	}
-	Apps []Value
-	hash string
+	Apps      []Value
+	Generator bool // Calls return an iterator over the values the body yields
+	hash      string
 }
 
 func (v *Function) v() vtype {
@@ -36,18 +38,39 @@ func (v *Function) Hash() string {
 }
 
 func (v *Function) Proto() *Proto {
-	return nil
+	return ProtoFunction
 }
 
 func (v *Function) Inherit(_ *Proto) Value {
 	return v
 }
 
+func (v *Function) String() string {
+	return Display(v)
+}
+
+func (v *Function) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Functions are only equal to themselves
+func (v *Function) Equal(o Value) bool {
+	f, ok := o.(*Function)
+
+	return ok && f.Hash() == v.Hash()
+}
+
+func (v *Function) HashCode() uint64 {
+	return hashString(seedFunction, v.Hash())
+}
+
 func (v *Function) Apply(vs []Value) Caller {
 	return &Function{
 		ParamList: v.ParamList,
 		Body:      v.Body,
+		Depth:     v.Depth,
 		Apps:      append(v.Apps, vs...),
+		Generator: v.Generator,
 	}
 }
 
@@ -74,14 +97,25 @@ func (v *Function) Arity() int {
 }
 
 func (v *Function) Call(e Evaluator) (interface{}, error) {
+	// A generator's body does not run yet: it runs up to each yield as the
+	// iterator it returns is advanced
+	if v.Generator {
+		return e.Generate(v.Body), nil
+	}
+
+	// Deferred expressions are scoped to this call and run however the body
+	// exits
+	e.PushDefers()
+
 	rVal, err := e.Eval(v.Body.Contents)
+	err = e.RunDefers(err)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if rVal == nil {
-		return Bottom{}, nil
+		return &Bottom{}, nil
 	}
 
 	return rVal, nil
@@ -105,6 +139,11 @@ func (v *Function) Closure(env *environment.Environment[Value]) *environment.Env
 	return environment.Slice(env, d)
 }
 
+// ProtoFunction is shared by all callable values, including proto methods
+var ProtoFunction = &Proto{
+	Members: map[string]Value{},
+}
+
 // Compile time checks
 var _ Value = (*Function)(nil)
 var _ Caller = (*Function)(nil)
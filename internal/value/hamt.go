@@ -0,0 +1,234 @@
+package value
+
+import "math/bits"
+
+// hamt is a persistent hash array mapped trie from values to positions. Each
+// level of the trie picks one of 32 children with the next 5 bits of a key's
+// hash code; keys whose codes are entirely equal share a leaf and are told
+// apart with `Equal`. Updates copy the path to the leaf they change and share
+// everything else.
+type hamt struct {
+	root *hnode
+}
+
+type hnode struct {
+	bitmap uint32 // Which of the 32 children are present
+	slots  []hslot
+}
+
+// hslot is a child of a node: either a deeper node or a leaf
+type hslot struct {
+	node *hnode
+	leaf *hleaf
+}
+
+type hleaf struct {
+	code uint64
+	keys []Value
+	vals []int
+}
+
+const (
+	hbits = 5
+	hmask = 1<<hbits - 1
+)
+
+func (h hamt) get(k Value, code uint64) (int, bool) {
+	n := h.root
+
+	for shift := uint(0); n != nil; shift += hbits {
+		bit := uint32(1) << ((code >> shift) & hmask)
+
+		if n.bitmap&bit == 0 {
+			return 0, false
+		}
+
+		s := n.slots[n.index(bit)]
+
+		if s.leaf != nil {
+			return s.leaf.get(k, code)
+		}
+
+		n = s.node
+	}
+
+	return 0, false
+}
+
+// set returns a trie with `k` mapped to `val`. When `owned` is true, the
+// nodes are changed in place instead, which is only safe when no other trie
+// shares them.
+func (h hamt) set(k Value, code uint64, val int, owned bool) hamt {
+	if h.root == nil {
+		h.root = &hnode{}
+	}
+
+	return hamt{root: h.root.set(0, k, code, val, owned)}
+}
+
+// delete returns a trie without `k`, or the same trie if it has no `k`
+func (h hamt) delete(k Value, code uint64) hamt {
+	if h.root == nil {
+		return h
+	}
+
+	return hamt{root: h.root.delete(0, k, code)}
+}
+
+func (n *hnode) index(bit uint32) int {
+	return bits.OnesCount32(n.bitmap & (bit - 1))
+}
+
+func (n *hnode) set(shift uint, k Value, code uint64, val int, owned bool) *hnode {
+	bit := uint32(1) << ((code >> shift) & hmask)
+	i := n.index(bit)
+	c := n
+
+	if !owned {
+		c = &hnode{bitmap: n.bitmap, slots: append([]hslot(nil), n.slots...)}
+	}
+
+	if n.bitmap&bit == 0 {
+		c.bitmap |= bit
+		c.slots = append(c.slots[:i], append([]hslot{{leaf: &hleaf{code: code, keys: []Value{k}, vals: []int{val}}}}, c.slots[i:]...)...)
+
+		return c
+	}
+
+	s := n.slots[i]
+
+	switch {
+	case s.node != nil:
+		c.slots[i] = hslot{node: s.node.set(shift+hbits, k, code, val, owned)}
+
+	case s.leaf.code == code:
+		c.slots[i] = hslot{leaf: s.leaf.set(k, val, owned)}
+
+	default:
+		// Two different codes share this slot: they are split one level
+		// down, where their next bits may differ
+		c.slots[i] = hslot{node: leafNode(shift+hbits, s.leaf).set(shift+hbits, k, code, val, true)}
+	}
+
+	return c
+}
+
+// delete returns a node without `k`, or nil when nothing is left in it. Nodes
+// that do not hold `k` are returned as they are.
+func (n *hnode) delete(shift uint, k Value, code uint64) *hnode {
+	bit := uint32(1) << ((code >> shift) & hmask)
+
+	if n.bitmap&bit == 0 {
+		return n
+	}
+
+	i := n.index(bit)
+	s := n.slots[i]
+	var rest hslot
+
+	if s.node != nil {
+		sub := s.node.delete(shift+hbits, k, code)
+
+		if sub == s.node {
+			return n
+		}
+
+		if sub != nil {
+			rest = hslot{node: sub}
+		}
+	} else {
+		l := s.leaf.delete(k, code)
+
+		if l == s.leaf {
+			return n
+		}
+
+		if l != nil {
+			rest = hslot{leaf: l}
+		}
+	}
+
+	c := &hnode{bitmap: n.bitmap, slots: append([]hslot(nil), n.slots...)}
+
+	if rest.node != nil || rest.leaf != nil {
+		c.slots[i] = rest
+		return c
+	}
+
+	// The slot is empty now, so it is dropped along with its bit
+	c.bitmap &^= bit
+
+	if c.bitmap == 0 {
+		return nil
+	}
+
+	c.slots = append(c.slots[:i], c.slots[i+1:]...)
+
+	return c
+}
+
+// leafNode makes a node holding only the leaf `l`
+func leafNode(shift uint, l *hleaf) *hnode {
+	bit := uint32(1) << ((l.code >> shift) & hmask)
+
+	return &hnode{bitmap: bit, slots: []hslot{{leaf: l}}}
+}
+
+func (l *hleaf) get(k Value, code uint64) (int, bool) {
+	if l.code != code {
+		return 0, false
+	}
+
+	for i, x := range l.keys {
+		if x.Equal(k) {
+			return l.vals[i], true
+		}
+	}
+
+	return 0, false
+}
+
+func (l *hleaf) set(k Value, val int, owned bool) *hleaf {
+	c := l
+
+	if !owned {
+		c = &hleaf{code: l.code, keys: append([]Value(nil), l.keys...), vals: append([]int(nil), l.vals...)}
+	}
+
+	for i, x := range l.keys {
+		if x.Equal(k) {
+			c.vals[i] = val
+			return c
+		}
+	}
+
+	c.keys = append(c.keys, k)
+	c.vals = append(c.vals, val)
+
+	return c
+}
+
+// delete returns a leaf without `k`, or nil when `k` was its only key
+func (l *hleaf) delete(k Value, code uint64) *hleaf {
+	if l.code != code {
+		return l
+	}
+
+	for i, x := range l.keys {
+		if !x.Equal(k) {
+			continue
+		}
+
+		if len(l.keys) == 1 {
+			return nil
+		}
+
+		return &hleaf{
+			code: l.code,
+			keys: append(append([]Value(nil), l.keys[:i]...), l.keys[i+1:]...),
+			vals: append(append([]int(nil), l.vals[:i]...), l.vals[i+1:]...),
+		}
+	}
+
+	return l
+}
@@ -0,0 +1,101 @@
+package value
+
+import "math/bits"
+
+// Hash codes are built with FNV-1a. Each kind starts from a seed of its own
+// so that, say, a string and a tuple holding it do not tend to collide.
+const (
+	fnvOffset uint64 = 14695981039346656037
+	fnvPrime  uint64 = 1099511628211
+)
+
+var (
+	seedNumber   = hashString(fnvOffset, "number")
+	seedString   = hashString(fnvOffset, "string")
+	seedBytes    = hashString(fnvOffset, "bytes")
+	seedBoolean  = hashString(fnvOffset, "boolean")
+	seedBottom   = hashString(fnvOffset, "bottom")
+	seedTuple    = hashString(fnvOffset, "tuple")
+	seedRecord   = hashString(fnvOffset, "record")
+	seedSet      = hashString(fnvOffset, "set")
+	seedRange    = hashString(fnvOffset, "range")
+	seedInstant  = hashString(fnvOffset, "instant")
+	seedDuration = hashString(fnvOffset, "duration")
+	seedRegex    = hashString(fnvOffset, "regex")
+	seedIterator = hashString(fnvOffset, "iterator")
+	seedDone     = hashString(fnvOffset, "done")
+	seedError    = hashString(fnvOffset, "error")
+	seedFunction = hashString(fnvOffset, "function")
+	seedProto    = hashString(fnvOffset, "proto")
+	seedProtocol = hashString(fnvOffset, "protocol")
+)
+
+func hashString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= fnvPrime
+	}
+
+	return h
+}
+
+// mix folds `x` into the hash `h`; the result depends on the order values
+// are mixed in
+func mix(h uint64, x uint64) uint64 {
+	for i := 0; i < 8; i++ {
+		h ^= x & 0xff
+		h *= fnvPrime
+		x >>= 8
+	}
+
+	return h
+}
+
+// scramble spreads the bits of `x` so that hash codes can be summed into an
+// order-independent hash without nearby codes cancelling out
+func scramble(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+
+	return bits.RotateLeft64(x, 17)
+}
+
+// valueSet holds values by hash code, comparing the values whose codes
+// collide with `Equal`
+type valueSet map[uint64][]Value
+
+// add puts `v` in the set, reporting whether it was not there already
+func (s valueSet) add(v Value) bool {
+	c := v.HashCode()
+
+	for _, x := range s[c] {
+		if x.Equal(v) {
+			return false
+		}
+	}
+
+	s[c] = append(s[c], v)
+
+	return true
+}
+
+func (s valueSet) has(v Value) bool {
+	for _, x := range s[v.HashCode()] {
+		if x.Equal(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newValueSet(vs []Value) valueSet {
+	s := make(valueSet, len(vs))
+
+	for _, v := range vs {
+		s.add(v)
+	}
+
+	return s
+}
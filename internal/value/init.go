@@ -5,86 +5,317 @@ import (
 	"calabash/errors"
 	"calabash/internal/tokentype"
 	"calabash/lexer/tokens"
-	"fmt"
-	"strconv"
 	"strings"
 )
 
 func init() {
-	ProtoTuple.Methods["s:\"push\""] = &ProtoMethod{
+	ProtoTuple.Protocols = []*Protocol{ProtocolAppendable, ProtocolSized, ProtocolContainer, ProtocolIterable}
+	ProtoNumber.Protocols = []*Protocol{ProtocolStringify}
+	ProtoInteger.Protocols = []*Protocol{ProtocolStringify}
+	ProtoBoolean.Protocols = []*Protocol{ProtocolStringify}
+	ProtoRecord.Protocols = []*Protocol{ProtocolKeyed, ProtocolSized, ProtocolIterable}
+	ProtoSet.Protocols = []*Protocol{ProtocolSized, ProtocolIterable}
+	ProtoRange.Protocols = []*Protocol{ProtocolSized, ProtocolContainer, ProtocolIterable}
+	ProtoString.Protocols = []*Protocol{ProtocolSized, ProtocolContainer, ProtocolIterable}
+	ProtoBytes.Protocols = []*Protocol{ProtocolSized, ProtocolIterable}
+
+	ProtoRange.Define(NewString("len"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			r, ok := me.(*Range)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a range"}
+			}
+
+			return NewInteger(int64(r.Len())), nil
+		},
+	})
+
+	ProtoRange.Define(NewString("contains"), &ProtoMethod{
 		ParamList: []ast.Identifier{
-			{Name: tokens.New(tokentype.IDENTIFIER, "e", 0, 0), Mut: false},
+			{Name: tokens.New(tokentype.IDENTIFIER, "n", 0, 0), Mut: false},
 		},
 		call: func(me Value, e Evaluator) (interface{}, error) {
-			tpl, ok := me.(*Tuple)
+			r, ok := me.(*Range)
 
 			if !ok {
-				return nil, errors.RuntimeError{Msg: "Cannot call `push` on a value other than a tuple"}
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a range"}
 			}
 
-			vs := append(tpl.Items, e.Dump().Env.Get("e"))
+			return NewBoolean(r.Contains(e.Dump().Env.Get("n"))), nil
+		},
+	})
+
+	ProtoRange.Define(NewString("toTuple"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			r, ok := me.(*Range)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a range"}
+			}
+
+			if r.Len() > MaxRangeTuple {
+				return nil, errors.RuntimeError{Msg: "Range is too long to make a tuple"}
+			}
+
+			vs := make([]Value, r.Len())
+
+			for i := range vs {
+				vs[i] = r.At(i)
+			}
 
 			return NewTuple(vs), nil
 		},
-	}
+	})
 
-	ProtoNumber.Methods["s:\"stringify\""] = &ProtoMethod{
+	ProtoError.Define(NewString("message"), &ProtoMethod{
 		call: func(me Value, _ Evaluator) (interface{}, error) {
-			n, ok := me.(*Number)
+			err, ok := me.(*Error)
 
 			if !ok {
-				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a number"}
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be an error"}
 			}
 
-			s := strconv.FormatFloat(float64(n.Value), 'f', -1, 64)
+			return NewString(err.Message), nil
+		},
+	})
 
-			return NewString(s), nil
+	ProtoError.Define(NewString("data"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			err, ok := me.(*Error)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be an error"}
+			}
+
+			return err.Data, nil
 		},
-	}
+	})
 
-	ProtoString.Methods["s:\"upper\""] = &ProtoMethod{
+	ProtoError.Define(NewString("position"), &ProtoMethod{
 		call: func(me Value, _ Evaluator) (interface{}, error) {
-			s, ok := me.(*String)
+			err, ok := me.(*Error)
 
 			if !ok {
-				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a string"}
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be an error"}
 			}
 
-			return NewString(strings.ToUpper(s.Value)), nil
+			if err.Token == nil {
+				return &Bottom{}, nil
+			}
+
+			return NewRecord([]struct {
+				K Value
+				V Value
+			}{
+				{K: NewString("row"), V: NewInteger(int64(err.Token.Position.Row))},
+				{K: NewString("col"), V: NewInteger(int64(err.Token.Position.Col))},
+			}), nil
 		},
-	}
+	})
 
-	ProtoBoolean.Methods["s:\"stringify\""] = &ProtoMethod{
+	ProtoProtocol.Define(NewString("name"), &ProtoMethod{
 		call: func(me Value, _ Evaluator) (interface{}, error) {
-			n, ok := me.(*Boolean)
+			pc, ok := me.(*Protocol)
 
 			if !ok {
-				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a boolean"}
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a protocol"}
 			}
 
-			return NewString(fmt.Sprint(n.Value)), nil
+			return NewString(pc.Name), nil
 		},
-	}
+	})
 
-	ProtoRecord.Methods["s:\"get\""] = &ProtoMethod{
+	ProtoProtocol.Define(NewString("check"), &ProtoMethod{
 		ParamList: []ast.Identifier{
-			{Name: tokens.New(tokentype.IDENTIFIER, "k", 0, 0), Mut: false},
+			{Name: tokens.New(tokentype.IDENTIFIER, "v", 0, 0), Mut: false},
 		},
 		call: func(me Value, e Evaluator) (interface{}, error) {
-			r, ok := me.(*Record)
+			pc, ok := me.(*Protocol)
 
 			if !ok {
-				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a record"}
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a protocol"}
+			}
+
+			_, missing := pc.MissingFor(e.Dump().Env.Get("v"))
+
+			return NewBoolean(!missing), nil
+		},
+	})
+
+	// A proto that overrides "stringify" is used for nested values, but not
+	// for `me`, so that the override can call this method through `super`
+	ProtoValue.Define(NewString("stringify"), method("a value", nil, func(me Value, e Evaluator) (interface{}, error) {
+		if s, ok := me.(*String); ok {
+			return NewString(s.Value), nil
+		}
+
+		p := &printer{call: evaluatorCall(e), open: map[Value]bool{}}
+
+		if err := p.plain(me); err != nil {
+			return nil, err
+		}
+
+		return NewString(p.b.String()), nil
+	}))
+
+	ProtoValue.Define(NewString("kind"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			return NewString(Kind(me)), nil
+		},
+	})
+
+	ProtoValue.Define(NewString("protoKeys"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			seen := map[string]bool{}
+			ks := []Value{}
+
+			for _, p := range []*Proto{me.Proto(), Builtin(me)} {
+				for ; p != nil; p = p.Parent {
+					for _, k := range p.Keys {
+						kv, ok := p.KeyValue(k)
+
+						if seen[k] || !ok {
+							continue
+						}
+
+						seen[k] = true
+						ks = append(ks, kv)
+					}
+				}
 			}
 
-			k := e.Dump().Env.Get("k")
-			v, ok := r.Entries[k.Hash()]
+			return NewTuple(ks), nil
+		},
+	})
+
+	ProtoFunction.Define(NewString("arity"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			c, ok := me.(Caller)
 
 			if !ok {
-				return nil, errors.RuntimeError{Msg: fmt.Sprintf("Record does not have key %q", k)}
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a function"}
 			}
 
-			return v, nil
+			return NewInteger(int64(c.Arity())), nil
 		},
+	})
+
+	ProtoFunction.Define(NewString("params"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			c, ok := me.(Caller)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a function"}
+			}
+
+			ps := make([]Value, len(c.Params()))
+
+			for i, p := range c.Params() {
+				ps[i] = NewString(p.Name.Lexeme)
+			}
+
+			return NewTuple(ps), nil
+		},
+	})
+
+	ProtoFunction.Define(NewString("rest"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			c, ok := me.(Caller)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a function"}
+			}
+
+			return NewBoolean(c.Rest()), nil
+		},
+	})
+
+	ProtoFunction.Define(NewString("args"), &ProtoMethod{
+		call: func(me Value, _ Evaluator) (interface{}, error) {
+			c, ok := me.(Caller)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be a function"}
+			}
+
+			return NewTuple(append([]Value{}, c.Args()...)), nil
+		},
+	})
+}
+
+// method declares a built-in method of the kind of value `T`. Its scope is
+// chained to the caller's so that callbacks it invokes see the scope they
+// were written in.
+func method[T Value](kind string, ps []ast.Identifier, f func(me T, e Evaluator) (interface{}, error)) *ProtoMethod {
+	pm := &ProtoMethod{
+		ParamList: ps,
+		call: func(me Value, e Evaluator) (interface{}, error) {
+			v, ok := me.(T)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect 'me' to be " + kind}
+			}
+
+			return f(v, e)
+		},
+	}
+	pm.Depth.Specified = true
+
+	return pm
+}
+
+// params declares the parameters of a built-in method; a name prefixed with
+// "..." is a rest parameter
+func params(names ...string) []ast.Identifier {
+	ps := make([]ast.Identifier, len(names))
+
+	for i, n := range names {
+		rest := strings.HasPrefix(n, "...")
+		ps[i] = ast.Identifier{
+			Name: tokens.New(tokentype.IDENTIFIER, strings.TrimPrefix(n, "..."), 0, 0),
+			Rest: rest,
+		}
+	}
+
+	return ps
+}
+
+// arg reads the argument bound to the parameter `name` of a built-in method
+func arg(e Evaluator, name string) Value {
+	return e.Dump().Env.Get(name)
+}
+
+// test calls the callback `f` and requires it to answer with a boolean
+func test(e Evaluator, f Value, args ...Value) (bool, error) {
+	r, err := e.Call(f, args)
+
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := r.(*Boolean)
+
+	if !ok {
+		return false, errors.RuntimeError{Msg: "Expect the callback to return a boolean"}
+	}
+
+	return b.Value, nil
+}
+
+// stringify renders `v` as a string, leaving strings as they are and
+// rendering other values with the "stringify" method of their proto
+func stringify(e Evaluator, v Value) (string, error) {
+	if s, ok := v.(*String); ok {
+		return s.Value, nil
+	}
+
+	return Render(v, evaluatorCall(e))
+}
+
+// evaluatorCall calls "stringify" overrides on behalf of a built-in method
+func evaluatorCall(e Evaluator) func(Caller) (Value, error) {
+	return func(c Caller) (Value, error) {
+		return e.Call(c.(Value), nil)
 	}
 }
@@ -0,0 +1,69 @@
+package value
+
+import (
+	"fmt"
+	"time"
+
+	// Time zones are looked up in a copy of the tz database built into the
+	// binary, so they do not depend on the host
+	_ "time/tzdata"
+)
+
+// Instant is a point in time, along with the time zone it is shown in
+type Instant struct {
+	Value time.Time
+	proto *Proto
+}
+
+func (v *Instant) v() vtype {
+	return value
+}
+
+func (v *Instant) Hash() string {
+	return "t:" + v.Value.UTC().Format(time.RFC3339Nano)
+}
+
+func (v *Instant) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Instant) Inherit(p *Proto) Value {
+	return &Instant{
+		Value: v.Value,
+		proto: p,
+	}
+}
+
+func (v *Instant) String() string {
+	return Display(v)
+}
+
+func (v *Instant) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Instants are equal when they are the same point in time, whatever time
+// zone they are shown in
+func (v *Instant) Equal(o Value) bool {
+	t, ok := o.(*Instant)
+
+	return ok && t.Value.Equal(v.Value)
+}
+
+func (v *Instant) HashCode() uint64 {
+	return mix(mix(seedInstant, uint64(v.Value.Unix())), uint64(v.Value.Nanosecond()))
+}
+
+func NewInstant(t time.Time) *Instant {
+	return &Instant{
+		Value: t,
+		proto: ProtoInstant,
+	}
+}
+
+var ProtoInstant = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Instant)(nil)
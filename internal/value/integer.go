@@ -0,0 +1,227 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Integer is an exact whole number. It is stored as an int64 and switches to
+// an arbitrary-precision representation when a value does not fit.
+type Integer struct {
+	small int64
+	large *big.Int // Only set when the value does not fit in an int64
+	proto *Proto
+}
+
+func (v *Integer) v() vtype {
+	return value
+}
+
+// Integers hash like the floats they are equal to so that `1` and `1.0` are
+// interchangeable as keys
+func (v *Integer) Hash() string {
+	return "n:" + v.Text()
+}
+
+func (v *Integer) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Integer) Inherit(p *Proto) Value {
+	return &Integer{small: v.small, large: v.large, proto: p}
+}
+
+func (v *Integer) String() string {
+	return Display(v)
+}
+
+func (v *Integer) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Integers are equal to the numbers with the same value
+func (v *Integer) Equal(o Value) bool {
+	c, ok := CompareNumbers(v, o)
+
+	return ok && c == 0
+}
+
+func (v *Integer) HashCode() uint64 {
+	if v.large == nil {
+		return mix(seedNumber, uint64(v.small))
+	}
+
+	return mix(hashString(seedNumber, string(v.large.Bytes())), uint64(v.large.Sign()))
+}
+
+// Int64 returns the integer as an int64 when it fits in one
+func (v *Integer) Int64() (int64, bool) {
+	return v.small, v.large == nil
+}
+
+// Big returns a copy of the integer as an arbitrary-precision integer
+func (v *Integer) Big() *big.Int {
+	if v.large != nil {
+		return new(big.Int).Set(v.large)
+	}
+
+	return big.NewInt(v.small)
+}
+
+// Float returns the nearest float to the integer
+func (v *Integer) Float() float64 {
+	if v.large != nil {
+		f, _ := new(big.Float).SetInt(v.large).Float64()
+
+		return f
+	}
+
+	return float64(v.small)
+}
+
+// Text returns the integer in base 10
+func (v *Integer) Text() string {
+	return v.Big().String()
+}
+
+func (v *Integer) Sign() int {
+	if v.large != nil {
+		return v.large.Sign()
+	}
+
+	switch {
+	case v.small > 0:
+		return 1
+
+	case v.small < 0:
+		return -1
+	}
+
+	return 0
+}
+
+func NewInteger(i int64) *Integer {
+	return &Integer{
+		small: i,
+		proto: ProtoInteger,
+	}
+}
+
+// NewBigInteger creates an integer from `b`, which must not be changed
+// afterwards
+func NewBigInteger(b *big.Int) *Integer {
+	if b.IsInt64() {
+		return NewInteger(b.Int64())
+	}
+
+	return &Integer{
+		large: b,
+		proto: ProtoInteger,
+	}
+}
+
+// IntegerFromFloat converts `f` to an integer when it is a finite whole
+// number
+func IntegerFromFloat(f float64) (*Integer, bool) {
+	if !isInteger(f) {
+		return nil, false
+	}
+
+	if f >= -(1<<63) && f < 1<<63 {
+		return NewInteger(int64(f)), true
+	}
+
+	b, _ := big.NewFloat(f).Int(nil)
+
+	return NewBigInteger(b), true
+}
+
+// Add, Sub and Mul compute exact results, switching to arbitrary precision
+// when an int64 would overflow
+
+func (v *Integer) Add(o *Integer) *Integer {
+	a, oka := v.Int64()
+	b, okb := o.Int64()
+
+	if s := a + b; oka && okb && (s > a) == (b > 0) {
+		return NewInteger(s)
+	}
+
+	return NewBigInteger(new(big.Int).Add(v.Big(), o.Big()))
+}
+
+func (v *Integer) Sub(o *Integer) *Integer {
+	a, oka := v.Int64()
+	b, okb := o.Int64()
+
+	if d := a - b; oka && okb && (d < a) == (b > 0) {
+		return NewInteger(d)
+	}
+
+	return NewBigInteger(new(big.Int).Sub(v.Big(), o.Big()))
+}
+
+func (v *Integer) Mul(o *Integer) *Integer {
+	a, oka := v.Int64()
+	b, okb := o.Int64()
+
+	// The product overflowed unless dividing it gives back the operand; the
+	// division itself overflows for -1 * MinInt64, so that case is excluded
+	if p := a * b; oka && okb && (a == 0 || (p/a == b && !(a == -1 && b == math.MinInt64))) {
+		return NewInteger(p)
+	}
+
+	return NewBigInteger(new(big.Int).Mul(v.Big(), o.Big()))
+}
+
+func (v *Integer) Neg() *Integer {
+	if a, ok := v.Int64(); ok && a != math.MinInt64 {
+		return NewInteger(-a)
+	}
+
+	return NewBigInteger(new(big.Int).Neg(v.Big()))
+}
+
+// Pow raises the integer to the non-negative power `o`. It fails rather than
+// build an integer of more than `maxPowBits` bits.
+func (v *Integer) Pow(o *Integer) (*Integer, bool) {
+	b := v.Big()
+	e := o.Big()
+
+	// 0, 1 and -1 stay small whatever the exponent
+	if b.CmpAbs(big.NewInt(1)) > 0 && (!e.IsInt64() || e.Int64()*int64(b.BitLen()) > maxPowBits) {
+		return nil, false
+	}
+
+	return NewBigInteger(b.Exp(b, e, nil)), true
+}
+
+const maxPowBits = 1 << 24
+
+// Cmp compares the integer with `o`, returning -1, 0 or 1
+func (v *Integer) Cmp(o *Integer) int {
+	a, oka := v.Int64()
+	b, okb := o.Int64()
+
+	if oka && okb {
+		switch {
+		case a < b:
+			return -1
+
+		case a > b:
+			return 1
+		}
+
+		return 0
+	}
+
+	return v.Big().Cmp(o.Big())
+}
+
+var ProtoInteger = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Integer)(nil)
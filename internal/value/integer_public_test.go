@@ -0,0 +1,47 @@
+package value_test
+
+import (
+	"calabash/internal/value"
+	"math"
+	"testing"
+)
+
+func TestIntegerArithmetic(t *testing.T) {
+	table := []struct {
+		name string
+		got  *value.Integer
+		want string
+	}{
+		{name: "addition within int64", got: value.NewInteger(2).Add(value.NewInteger(3)), want: "5"},
+		{name: "addition overflowing int64", got: value.NewInteger(math.MaxInt64).Add(value.NewInteger(1)), want: "9223372036854775808"},
+		{name: "subtraction overflowing int64", got: value.NewInteger(math.MinInt64).Sub(value.NewInteger(1)), want: "-9223372036854775809"},
+		{name: "multiplication overflowing int64", got: value.NewInteger(math.MaxInt64).Mul(value.NewInteger(2)), want: "18446744073709551614"},
+		{name: "multiplying the smallest int64 by -1", got: value.NewInteger(-1).Mul(value.NewInteger(math.MinInt64)), want: "9223372036854775808"},
+		{name: "negating the smallest int64", got: value.NewInteger(math.MinInt64).Neg(), want: "9223372036854775808"},
+		{name: "results that fit are small again", got: value.NewInteger(math.MaxInt64).Add(value.NewInteger(1)).Sub(value.NewInteger(1)), want: "9223372036854775807"},
+	}
+
+	for _, e := range table {
+		if e.got.Text() != e.want {
+			t.Errorf("%q: expected %s, got %s", e.name, e.want, e.got.Text())
+		}
+	}
+
+	if _, ok := value.NewInteger(math.MaxInt64).Add(value.NewInteger(1)).Sub(value.NewInteger(1)).Int64(); !ok {
+		t.Error("Integers that fit in an int64 should be stored as one")
+	}
+}
+
+func TestNumericHash(t *testing.T) {
+	if value.NewInteger(1).Hash() != value.NewNumber(1).Hash() {
+		t.Error("Equal integers and floats should hash the same")
+	}
+
+	if value.NewNumber(0).Hash() != value.NewNumber(math.Copysign(0, -1)).Hash() {
+		t.Error("0 and -0 should hash the same")
+	}
+
+	if value.NewNumber(math.NaN()).Hash() == value.NewNumber(math.NaN()).Hash() {
+		t.Error("Each NaN should hash uniquely")
+	}
+}
@@ -0,0 +1,117 @@
+package value
+
+import (
+	"calabash/internal/uuid"
+	"fmt"
+)
+
+// Iterator produces values one at a time, computing each only when it is
+// asked for. Iterators are stateful: values inheriting a proto from the same
+// iterator share its position.
+type Iterator struct {
+	state *iteratorState
+	proto *Proto
+}
+
+type iteratorState struct {
+	next  func() (Value, bool, error)
+	close func() error
+	done  bool
+	hash  string
+}
+
+func (v *Iterator) v() vtype {
+	return value
+}
+
+func (v *Iterator) Hash() string {
+	if v.state.hash == "" {
+		v.state.hash = "it:" + uuid.V4()
+	}
+
+	return v.state.hash
+}
+
+func (v *Iterator) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Iterator) Inherit(p *Proto) Value {
+	return &Iterator{
+		state: v.state,
+		proto: p,
+	}
+}
+
+func (v *Iterator) String() string {
+	return Display(v)
+}
+
+func (v *Iterator) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Iterators are only equal to themselves
+func (v *Iterator) Equal(o Value) bool {
+	it, ok := o.(*Iterator)
+
+	return ok && it.state == v.state
+}
+
+func (v *Iterator) HashCode() uint64 {
+	return hashString(seedIterator, v.Hash())
+}
+
+// Next produces the iterator's next value. It reports false once the
+// iterator is exhausted, has failed or was closed, and keeps doing so.
+func (v *Iterator) Next() (Value, bool, error) {
+	if v.state.done {
+		return nil, false, nil
+	}
+
+	x, ok, err := v.state.next()
+
+	if !ok || err != nil {
+		v.state.done = true
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return x, ok, nil
+}
+
+// Close stops an iterator before it is exhausted so that it can release
+// what it holds. Closing an iterator that is already done does nothing.
+func (v *Iterator) Close() error {
+	if v.state.done {
+		return nil
+	}
+
+	v.state.done = true
+
+	if v.state.close == nil {
+		return nil
+	}
+
+	return v.state.close()
+}
+
+// NewIterator makes an iterator whose values come from `next`, which reports
+// false when there are none left. Hosts use it to hand scripts lazy
+// sequences. `close` may be nil; otherwise it is called if the iterator is
+// closed before `next` reports the end.
+func NewIterator(next func() (Value, bool, error), close func() error) *Iterator {
+	return &Iterator{
+		state: &iteratorState{next: next, close: close},
+		proto: ProtoIterator,
+	}
+}
+
+var ProtoIterator = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Iterator)(nil)
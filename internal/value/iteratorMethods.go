@@ -0,0 +1,268 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+)
+
+// Protocols of values that can be iterated: an iterator answers "next" with
+// its next value or `done`, and an iterable answers "iter" with an iterator
+var (
+	ProtocolIterator = newBuiltinProtocol("Iterator", protocolMethod{"next", 0})
+	ProtocolIterable = newBuiltinProtocol("Iterable", protocolMethod{"iter", 0})
+)
+
+func init() {
+	Globals["done"] = &Done{}
+	Globals["Iterator"] = ProtocolIterator
+	Globals["Iterable"] = ProtocolIterable
+
+	ProtoIterator.Protocols = []*Protocol{ProtocolIterator, ProtocolIterable}
+
+	for _, p := range []*Proto{ProtoTuple, ProtoSet, ProtoRange, ProtoBytes} {
+		p.Define(NewString("iter"), method("a sequence", nil, func(s Sequence, _ Evaluator) (interface{}, error) {
+			return sequenceIterator(s), nil
+		}))
+	}
+
+	// Records are iterated as [key, value] pairs, in the order of their keys
+	ProtoRecord.Define(NewString("iter"), recordMethod(nil, func(r *Record, _ Evaluator) (interface{}, error) {
+		return sequenceIterator(&pairs{es: r.Entries()}), nil
+	}))
+
+	ProtoString.Define(NewString("iter"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		rs := []rune(s.Value)
+		i := 0
+
+		return NewIterator(func() (Value, bool, error) {
+			if i >= len(rs) {
+				return nil, false, nil
+			}
+
+			i++
+
+			return NewString(string(rs[i-1])), true, nil
+		}, nil), nil
+	}))
+
+	ProtoIterator.Define(NewString("next"), iteratorMethod(nil, func(it *Iterator, _ Evaluator) (interface{}, error) {
+		v, ok, err := it.Next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			return &Done{}, nil
+		}
+
+		return v, nil
+	}))
+
+	ProtoIterator.Define(NewString("iter"), iteratorMethod(nil, func(it *Iterator, _ Evaluator) (interface{}, error) {
+		return it, nil
+	}))
+
+	ProtoIterator.Define(NewString("close"), iteratorMethod(nil, func(it *Iterator, _ Evaluator) (interface{}, error) {
+		if err := it.Close(); err != nil {
+			return nil, err
+		}
+
+		return &Bottom{}, nil
+	}))
+
+	ProtoIterator.Define(NewString("toTuple"), iteratorMethod(nil, func(it *Iterator, _ Evaluator) (interface{}, error) {
+		vs, err := Drain(it)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoIterator.Define(NewString("map"), iteratorMethod(params("f"), func(it *Iterator, e Evaluator) (interface{}, error) {
+		f := arg(e, "f")
+
+		return NewIterator(func() (Value, bool, error) {
+			v, ok, err := it.Next()
+
+			if !ok || err != nil {
+				return nil, false, err
+			}
+
+			r, err := e.Call(f, []Value{v})
+
+			if err != nil {
+				return nil, false, err
+			}
+
+			return r, true, nil
+		}, it.Close), nil
+	}))
+
+	ProtoIterator.Define(NewString("filter"), iteratorMethod(params("f"), func(it *Iterator, e Evaluator) (interface{}, error) {
+		f := arg(e, "f")
+
+		return NewIterator(func() (Value, bool, error) {
+			for {
+				v, ok, err := it.Next()
+
+				if !ok || err != nil {
+					return nil, false, err
+				}
+
+				keep, err := test(e, f, v)
+
+				if err != nil {
+					return nil, false, err
+				}
+
+				if keep {
+					return v, true, nil
+				}
+			}
+		}, it.Close), nil
+	}))
+
+	// Once `n` values have been taken the source is closed, so a generator
+	// cut short still runs its deferred expressions
+	ProtoIterator.Define(NewString("take"), iteratorMethod(params("n"), func(it *Iterator, e Evaluator) (interface{}, error) {
+		n, err := count(arg(e, "n"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewIterator(func() (Value, bool, error) {
+			if n == 0 {
+				return nil, false, it.Close()
+			}
+
+			n--
+
+			return it.Next()
+		}, it.Close), nil
+	}))
+}
+
+// Iterate returns an iterator over `v`: `v` itself when it is an iterator, or
+// one driven by the "next" or "iter" method of its proto. "next" is preferred,
+// since values inheriting a proto of their own may still inherit "iter" from
+// their kind. `call` invokes those methods.
+func Iterate(v Value, call func(Caller) (Value, error)) (*Iterator, error) {
+	if it, ok := v.(*Iterator); ok {
+		return it, nil
+	}
+
+	next, ok := boundMethod(v, "next")
+
+	if !ok {
+		iter, ok := boundMethod(v, "iter")
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Expect an iterator or an iterable value"}
+		}
+
+		r, err := call(iter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if it, ok := r.(*Iterator); ok {
+			return it, nil
+		}
+
+		if next, ok = boundMethod(r, "next"); !ok {
+			return nil, errors.RuntimeError{Msg: "Expect 'iter' to return an iterator"}
+		}
+	}
+
+	return NewIterator(func() (Value, bool, error) {
+		r, err := call(next)
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		if _, ok := r.(*Done); ok {
+			return nil, false, nil
+		}
+
+		return r, true, nil
+	}, nil), nil
+}
+
+// Drain collects the values left in `it`
+func Drain(it *Iterator) ([]Value, error) {
+	vs := []Value{}
+
+	for {
+		v, ok, err := it.Next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			return vs, nil
+		}
+
+		vs = append(vs, v)
+	}
+}
+
+// boundMethod looks up the method `k` of `v`, bound to `v`
+func boundMethod(v Value, k string) (Caller, bool) {
+	if v.Proto() == nil {
+		return nil, false
+	}
+
+	m, ok := Member(v, v.Proto(), NewString(k).Hash())
+	pm, isMethod := m.(*ProtoMethod)
+
+	if !ok || !isMethod {
+		return nil, false
+	}
+
+	return pm.Bind(v), true
+}
+
+func sequenceIterator(s interface {
+	Len() int
+	At(int) Value
+}) *Iterator {
+	i := 0
+
+	return NewIterator(func() (Value, bool, error) {
+		if i >= s.Len() {
+			return nil, false, nil
+		}
+
+		i++
+
+		return s.At(i - 1), true, nil
+	}, nil)
+}
+
+// pairs presents record entries as a sequence of [key, value] tuples
+type pairs struct {
+	es []struct {
+		K Value
+		V Value
+	}
+}
+
+func (p *pairs) Len() int {
+	return len(p.es)
+}
+
+func (p *pairs) At(i int) Value {
+	return NewTuple([]Value{p.es[i].K, p.es[i].V})
+}
+
+// iteratorMethod declares a built-in iterator method
+func iteratorMethod(ps []ast.Identifier, f func(it *Iterator, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("an iterator", ps, f)
+}
@@ -0,0 +1,189 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"math"
+	"math/big"
+)
+
+// Globals are the values every program can refer to without declaring them.
+// Declarations of the same name shadow them.
+var Globals = map[string]Value{}
+
+// ProtoMath holds the members of the `math` namespace
+var ProtoMath = &Proto{
+	Members: map[string]Value{},
+}
+
+func init() {
+	Globals["math"] = NewRecord(nil).Inherit(ProtoMath)
+
+	ProtoMath.Define(NewString("pi"), NewNumber(math.Pi))
+	ProtoMath.Define(NewString("e"), NewNumber(math.E))
+	ProtoMath.Define(NewString("inf"), NewNumber(math.Inf(1)))
+
+	for _, m := range []struct {
+		k string
+		f func(float64) float64
+	}{
+		{"sqrt", math.Sqrt},
+		{"cbrt", math.Cbrt},
+		{"log", math.Log},
+		{"log2", math.Log2},
+		{"log10", math.Log10},
+		{"exp", math.Exp},
+		{"sin", math.Sin},
+		{"cos", math.Cos},
+		{"tan", math.Tan},
+		{"asin", math.Asin},
+		{"acos", math.Acos},
+		{"atan", math.Atan},
+	} {
+		ProtoMath.Define(NewString(m.k), unaryMath(m.f))
+	}
+
+	ProtoMath.Define(NewString("atan2"), namespaceMethod(params("y", "x"), func(e Evaluator) (interface{}, error) {
+		ns, err := numberArgs(e, "y", "x")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNumber(math.Atan2(ns[0], ns[1])), nil
+	}))
+
+	ProtoMath.Define(NewString("min"), namespaceMethod(params("n", "...ns"), func(e Evaluator) (interface{}, error) {
+		return extreme(e, -1)
+	}))
+
+	ProtoMath.Define(NewString("max"), namespaceMethod(params("n", "...ns"), func(e Evaluator) (interface{}, error) {
+		return extreme(e, 1)
+	}))
+
+	ProtoMath.Define(NewString("div"), namespaceMethod(params("a", "b"), func(e Evaluator) (interface{}, error) {
+		return floorDivision(e, func(q, _ *big.Int) *big.Int {
+			return q
+		})
+	}))
+
+	ProtoMath.Define(NewString("mod"), namespaceMethod(params("a", "b"), func(e Evaluator) (interface{}, error) {
+		return floorDivision(e, func(_, m *big.Int) *big.Int {
+			return m
+		})
+	}))
+}
+
+// namespaceMethod declares a member of a namespace such as `math`, which
+// does not depend on the value it is called on
+func namespaceMethod(ps []ast.Identifier, f func(e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("a value", ps, func(_ Value, e Evaluator) (interface{}, error) {
+		return f(e)
+	})
+}
+
+func unaryMath(f func(float64) float64) *ProtoMethod {
+	return namespaceMethod(params("n"), func(e Evaluator) (interface{}, error) {
+		n, err := numberArg(e, "n")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNumber(f(n)), nil
+	})
+}
+
+func numberArgs(e Evaluator, names ...string) ([]float64, error) {
+	ns := make([]float64, len(names))
+
+	for i, name := range names {
+		n, err := numberArg(e, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ns[i] = n
+	}
+
+	return ns, nil
+}
+
+// extreme returns whichever of the "n" and "ns" arguments compares as `want`
+// (-1 for the smallest, 1 for the largest) to all the others. NaN wins over
+// every number.
+func extreme(e Evaluator, want int) (Value, error) {
+	acc := arg(e, "n")
+
+	for _, v := range append([]Value{acc}, arg(e, "ns").(*Tuple).Items()...) {
+		if !IsNumeric(v) {
+			return nil, errors.RuntimeError{Msg: "Expect every argument to be a number"}
+		}
+
+		c, ok := CompareNumbers(v, acc)
+
+		if !ok {
+			if f, _ := ToFloat(v); math.IsNaN(f) {
+				return v, nil
+			}
+
+			return acc, nil
+		}
+
+		if c == want {
+			acc = v
+		}
+	}
+
+	return acc, nil
+}
+
+// floorDivision divides the "a" argument by "b", which must be whole numbers,
+// rounding the quotient down so that the remainder has the sign of the
+// divisor. `pick` selects the quotient or the remainder. The result is an
+// integer unless either operand is a float.
+func floorDivision(e Evaluator, pick func(q, m *big.Int) *big.Int) (Value, error) {
+	a, b := arg(e, "a"), arg(e, "b")
+	ai, oka := wholeNumber(a)
+	bi, okb := wholeNumber(b)
+
+	if !oka || !okb {
+		return nil, errors.RuntimeError{Msg: "Expect integer division operands to be integers"}
+	}
+
+	if bi.Sign() == 0 {
+		return nil, errors.RuntimeError{Msg: "Integer division by zero"}
+	}
+
+	q, m := new(big.Int).QuoRem(ai.Big(), bi.Big(), new(big.Int))
+
+	if m.Sign() != 0 && m.Sign() != bi.Sign() {
+		q.Sub(q, big.NewInt(1))
+		m.Add(m, bi.Big())
+	}
+
+	r := NewBigInteger(pick(q, m))
+	_, okai := a.(*Integer)
+	_, okbi := b.(*Integer)
+
+	if okai && okbi {
+		return r, nil
+	}
+
+	return NewNumber(r.Float()), nil
+}
+
+// wholeNumber converts an integer, or a number holding a whole value, to an
+// integer
+func wholeNumber(v Value) (*Integer, bool) {
+	switch v := v.(type) {
+	case *Integer:
+		return v, true
+
+	case *Number:
+		return IntegerFromFloat(v.Value)
+	}
+
+	return nil, false
+}
@@ -1,10 +1,16 @@
 package value
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+)
 
 type Number struct {
 	Value float64
 	proto *Proto
+	hash  string
+	nan   uint64 // Tells NaNs apart
 }
 
 func (v *Number) v() vtype {
@@ -12,6 +18,15 @@ func (v *Number) v() vtype {
 }
 
 func (v *Number) Hash() string {
+	if v.hash != "" {
+		return v.hash
+	}
+
+	// Whole numbers hash like the integers they are equal to
+	if i, ok := IntegerFromFloat(v.Value); ok {
+		return i.Hash()
+	}
+
 	return fmt.Sprintf("n:%v", v.Value)
 }
 
@@ -26,15 +41,57 @@ func (v *Number) Inherit(p *Proto) Value {
 	return n
 }
 
+func (v *Number) String() string {
+	return Display(v)
+}
+
+func (v *Number) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Number) Equal(o Value) bool {
+	if math.IsNaN(v.Value) {
+		return v == o
+	}
+
+	c, ok := CompareNumbers(v, o)
+
+	return ok && c == 0
+}
+
+// Whole numbers have the hash code of the integer they are equal to
+func (v *Number) HashCode() uint64 {
+	if math.IsNaN(v.Value) {
+		return mix(seedNumber, v.nan)
+	}
+
+	if i, ok := IntegerFromFloat(v.Value); ok {
+		return i.HashCode()
+	}
+
+	return mix(seedNumber, math.Float64bits(v.Value))
+}
+
 func NewNumber(v float64) *Number {
-	return &Number{
+	n := &Number{
 		Value: v,
 		proto: ProtoNumber,
 	}
+
+	// NaN is not equal to anything, itself included, so every NaN is given a
+	// hash of its own
+	if math.IsNaN(v) {
+		n.nan = atomic.AddUint64(&nans, 1)
+		n.hash = fmt.Sprintf("n:NaN:%d", n.nan)
+	}
+
+	return n
 }
 
+var nans uint64
+
 var ProtoNumber = &Proto{
-	Methods: map[string]Caller{},
+	Members: map[string]Value{},
 }
 
 // Compile time checks
@@ -0,0 +1,181 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	for _, m := range []struct {
+		k string
+		f func(float64) float64
+	}{
+		{"floor", math.Floor},
+		{"ceil", math.Ceil},
+		{"round", math.Round},
+		{"trunc", math.Trunc},
+	} {
+		f := m.f
+
+		// Integers are already whole, so rounding them changes nothing
+		defineNumeric(m.k, nil, func(i *Integer, _ Evaluator) (interface{}, error) {
+			return i, nil
+		}, func(n *Number, _ Evaluator) (interface{}, error) {
+			return NewNumber(f(n.Value)), nil
+		})
+	}
+
+	defineNumeric("abs", nil, func(i *Integer, _ Evaluator) (interface{}, error) {
+		if i.Sign() < 0 {
+			return i.Neg(), nil
+		}
+
+		return i, nil
+	}, func(n *Number, _ Evaluator) (interface{}, error) {
+		return NewNumber(math.Abs(n.Value)), nil
+	})
+
+	defineNumeric("sign", nil, func(i *Integer, _ Evaluator) (interface{}, error) {
+		return NewInteger(int64(i.Sign())), nil
+	}, func(n *Number, _ Evaluator) (interface{}, error) {
+		switch {
+		case n.Value > 0:
+			return NewNumber(1), nil
+
+		case n.Value < 0:
+			return NewNumber(-1), nil
+		}
+
+		// Zero and NaN are their own sign
+		return NewNumber(n.Value), nil
+	})
+
+	defineNumeric("isNaN", nil, func(_ *Integer, _ Evaluator) (interface{}, error) {
+		return NewBoolean(false), nil
+	}, func(n *Number, _ Evaluator) (interface{}, error) {
+		return NewBoolean(math.IsNaN(n.Value)), nil
+	})
+
+	defineNumeric("isFinite", nil, func(_ *Integer, _ Evaluator) (interface{}, error) {
+		return NewBoolean(true), nil
+	}, func(n *Number, _ Evaluator) (interface{}, error) {
+		return NewBoolean(!math.IsNaN(n.Value) && !math.IsInf(n.Value, 0)), nil
+	})
+
+	defineNumeric("isInteger", nil, func(_ *Integer, _ Evaluator) (interface{}, error) {
+		return NewBoolean(true), nil
+	}, func(n *Number, _ Evaluator) (interface{}, error) {
+		return NewBoolean(isInteger(n.Value)), nil
+	})
+
+	defineNumeric("toFixed", params("digits"), func(i *Integer, e Evaluator) (interface{}, error) {
+		d, err := fixedDigits(e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if d == 0 {
+			return NewString(i.Text()), nil
+		}
+
+		return NewString(i.Text() + "." + strings.Repeat("0", d)), nil
+	}, func(n *Number, e Evaluator) (interface{}, error) {
+		d, err := fixedDigits(e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewString(strconv.FormatFloat(n.Value, 'f', d, 64)), nil
+	})
+
+	defineNumeric("clamp", params("lo", "hi"), func(i *Integer, e Evaluator) (interface{}, error) {
+		return clamp(i, e)
+	}, func(n *Number, e Evaluator) (interface{}, error) {
+		return clamp(n, e)
+	})
+
+	defineNumeric("toInteger", nil, func(i *Integer, _ Evaluator) (interface{}, error) {
+		return i, nil
+	}, func(n *Number, _ Evaluator) (interface{}, error) {
+		i, ok := IntegerFromFloat(math.Trunc(n.Value))
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Cannot convert a non-finite number to an integer"}
+		}
+
+		return i, nil
+	})
+
+	defineNumeric("toFloat", nil, func(i *Integer, _ Evaluator) (interface{}, error) {
+		return NewNumber(i.Float()), nil
+	}, func(n *Number, _ Evaluator) (interface{}, error) {
+		return n, nil
+	})
+}
+
+// defineNumeric declares a method under the key `k` for integers and numbers
+func defineNumeric(k string, ps []ast.Identifier, fi func(i *Integer, e Evaluator) (interface{}, error), fn func(n *Number, e Evaluator) (interface{}, error)) {
+	ProtoInteger.Define(NewString(k), method("an integer", ps, fi))
+	ProtoNumber.Define(NewString(k), method("a number", ps, fn))
+}
+
+// numberArg reads the argument bound to `name`, which must be numeric, as a
+// float
+func numberArg(e Evaluator, name string) (float64, error) {
+	n, ok := ToFloat(arg(e, name))
+
+	if !ok {
+		return 0, errors.RuntimeError{Msg: "Expect '" + name + "' to be a number"}
+	}
+
+	return n, nil
+}
+
+// clamp bounds `n` by the "lo" and "hi" arguments, returning whichever of
+// the three values is in the middle
+func clamp(n Value, e Evaluator) (Value, error) {
+	lo, hi := arg(e, "lo"), arg(e, "hi")
+
+	if !IsNumeric(lo) || !IsNumeric(hi) {
+		return nil, errors.RuntimeError{Msg: "Expect clamp bounds to be numbers"}
+	}
+
+	if c, ok := CompareNumbers(lo, hi); ok && c > 0 {
+		return nil, errors.RuntimeError{Msg: "Cannot clamp with a lower bound above the upper bound"}
+	}
+
+	if c, ok := CompareNumbers(n, lo); ok && c < 0 {
+		return lo, nil
+	}
+
+	if c, ok := CompareNumbers(n, hi); ok && c > 0 {
+		return hi, nil
+	}
+
+	return n, nil
+}
+
+func isInteger(f float64) bool {
+	return f == math.Trunc(f) && !math.IsInf(f, 0)
+}
+
+// fixedDigits reads the number of decimal places asked of 'toFixed', which
+// must fit in a string 'repeat' could build
+func fixedDigits(e Evaluator) (int, error) {
+	d, err := count(arg(e, "digits"))
+
+	if err != nil {
+		return 0, err
+	}
+
+	if d > maxStringLen {
+		return 0, errors.RuntimeError{Msg: "Too many digits for 'toFixed'"}
+	}
+
+	return d, nil
+}
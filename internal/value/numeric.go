@@ -0,0 +1,94 @@
+package value
+
+import "math/big"
+
+// IsNumeric tells whether `v` is a number or an integer
+func IsNumeric(v Value) bool {
+	switch v.(type) {
+	case *Number, *Integer:
+		return true
+	}
+
+	return false
+}
+
+// ToFloat converts a number or an integer to the nearest float
+func ToFloat(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case *Number:
+		return v.Value, true
+
+	case *Integer:
+		return v.Float(), true
+	}
+
+	return 0, false
+}
+
+// ToInt converts an integer, or a number holding a whole value, to an int
+func ToInt(v Value) (int, bool) {
+	switch v := v.(type) {
+	case *Integer:
+		i, ok := v.Int64()
+
+		return int(i), ok && int64(int(i)) == i
+
+	case *Number:
+		if i, ok := IntegerFromFloat(v.Value); ok {
+			return ToInt(i)
+		}
+	}
+
+	return 0, false
+}
+
+// CompareNumbers compares two numeric values exactly, whatever their kinds,
+// returning -1, 0 or 1. It fails when either value is not numeric or is NaN.
+func CompareNumbers(a, b Value) (int, bool) {
+	ai, oka := a.(*Integer)
+	bi, okb := b.(*Integer)
+
+	if oka && okb {
+		return ai.Cmp(bi), true
+	}
+
+	an, oka := a.(*Number)
+	bn, okb := b.(*Number)
+
+	if oka && okb && an.Value == an.Value && bn.Value == bn.Value {
+		switch {
+		case an.Value < bn.Value:
+			return -1, true
+
+		case an.Value > bn.Value:
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	af, oka := bigFloat(a)
+	bf, okb := bigFloat(b)
+
+	if !oka || !okb {
+		return 0, false
+	}
+
+	return af.Cmp(bf), true
+}
+
+func bigFloat(v Value) (*big.Float, bool) {
+	switch v := v.(type) {
+	case *Integer:
+		return new(big.Float).SetInt(v.Big()), true
+
+	case *Number:
+		if v.Value != v.Value {
+			return nil, false
+		}
+
+		return big.NewFloat(v.Value), true
+	}
+
+	return nil, false
+}
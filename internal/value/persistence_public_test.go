@@ -0,0 +1,32 @@
+package value_test
+
+import (
+	"calabash/internal/value"
+	"testing"
+)
+
+func TestPersistentTuples(t *testing.T) {
+	tpl := value.NewTuple(nil)
+
+	// Enough elements for the tuple to grow a few levels
+	for i := 0; i < 2000; i++ {
+		tpl = tpl.Push(value.NewInteger(int64(i)))
+	}
+
+	a := tpl.Push(value.NewString("a"))
+	b := tpl.Push(value.NewString("b"))
+
+	if tpl.Len() != 2000 || a.Len() != 2001 || b.Len() != 2001 {
+		t.Fatalf("Expected lengths 2000, 2001 and 2001, got %d, %d and %d", tpl.Len(), a.Len(), b.Len())
+	}
+
+	for i := 0; i < 2000; i++ {
+		if !tpl.At(i).Equal(value.NewInteger(int64(i))) {
+			t.Fatalf("Element %d is %v", i, tpl.At(i))
+		}
+	}
+
+	if !a.At(2000).Equal(value.NewString("a")) || !b.At(2000).Equal(value.NewString("b")) {
+		t.Errorf("Pushing onto the same tuple twice should not share the new elements, got %v and %v", a.At(2000), b.At(2000))
+	}
+}
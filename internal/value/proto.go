@@ -1,11 +1,18 @@
 package value
 
-import "calabash/internal/slice"
+import (
+	"calabash/errors"
+	"calabash/internal/slice"
+	"fmt"
+)
 
 type Proto struct {
-	Methods map[string]Caller
-	Keys    []string
-	hash    string
+	Members   map[string]Value // Proto methods as well as plain values such as constants
+	Keys      []string
+	Parent    *Proto
+	Protocols []*Protocol // Protocols the proto declares it implements
+	names     map[string]Value
+	hash      string
 }
 
 func (v *Proto) v() vtype {
@@ -15,20 +22,281 @@ func (v *Proto) v() vtype {
 func (v *Proto) Hash() string {
 	if v.hash == "" {
 		v.hash = slice.Fold(v.Keys, "prt:", func(k string, acc string, _ int) string {
-			return acc + k + "->" + v.Methods[k].Hash()
+			return acc + k + "->" + v.Members[k].Hash()
 		})
+
+		if v.Parent != nil {
+			v.hash += "<" + v.Parent.Hash()
+		}
 	}
 
 	return v.hash
 }
 
 func (v *Proto) Proto() *Proto {
-	return nil
+	return ProtoProto
 }
 
 func (v *Proto) Inherit(_ *Proto) Value {
 	return v
 }
 
+func (v *Proto) String() string {
+	return Display(v)
+}
+
+func (v *Proto) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Protos are equal when they define equal members under the same keys and
+// have equal parents
+func (v *Proto) Equal(o Value) bool {
+	p, ok := o.(*Proto)
+
+	if !ok || len(p.Members) != len(v.Members) {
+		return false
+	}
+
+	if p == v {
+		return true
+	}
+
+	for k, m := range v.Members {
+		if pm, ok := p.Members[k]; !ok || !pm.Equal(m) {
+			return false
+		}
+	}
+
+	if v.Parent == nil || p.Parent == nil {
+		return v.Parent == p.Parent
+	}
+
+	return v.Parent.Equal(p.Parent)
+}
+
+func (v *Proto) HashCode() uint64 {
+	var h uint64
+
+	// Members are summed so that the order they were defined in does not
+	// matter
+	for k, m := range v.Members {
+		h += scramble(mix(hashString(seedProto, k), m.HashCode()))
+	}
+
+	if v.Parent != nil {
+		h = mix(h, v.Parent.HashCode())
+	}
+
+	return h
+}
+
+// Define adds the member `m` under the key `k`, keeping track of the order
+// keys were defined in and of the key's value so it can be reflected on
+func (v *Proto) Define(k Value, m Value) {
+	kstr := k.Hash()
+
+	if _, ok := v.Members[kstr]; !ok {
+		v.Keys = append(v.Keys, kstr)
+	}
+
+	if v.Members == nil {
+		v.Members = map[string]Value{}
+	}
+
+	if v.names == nil {
+		v.names = map[string]Value{}
+	}
+
+	v.Members[kstr] = m
+	v.names[kstr] = k
+	v.hash = ""
+}
+
+// KeyValue returns the value a member's hashed key was defined with
+func (v *Proto) KeyValue(k string) (Value, bool) {
+	kv, ok := v.names[k]
+
+	return kv, ok
+}
+
+// Lookup finds the member stored under `k` on the proto or, failing that, on
+// the nearest parent that defines it
+func (v *Proto) Lookup(k string) (Value, bool) {
+	for p := v; p != nil; p = p.Parent {
+		if m, ok := p.Members[k]; ok {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// Declares checks whether the proto or any of its parents declares that it
+// implements `pc`
+func (v *Proto) Declares(pc *Protocol) bool {
+	for p := v; p != nil; p = p.Parent {
+		for _, d := range p.Protocols {
+			if d == pc {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Member resolves `k` for the receiver `v` starting from the proto `p`. Every
+// proto chain implicitly ends in the built-in proto for the receiver's kind so
+// custom protos keep the methods the kind already had.
+func Member(v Value, p *Proto, k string) (Value, bool) {
+	if m, ok := p.Lookup(k); ok {
+		return m, true
+	}
+
+	if m, ok := Builtin(v).Lookup(k); ok {
+		return m, true
+	}
+
+	return ProtoValue.Lookup(k)
+}
+
+// Compose merges the members of several protos, including the ones they
+// inherit, into a new proto. A key defined differently by more than one of the
+// protos is a clash and is reported as an error unless `overridden` reports
+// that the key will be redefined by the caller.
+func Compose(ps []*Proto, overridden func(k string) bool) (*Proto, error) {
+	c := &Proto{Members: map[string]Value{}}
+
+	for _, p := range ps {
+		fs := p.flatten()
+
+		for _, k := range fs.Keys {
+			m := fs.Members[k]
+			prev, ok := c.Members[k]
+
+			if !ok {
+				c.define(k, m, fs)
+				continue
+			}
+
+			if !prev.Equal(m) && (overridden == nil || !overridden(k)) {
+				kv, _ := fs.KeyValue(k)
+				return nil, errors.RuntimeError{Msg: fmt.Sprintf("Member %s is defined by more than one composed proto and must be overridden", Display(kv))}
+			}
+		}
+	}
+
+	return c, nil
+}
+
+// flatten collapses a proto and its parents into a single proto where members
+// closer to `v` take precedence
+func (v *Proto) flatten() *Proto {
+	if v.Parent == nil {
+		return v
+	}
+
+	f := v.Parent.flatten()
+	fl := &Proto{Members: map[string]Value{}}
+
+	for _, k := range f.Keys {
+		fl.define(k, f.Members[k], f)
+	}
+
+	for _, k := range v.Keys {
+		fl.define(k, v.Members[k], v)
+	}
+
+	return fl
+}
+
+// define copies the member stored under the hashed key `k` of `from`
+func (v *Proto) define(k string, m Value, from *Proto) {
+	if kv, ok := from.KeyValue(k); ok {
+		v.Define(kv, m)
+		return
+	}
+
+	if _, ok := v.Members[k]; !ok {
+		v.Keys = append(v.Keys, k)
+	}
+
+	v.Members[k] = m
+}
+
+// Builtin returns the proto values of the same kind as `v` are created with
+func Builtin(v Value) *Proto {
+	switch v.(type) {
+	case *Number:
+		return ProtoNumber
+
+	case *Integer:
+		return ProtoInteger
+
+	case *String:
+		return ProtoString
+
+	case *Bytes:
+		return ProtoBytes
+
+	case *Boolean:
+		return ProtoBoolean
+
+	case *Tuple:
+		return ProtoTuple
+
+	case *Record:
+		return ProtoRecord
+
+	case *Set:
+		return ProtoSet
+
+	case *Range:
+		return ProtoRange
+
+	case *Instant:
+		return ProtoInstant
+
+	case *Duration:
+		return ProtoDuration
+
+	case *Regex:
+		return ProtoRegex
+
+	case *Iterator:
+		return ProtoIterator
+
+	case *Done:
+		return ProtoDone
+
+	case *Error:
+		return ProtoError
+
+	case *Protocol:
+		return ProtoProtocol
+
+	case *Function, *ProtoMethod:
+		return ProtoFunction
+
+	case *Bottom:
+		return ProtoBottom
+
+	case *Proto:
+		return ProtoProto
+	}
+
+	return nil
+}
+
+// ProtoValue holds the members every value has, whatever its kind
+var ProtoValue = &Proto{
+	Members: map[string]Value{},
+}
+
+var ProtoProto = &Proto{
+	Members: map[string]Value{},
+}
+
 // Compile time checks
 var _ Value = (*Proto)(nil)
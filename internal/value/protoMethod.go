@@ -6,6 +6,7 @@ import (
 	"calabash/internal/slice"
 	"calabash/internal/uuid"
 	"calabash/lexer/tokens"
+	"fmt"
 	"strconv"
 )
 
@@ -20,6 +21,8 @@ type ProtoMethod struct {
 	hash        string
 	call        func(me Value, e Evaluator) (interface{}, error)
 	Inheritable *Proto
+	Owner       *Proto // Proto the method was declared in; `super` starts after it
+	Getter      bool   // Called as soon as it is accessed with `->`
 }
 
 func (pm *ProtoMethod) v() vtype {
@@ -27,19 +30,47 @@ func (pm *ProtoMethod) v() vtype {
 }
 
 func (pm *ProtoMethod) Proto() *Proto {
-	return nil
+	return ProtoFunction
 }
 
 func (v *ProtoMethod) Inherit(_ *Proto) Value {
 	return v
 }
 
+func (v *ProtoMethod) String() string {
+	return Display(v)
+}
+
+func (v *ProtoMethod) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Proto methods are equal when they are the same method, bound to equal
+// values
+func (pm *ProtoMethod) Equal(o Value) bool {
+	m, ok := o.(*ProtoMethod)
+
+	if !ok || m.Hash() != pm.Hash() || (m.Me == nil) != (pm.Me == nil) {
+		return false
+	}
+
+	return pm.Me == nil || pm.Me.Equal(m.Me)
+}
+
+func (pm *ProtoMethod) HashCode() uint64 {
+	return hashString(seedFunction, pm.Hash())
+}
+
 func (pm *ProtoMethod) Apply(vs []Value) Caller {
 	return &ProtoMethod{
-		ParamList: pm.ParamList,
-		Apps:      append(pm.Apps, vs...),
-		Me:        pm.Me,
-		call:      pm.call,
+		ParamList:   pm.ParamList,
+		Apps:        append(pm.Apps, vs...),
+		Depth:       pm.Depth,
+		Me:          pm.Me,
+		call:        pm.call,
+		Inheritable: pm.Inheritable,
+		Owner:       pm.Owner,
+		Getter:      pm.Getter,
 	}
 }
 
@@ -54,8 +85,10 @@ func (pm *ProtoMethod) Bind(me Value) Caller {
 		Depth:       pm.Depth,
 		Apps:        pm.Apps,
 		call:        pm.call,
-		hash:        pm.hash,
+		hash:        pm.Hash(), // Bindings of a method are the same method
 		Inheritable: pm.Inheritable,
+		Owner:       pm.Owner,
+		Getter:      pm.Getter,
 	}
 }
 
@@ -112,7 +145,7 @@ func (pm *ProtoMethod) Closure(env *environment.Environment[Value]) *environment
 	return environment.Slice(env, d)
 }
 
-func ProtoMethodFromFn(fn *Function) *ProtoMethod {
+func ProtoMethodFromFn(fn *Function, owner *Proto) *ProtoMethod {
 	return &ProtoMethod{
 		Me:        nil,
 		ParamList: fn.ParamList,
@@ -123,10 +156,12 @@ func ProtoMethodFromFn(fn *Function) *ProtoMethod {
 			defer e.PopEnv()
 
 			e.AddEnv("me", me)
+			e.AddEnv("super", owner)
 
 			return fn.Call(e)
 		},
-		hash: fn.hash,
+		hash:  fn.hash,
+		Owner: owner,
 	}
 }
 
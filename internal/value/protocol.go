@@ -0,0 +1,179 @@
+package value
+
+import (
+	"calabash/internal/slice"
+	"fmt"
+)
+
+// Protocol is a named set of method keys, each with the arity a proto's
+// method must have to implement it
+type Protocol struct {
+	Name    string
+	Keys    []string
+	Arities map[string]int
+	names   map[string]Value // Values of the keys, by their hash
+	proto   *Proto
+	hash    string
+}
+
+func (v *Protocol) v() vtype {
+	return value
+}
+
+func (v *Protocol) Hash() string {
+	if v.hash == "" {
+		v.hash = slice.Fold(v.Keys, "ptc:"+v.Name+":", func(k string, acc string, _ int) string {
+			return fmt.Sprintf("%s%s/%d", acc, k, v.Arities[k])
+		})
+	}
+
+	return v.hash
+}
+
+func (v *Protocol) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Protocol) Inherit(p *Proto) Value {
+	pc := *v
+	pc.proto = p
+
+	return &pc
+}
+
+func (v *Protocol) String() string {
+	return Display(v)
+}
+
+func (v *Protocol) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Protocol) Equal(o Value) bool {
+	p, ok := o.(*Protocol)
+
+	if !ok || p.Name != v.Name || len(p.Keys) != len(v.Keys) {
+		return false
+	}
+
+	for _, k := range v.Keys {
+		if n, ok := p.Arities[k]; !ok || n != v.Arities[k] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (v *Protocol) HashCode() uint64 {
+	h := hashString(seedProtocol, v.Name)
+
+	for _, k := range v.Keys {
+		h += scramble(mix(hashString(seedProtocol, k), uint64(v.Arities[k])))
+	}
+
+	return h
+}
+
+// MissingFrom reports the first key of the protocol that the proto chain
+// starting at `p` does not implement with a method of the right arity
+func (v *Protocol) MissingFrom(p *Proto) (string, bool) {
+	if p.Declares(v) {
+		return "", false
+	}
+
+	return v.missing(p.Lookup)
+}
+
+// MissingFor is like `MissingFrom` but resolves keys for the value `val`, so
+// the built-in proto of its kind is considered as well
+func (v *Protocol) MissingFor(val Value) (string, bool) {
+	p := val.Proto()
+
+	if p.Declares(v) || Builtin(val).Declares(v) {
+		return "", false
+	}
+
+	return v.missing(func(k string) (Value, bool) {
+		return Member(val, p, k)
+	})
+}
+
+func (v *Protocol) missing(lookup func(k string) (Value, bool)) (string, bool) {
+	for _, k := range v.Keys {
+		m, ok := lookup(k)
+
+		if !ok {
+			return k, true
+		}
+
+		c, ok := m.(Caller)
+		n := v.Arities[k]
+
+		if !ok || !(c.Arity() == n || (c.Rest() && c.Arity() <= n)) {
+			return k, true
+		}
+	}
+
+	return "", false
+}
+
+// KeyValue returns the value a method's hashed key was declared with
+func (v *Protocol) KeyValue(k string) (Value, bool) {
+	kv, ok := v.names[k]
+
+	return kv, ok
+}
+
+// NewProtocol declares a protocol requiring a method under each of `keys`
+// that takes the number of arguments at the same index of `arities`
+func NewProtocol(name string, keys []Value, arities []int) *Protocol {
+	pc := &Protocol{
+		Name:    name,
+		Keys:    make([]string, len(keys)),
+		Arities: map[string]int{},
+		names:   map[string]Value{},
+		proto:   ProtoProtocol,
+	}
+
+	for i, k := range keys {
+		pc.Keys[i] = k.Hash()
+		pc.Arities[pc.Keys[i]] = arities[i]
+		pc.names[pc.Keys[i]] = k
+	}
+
+	return pc
+}
+
+type protocolMethod struct {
+	key   string
+	arity int
+}
+
+// newBuiltinProtocol declares a protocol whose method keys are strings
+func newBuiltinProtocol(name string, ms ...protocolMethod) *Protocol {
+	keys := make([]Value, len(ms))
+	arities := make([]int, len(ms))
+
+	for i, m := range ms {
+		keys[i], arities[i] = NewString(m.key), m.arity
+	}
+
+	return NewProtocol(name, keys, arities)
+}
+
+var ProtoProtocol = &Proto{
+	Members: map[string]Value{},
+}
+
+// Protocols declared by the built-in protos
+var (
+	ProtocolStringify  = newBuiltinProtocol("Stringify", protocolMethod{"stringify", 0})
+	ProtocolSized      = newBuiltinProtocol("Sized", protocolMethod{"len", 0})
+	ProtocolContainer  = newBuiltinProtocol("Container", protocolMethod{"contains", 1})
+	ProtocolKeyed      = newBuiltinProtocol("Keyed", protocolMethod{"get", 1})
+	ProtocolAppendable = newBuiltinProtocol("Appendable", protocolMethod{"push", 1})
+)
+
+// Compile time checks
+var _ Value = (*Protocol)(nil)
@@ -0,0 +1,54 @@
+package value_test
+
+import (
+	"calabash/internal/value"
+	"testing"
+)
+
+func TestProtocolMissing(t *testing.T) {
+	t.Run("built-in protos declare their protocols", func(t *testing.T) {
+		table := []struct {
+			name string
+			v    value.Value
+			pc   *value.Protocol
+		}{
+			{name: "tuples are appendable", v: value.NewTuple([]value.Value{}), pc: value.ProtocolAppendable},
+			{name: "numbers are stringifiable", v: value.NewNumber(1), pc: value.ProtocolStringify},
+			{name: "booleans are stringifiable", v: value.NewBoolean(true), pc: value.ProtocolStringify},
+			{name: "ranges are sized", v: value.NewRange(0, 1, 1, false), pc: value.ProtocolSized},
+			{name: "ranges are containers", v: value.NewRange(0, 1, 1, false), pc: value.ProtocolContainer},
+		}
+
+		for _, e := range table {
+			if !value.Builtin(e.v).Declares(e.pc) {
+				t.Errorf("%q: built-in proto does not declare protocol %q", e.name, e.pc.Name)
+			}
+
+			if _, missing := e.pc.MissingFor(e.v); missing {
+				t.Errorf("%q: value does not implement protocol %q", e.name, e.pc.Name)
+			}
+		}
+	})
+
+	t.Run("values are checked structurally", func(t *testing.T) {
+		pc := value.NewProtocol("Sized", []value.Value{value.NewString("len")}, []int{0})
+
+		if _, missing := pc.MissingFor(value.NewRange(0, 1, 1, false)); missing {
+			t.Error("Ranges should structurally implement a protocol requiring 'len'")
+		}
+
+		k, missing := pc.MissingFor(value.NewNumber(1))
+
+		if !missing || k != value.NewString("len").Hash() {
+			t.Error("Numbers should be missing 'len'")
+		}
+	})
+
+	t.Run("arities must match", func(t *testing.T) {
+		pc := value.NewProtocol("Container", []value.Value{value.NewString("contains")}, []int{2})
+
+		if _, missing := pc.MissingFor(value.NewRange(0, 1, 1, false)); !missing {
+			t.Error("Methods with the wrong arity should not implement a protocol")
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package value
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Range is a lazily evaluated sequence of numbers. Items are computed on
+// demand from the bounds and step so no backing tuple is ever allocated.
+// Ranges whose bounds and step are all integers keep them exactly, so their
+// items are exact integers however large they get; the float fields then hold
+// the nearest floats.
+type Range struct {
+	Start     float64
+	End       float64
+	Step      float64
+	Inclusive bool
+	ints      *intBounds // Only set when bounds and step are all integers
+	proto     *Proto
+}
+
+type intBounds struct {
+	start, end, step *Integer
+}
+
+func (v *Range) v() vtype {
+	return value
+}
+
+func (v *Range) Hash() string {
+	if v.ints != nil {
+		return fmt.Sprintf("rng:%s,%s,%s,%t", v.ints.start.Text(), v.ints.end.Text(), v.ints.step.Text(), v.Inclusive)
+	}
+
+	return fmt.Sprintf("rng:%v,%v,%v,%t", v.Start, v.End, v.Step, v.Inclusive)
+}
+
+func (v *Range) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Range) Inherit(p *Proto) Value {
+	r := *v
+	r.proto = p
+
+	return &r
+}
+
+func (v *Range) String() string {
+	return Display(v)
+}
+
+func (v *Range) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Ranges are equal when they have the same bounds and step, counting an
+// integer bound as different from the number with its value, since the items
+// are integers only when every bound is
+func (v *Range) Equal(o Value) bool {
+	r, ok := o.(*Range)
+
+	if !ok || r.Inclusive != v.Inclusive || (r.ints == nil) != (v.ints == nil) {
+		return false
+	}
+
+	if v.ints != nil {
+		return r.ints.start.Cmp(v.ints.start) == 0 && r.ints.end.Cmp(v.ints.end) == 0 && r.ints.step.Cmp(v.ints.step) == 0
+	}
+
+	return r.Start == v.Start && r.End == v.End && r.Step == v.Step
+}
+
+func (v *Range) HashCode() uint64 {
+	var h uint64
+
+	if v.ints != nil {
+		h = mix(seedRange, v.ints.start.HashCode())
+		h = mix(h, v.ints.end.HashCode())
+		h = mix(h, v.ints.step.HashCode())
+	} else {
+		h = mix(seedRange, math.Float64bits(v.Start))
+		h = mix(h, math.Float64bits(v.End))
+		h = mix(h, math.Float64bits(v.Step))
+	}
+
+	if v.Inclusive {
+		h = mix(h, 1)
+	}
+
+	return h
+}
+
+// Integral reports whether the bounds and step are all integers, so the items
+// are too
+func (v *Range) Integral() bool {
+	return v.ints != nil
+}
+
+// Len counts the items of the range. Ranges built by hosts may have bounds the
+// interpreter rejects, so a count that is not a number is 0 and one too large
+// for an int is capped.
+func (v *Range) Len() int {
+	if v.ints != nil {
+		return v.intLen()
+	}
+
+	span := (v.End - v.Start) / v.Step
+	n := math.Ceil(span)
+
+	if v.Inclusive {
+		n = math.Floor(span) + 1
+	}
+
+	switch {
+	case n < 0 || math.IsNaN(n):
+		return 0
+
+	case n >= math.MaxInt:
+		return math.MaxInt
+	}
+
+	return int(n)
+}
+
+// intLen counts the items of an integral range, capped like `Len`
+func (v *Range) intLen() int {
+	n := v.intCount()
+
+	if !n.IsInt64() || n.Int64() > math.MaxInt {
+		return math.MaxInt
+	}
+
+	return int(n.Int64())
+}
+
+// intCount counts the items of an integral range exactly
+func (v *Range) intCount() *big.Int {
+	d := new(big.Int).Sub(v.ints.end.Big(), v.ints.start.Big())
+	s := v.ints.step.Big()
+
+	// Count along a positive step so that division rounds down
+	if s.Sign() < 0 {
+		d.Neg(d)
+		s.Neg(s)
+	}
+
+	n := new(big.Int)
+
+	if v.Inclusive {
+		n.Div(d, s).Add(n, big.NewInt(1))
+	} else {
+		n.Div(d.Add(d, s).Sub(d, big.NewInt(1)), s)
+	}
+
+	if n.Sign() < 0 {
+		return n.SetInt64(0)
+	}
+
+	return n
+}
+
+func (v *Range) At(i int) Value {
+	if v.ints != nil {
+		return v.ints.start.Add(NewInteger(int64(i)).Mul(v.ints.step))
+	}
+
+	return NewNumber(v.Start + float64(i)*v.Step)
+}
+
+// Contains reports whether `n` is one of the range's items
+func (v *Range) Contains(n Value) bool {
+	if v.ints != nil {
+		i, ok := n.(*Integer)
+
+		if f, isNum := n.(*Number); isNum {
+			i, ok = IntegerFromFloat(f.Value)
+		}
+
+		if !ok {
+			return false
+		}
+
+		d := new(big.Int).Sub(i.Big(), v.ints.start.Big())
+		k, m := new(big.Int).QuoRem(d, v.ints.step.Big(), new(big.Int))
+
+		return m.Sign() == 0 && k.Sign() >= 0 && k.Cmp(v.intCount()) < 0
+	}
+
+	f, ok := ToFloat(n)
+
+	if !ok {
+		return false
+	}
+
+	k := (f - v.Start) / v.Step
+
+	return k == math.Trunc(k) && k >= 0 && k < float64(v.Len())
+}
+
+func NewRange(start float64, end float64, step float64, inclusive bool) *Range {
+	return &Range{
+		Start:     start,
+		End:       end,
+		Step:      step,
+		Inclusive: inclusive,
+		proto:     ProtoRange,
+	}
+}
+
+// NewIntegerRange creates a range over exact integers. `step` must not be 0.
+func NewIntegerRange(start, end, step *Integer, inclusive bool) *Range {
+	r := NewRange(start.Float(), end.Float(), step.Float(), inclusive)
+	r.ints = &intBounds{start: start, end: end, step: step}
+
+	return r
+}
+
+// MaxRangeTuple caps the ranges whose items are put in memory at once, by
+// 'toTuple' or by spreading, since a range is cheap however long it is but
+// its items are not
+const MaxRangeTuple = 1 << 26
+
+var ProtoRange = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Range)(nil)
+var _ Sequence = (*Range)(nil)
@@ -0,0 +1,30 @@
+package value_test
+
+import (
+	"calabash/internal/value"
+	"math"
+	"testing"
+)
+
+func TestRangeLen(t *testing.T) {
+	table := []struct {
+		name string
+		r    *value.Range
+		want int
+	}{
+		{name: "exclusive", r: value.NewRange(0, 10, 3, false), want: 4},
+		{name: "inclusive", r: value.NewRange(0, 9, 3, true), want: 4},
+		{name: "against the step", r: value.NewRange(0, 10, -1, false), want: 0},
+		{name: "infinite bounds are capped", r: value.NewRange(0, math.Inf(1), 1, false), want: math.MaxInt},
+		{name: "spans too long for an int are capped", r: value.NewRange(-math.MaxFloat64, math.MaxFloat64, 1, true), want: math.MaxInt},
+		{name: "NaN bounds are empty", r: value.NewRange(math.NaN(), 1, 1, false), want: 0},
+		{name: "integers past 2^53", r: value.NewIntegerRange(value.NewInteger(1<<53+1), value.NewInteger(1<<53+4), value.NewInteger(1), false), want: 3},
+		{name: "integer spans too long for an int are capped", r: value.NewIntegerRange(value.NewInteger(math.MinInt64), value.NewInteger(math.MaxInt64), value.NewInteger(1), true), want: math.MaxInt},
+	}
+
+	for _, e := range table {
+		if got := e.r.Len(); got != e.want {
+			t.Errorf("%q: expected %d, got %d", e.name, e.want, got)
+		}
+	}
+}
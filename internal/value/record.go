@@ -3,13 +3,28 @@ package value
 import (
 	"calabash/internal/slice"
 	"fmt"
+	"sort"
 )
 
+// Record maps keys to values. Entries are kept in a persistent vector in the
+// order their keys were first added, and found through a persistent hash
+// trie of their keys, so records share most of their structure with the
+// records they were made from. Deleting a key leaves an empty entry behind
+// rather than moving the entries after it, and the entries are compacted once
+// empty ones outnumber the others.
 type Record struct {
-	Entries map[string]Value
-	keys    []Value
+	entries vector[entry]
+	keys    hamt // Positions in `entries` by key
+	dead    int  // Empty entries left by deleted keys
 	proto   *Proto
 	hash    string
+	code    uint64
+	coded   bool
+}
+
+type entry = struct {
+	K Value
+	V Value
 }
 
 func (v *Record) v() vtype {
@@ -18,8 +33,15 @@ func (v *Record) v() vtype {
 
 func (v *Record) Hash() string {
 	if v.hash == "" {
-		v.hash = fmt.Sprintf("rec:%s", slice.Fold(v.keys, "", func(k Value, acc string, _ int) string {
-			return acc + "," + k.Hash() + ":" + v.Entries[k.Hash()].Hash()
+		// Entries are hashed in a canonical order so that records built with
+		// different insertion orders are equal
+		es, _ := slice.Map(v.Entries(), func(e entry) (string, error) {
+			return e.K.Hash() + ":" + e.V.Hash(), nil
+		})
+		sort.Strings(es)
+
+		v.hash = fmt.Sprintf("rec:%s", slice.Fold(es, "", func(e string, acc string, _ int) string {
+			return acc + "," + e
 		}))
 	}
 
@@ -31,45 +53,201 @@ func (v *Record) Proto() *Proto {
 }
 
 func (v *Record) Inherit(p *Proto) Value {
-	es, _ := slice.Map(v.keys, func(k Value) (struct {
-		K Value
-		V Value
-	}, error) {
-		return struct {
-			K Value
-			V Value
-		}{K: k, V: v.Entries[k.Hash()]}, nil
-	})
-
-	r := NewRecord(es)
+	r := v.copy()
 	r.proto = p
 
 	return r
 }
 
+func (v *Record) String() string {
+	return Display(v)
+}
+
+func (v *Record) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Records are equal when they have equal values under equal keys, whatever
+// order the keys were added in
+func (v *Record) Equal(o Value) bool {
+	r, ok := o.(*Record)
+
+	if !ok || r.Len() != v.Len() {
+		return false
+	}
+
+	if r == v {
+		return true
+	}
+
+	if v.coded && r.coded && v.code != r.code {
+		return false
+	}
+
+	return v.entries.Each(func(i int, e entry) bool {
+		if e.K == nil {
+			return true
+		}
+
+		// Records built alike have their keys in the same order, which saves
+		// looking them up
+		if i < r.entries.Len() {
+			if o := r.entries.At(i); o.K != nil && o.K.Equal(e.K) {
+				return o.V.Equal(e.V)
+			}
+		}
+
+		x, ok := r.Get(e.K)
+
+		return ok && x.Equal(e.V)
+	})
+}
+
+func (v *Record) HashCode() uint64 {
+	if !v.coded {
+		h := seedRecord
+
+		// Entries are summed so that the order of the keys does not matter
+		v.entries.Each(func(_ int, e entry) bool {
+			if e.K == nil {
+				return true
+			}
+
+			h += scramble(mix(e.K.HashCode(), e.V.HashCode()))
+			return true
+		})
+
+		v.code, v.coded = h, true
+	}
+
+	return v.code
+}
+
+func (v *Record) Len() int {
+	return v.entries.Len() - v.dead
+}
+
+// Get returns the value stored under a key equal to `k`
+func (v *Record) Get(k Value) (Value, bool) {
+	if i, ok := v.keys.get(k, k.HashCode()); ok {
+		return v.entries.At(i).V, true
+	}
+
+	return nil, false
+}
+
+// Entries returns the entries of the record in the order their keys were
+// added
+func (v *Record) Entries() []struct {
+	K Value
+	V Value
+} {
+	es := v.entries.Slice()
+
+	if v.dead == 0 {
+		return es
+	}
+
+	live := es[:0]
+
+	for _, e := range es {
+		if e.K != nil {
+			live = append(live, e)
+		}
+	}
+
+	return live
+}
+
 func NewRecord(vs []struct {
 	K Value
 	V Value
 }) *Record {
-	keys, _ := slice.Map(vs, func(v struct {
-		K Value
-		V Value
-	}) (Value, error) {
-		return v.K, nil
-	})
+	b := &recordBuilder{entries: make([]entry, 0, len(vs))}
+
+	for _, e := range vs {
+		b.put(e.K, e.V)
+	}
+
+	return b.record()
+}
+
+// put sets the entry for `k`, keeping the key's position if it is already
+// present. The entries and keys are persistent, so records sharing them are
+// left as they were, but the record itself must not have been shared yet.
+func (v *Record) put(k Value, x Value) {
+	c := k.HashCode()
+
+	if i, ok := v.keys.get(k, c); ok {
+		v.entries = v.entries.Set(i, entry{K: v.entries.At(i).K, V: x})
+	} else {
+		v.keys = v.keys.set(k, c, v.entries.Len(), false)
+		v.entries = v.entries.Push(entry{K: k, V: x})
+	}
+
+	v.hash = ""
+	v.coded = false
+}
+
+// remove deletes the entry for `k`, if there is one. Its place in the entries
+// is left empty so that the positions of later entries stay as they are, and
+// the entries are rebuilt once most of them are empty, so deleting takes
+// amortised time proportional to the depth of the trie. Like `put`, it must
+// only be used on a record that has not been shared yet.
+func (v *Record) remove(k Value) {
+	c := k.HashCode()
+	i, ok := v.keys.get(k, c)
+
+	if !ok {
+		return
+	}
+
+	v.keys = v.keys.delete(k, c)
+	v.entries = v.entries.Set(i, entry{})
+	v.dead++
+	v.hash = ""
+	v.coded = false
+
+	if v.dead > v.Len() {
+		b := &recordBuilder{entries: make([]entry, 0, v.Len())}
+
+		for _, en := range v.Entries() {
+			b.put(en.K, en.V)
+		}
+
+		v.entries, v.keys, v.dead = vectorOf(b.entries), b.keys, 0
+	}
+}
+
+// recordBuilder collects the entries of a new record. Nothing shares its
+// trie until the record is built, so the trie is changed in place.
+type recordBuilder struct {
+	entries []entry
+	keys    hamt
+}
+
+func (b *recordBuilder) put(k Value, x Value) {
+	c := k.HashCode()
+
+	if i, ok := b.keys.get(k, c); ok {
+		b.entries[i].V = x
+		return
+	}
+
+	b.keys = b.keys.set(k, c, len(b.entries), true)
+	b.entries = append(b.entries, entry{K: k, V: x})
+}
 
+func (b *recordBuilder) record() *Record {
 	return &Record{
-		Entries: slice.Fold(keys, map[string]Value{}, func(v Value, acc map[string]Value, i int) map[string]Value {
-			acc[v.Hash()] = vs[i].V
-			return acc
-		}),
-		keys:  keys,
-		proto: ProtoRecord,
+		entries: vectorOf(b.entries),
+		keys:    b.keys,
+		proto:   ProtoRecord,
 	}
 }
 
 var ProtoRecord = &Proto{
-	Methods: map[string]Caller{},
+	Members: map[string]Value{},
 }
 
 // Compile time checks
@@ -0,0 +1,180 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"fmt"
+)
+
+func init() {
+	ProtoRecord.Define(NewString("get"), recordMethod(params("k"), func(r *Record, e Evaluator) (interface{}, error) {
+		k := arg(e, "k")
+		v, ok := r.Get(k)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("Record does not have key %q", k)}
+		}
+
+		return v, nil
+	}))
+
+	ProtoRecord.Define(NewString("has"), recordMethod(params("k"), func(r *Record, e Evaluator) (interface{}, error) {
+		_, ok := r.Get(arg(e, "k"))
+
+		return NewBoolean(ok), nil
+	}))
+
+	ProtoRecord.Define(NewString("getOr"), recordMethod(params("k", "d"), func(r *Record, e Evaluator) (interface{}, error) {
+		if v, ok := r.Get(arg(e, "k")); ok {
+			return v, nil
+		}
+
+		return arg(e, "d"), nil
+	}))
+
+	ProtoRecord.Define(NewString("set"), recordMethod(params("k", "v"), func(r *Record, e Evaluator) (interface{}, error) {
+		c := r.copy()
+		c.put(arg(e, "k"), arg(e, "v"))
+
+		return c, nil
+	}))
+
+	ProtoRecord.Define(NewString("delete"), recordMethod(params("k"), func(r *Record, e Evaluator) (interface{}, error) {
+		c := r.copy()
+		c.remove(arg(e, "k"))
+
+		return c, nil
+	}))
+
+	ProtoRecord.Define(NewString("keys"), recordMethod(nil, func(r *Record, _ Evaluator) (interface{}, error) {
+		vs := make([]Value, r.Len())
+
+		for i, en := range r.Entries() {
+			vs[i] = en.K
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoRecord.Define(NewString("values"), recordMethod(nil, func(r *Record, _ Evaluator) (interface{}, error) {
+		vs := make([]Value, r.Len())
+
+		for i, en := range r.Entries() {
+			vs[i] = en.V
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoRecord.Define(NewString("entries"), recordMethod(nil, func(r *Record, _ Evaluator) (interface{}, error) {
+		vs := make([]Value, r.Len())
+
+		for i, en := range r.Entries() {
+			vs[i] = NewTuple([]Value{en.K, en.V})
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoRecord.Define(NewString("merge"), recordMethod(params("...rs"), func(r *Record, e Evaluator) (interface{}, error) {
+		c := r.copy()
+
+		for _, o := range arg(e, "rs").(*Tuple).Items() {
+			or, ok := o.(*Record)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Can only merge records with other records"}
+			}
+
+			for _, en := range or.Entries() {
+				c.put(en.K, en.V)
+			}
+		}
+
+		return c, nil
+	}))
+
+	ProtoRecord.Define(NewString("map"), recordMethod(params("f"), func(r *Record, e Evaluator) (interface{}, error) {
+		b := &recordBuilder{}
+
+		for _, en := range r.Entries() {
+			v, err := e.Call(arg(e, "f"), []Value{en.V, en.K})
+
+			if err != nil {
+				return nil, err
+			}
+
+			b.put(en.K, v)
+		}
+
+		return b.record(), nil
+	}))
+
+	ProtoRecord.Define(NewString("filter"), recordMethod(params("f"), func(r *Record, e Evaluator) (interface{}, error) {
+		b := &recordBuilder{}
+
+		for _, en := range r.Entries() {
+			ok, err := test(e, arg(e, "f"), en.V, en.K)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				b.put(en.K, en.V)
+			}
+		}
+
+		return b.record(), nil
+	}))
+
+	ProtoRecord.Define(NewString("len"), recordMethod(nil, func(r *Record, _ Evaluator) (interface{}, error) {
+		return NewInteger(int64(r.Len())), nil
+	}))
+
+	ProtoRecord.Define(NewString("pick"), recordMethod(params("...ks"), func(r *Record, e Evaluator) (interface{}, error) {
+		ks := newValueSet(arg(e, "ks").(*Tuple).Items())
+
+		return r.keep(func(k Value, _ Value) bool {
+			return ks.has(k)
+		}), nil
+	}))
+
+	ProtoRecord.Define(NewString("omit"), recordMethod(params("...ks"), func(r *Record, e Evaluator) (interface{}, error) {
+		c := r.copy()
+
+		for _, k := range arg(e, "ks").(*Tuple).Items() {
+			c.remove(k)
+		}
+
+		return c, nil
+	}))
+}
+
+// copy returns a record with the same entries that can be changed with `put`
+func (v *Record) copy() *Record {
+	return &Record{
+		entries: v.entries,
+		keys:    v.keys,
+		dead:    v.dead,
+		proto:   ProtoRecord,
+	}
+}
+
+// keep returns a record with the entries for which `f` is true, in order
+func (v *Record) keep(f func(k Value, x Value) bool) *Record {
+	b := &recordBuilder{}
+
+	for _, en := range v.Entries() {
+		if f(en.K, en.V) {
+			b.put(en.K, en.V)
+		}
+	}
+
+	return b.record()
+}
+
+// recordMethod declares a built-in record method
+func recordMethod(ps []ast.Identifier, f func(r *Record, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("a record", ps, f)
+}
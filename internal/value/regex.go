@@ -0,0 +1,66 @@
+package value
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Regex is a compiled regular expression in RE2 syntax
+type Regex struct {
+	Value *regexp.Regexp
+	proto *Proto
+}
+
+func (v *Regex) v() vtype {
+	return value
+}
+
+func (v *Regex) Hash() string {
+	return "r:" + v.Value.String()
+}
+
+func (v *Regex) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Regex) Inherit(p *Proto) Value {
+	return &Regex{
+		Value: v.Value,
+		proto: p,
+	}
+}
+
+func (v *Regex) String() string {
+	return Display(v)
+}
+
+func (v *Regex) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Regexes are equal when they were compiled from the same pattern
+func (v *Regex) Equal(o Value) bool {
+	r, ok := o.(*Regex)
+
+	return ok && r.Value.String() == v.Value.String()
+}
+
+func (v *Regex) HashCode() uint64 {
+	return hashString(seedRegex, v.Value.String())
+}
+
+// NewRegex wraps a compiled regular expression, which is safe to share
+// between values
+func NewRegex(re *regexp.Regexp) *Regex {
+	return &Regex{
+		Value: re,
+		proto: ProtoRegex,
+	}
+}
+
+var ProtoRegex = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Regex)(nil)
@@ -0,0 +1,228 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ProtoRegexNamespace holds the members of the `regex` namespace
+var ProtoRegexNamespace = &Proto{
+	Members: map[string]Value{},
+}
+
+func init() {
+	Globals["regex"] = NewRecord(nil).Inherit(ProtoRegexNamespace)
+
+	ProtoRegexNamespace.Define(NewString("compile"), namespaceMethod(params("pattern"), func(e Evaluator) (interface{}, error) {
+		pattern, err := stringArg(e, "pattern")
+
+		if err != nil {
+			return nil, err
+		}
+
+		re, err := regexp.Compile(pattern)
+
+		if err != nil {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("Invalid regular expression %q: %s", pattern, err)}
+		}
+
+		return NewRegex(re), nil
+	}))
+
+	ProtoRegexNamespace.Define(NewString("escape"), namespaceMethod(params("s"), func(e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewString(regexp.QuoteMeta(s)), nil
+	}))
+
+	ProtoRegex.Define(NewString("pattern"), regexMethod(nil, func(r *Regex, _ Evaluator) (interface{}, error) {
+		return NewString(r.Value.String()), nil
+	}))
+
+	ProtoRegex.Define(NewString("test"), regexMethod(params("s"), func(r *Regex, e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBoolean(r.Value.MatchString(s)), nil
+	}))
+
+	ProtoRegex.Define(NewString("find"), regexMethod(params("s"), func(r *Regex, e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		loc := r.Value.FindStringIndex(s)
+
+		if loc == nil {
+			return &Bottom{}, nil
+		}
+
+		return NewString(s[loc[0]:loc[1]]), nil
+	}))
+
+	ProtoRegex.Define(NewString("findAll"), regexMethod(params("s", "...n"), func(r *Regex, e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := limit(e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return stringTuple(r.Value.FindAllString(s, n)), nil
+	}))
+
+	ProtoRegex.Define(NewString("captures"), regexMethod(params("s"), func(r *Regex, e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		loc := r.Value.FindStringSubmatchIndex(s)
+
+		if loc == nil {
+			return &Bottom{}, nil
+		}
+
+		return groups(s, loc), nil
+	}))
+
+	ProtoRegex.Define(NewString("namedCaptures"), regexMethod(params("s"), func(r *Regex, e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		loc := r.Value.FindStringSubmatchIndex(s)
+
+		if loc == nil {
+			return &Bottom{}, nil
+		}
+
+		return namedGroups(r.Value, s, loc), nil
+	}))
+
+	// The replacement is either a template, where `$1` or `${name}` stands
+	// for a group, or a callback given the match, its groups and its named
+	// groups
+	ProtoRegex.Define(NewString("replace"), regexMethod(params("s", "repl"), func(r *Regex, e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if tmpl, ok := arg(e, "repl").(*String); ok {
+			return NewString(r.Value.ReplaceAllString(s, tmpl.Value)), nil
+		}
+
+		var b strings.Builder
+		last := 0
+
+		for _, loc := range r.Value.FindAllStringSubmatchIndex(s, -1) {
+			v, err := e.Call(arg(e, "repl"), []Value{NewString(s[loc[0]:loc[1]]), groups(s, loc), namedGroups(r.Value, s, loc)})
+
+			if err != nil {
+				return nil, err
+			}
+
+			repl, ok := v.(*String)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Expect the replacement callback to return a string"}
+			}
+
+			b.WriteString(s[last:loc[0]])
+			b.WriteString(repl.Value)
+			last = loc[1]
+		}
+
+		b.WriteString(s[last:])
+
+		return NewString(b.String()), nil
+	}))
+
+	ProtoRegex.Define(NewString("split"), regexMethod(params("s", "...n"), func(r *Regex, e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := limit(e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return stringTuple(r.Value.Split(s, n)), nil
+	}))
+}
+
+// groups builds the tuple of the whole match and each group it captured from
+// the indices of a submatch; groups that did not take part are `bottom`
+func groups(s string, loc []int) *Tuple {
+	vs := make([]Value, len(loc)/2)
+
+	for i := range vs {
+		vs[i] = group(s, loc, i)
+	}
+
+	return NewTuple(vs)
+}
+
+// namedGroups builds the record of the named groups of a submatch
+func namedGroups(re *regexp.Regexp, s string, loc []int) *Record {
+	b := &recordBuilder{}
+
+	for i, name := range re.SubexpNames() {
+		if name != "" {
+			b.put(NewString(name), group(s, loc, i))
+		}
+	}
+
+	return b.record()
+}
+
+func group(s string, loc []int, i int) Value {
+	if loc[2*i] < 0 {
+		return &Bottom{}
+	}
+
+	return NewString(s[loc[2*i]:loc[2*i+1]])
+}
+
+// limit reads the optional "n" argument capping how many results a regex
+// method returns; all of them are returned without it
+func limit(e Evaluator) (int, error) {
+	rest := arg(e, "n").(*Tuple)
+
+	if rest.Len() == 0 {
+		return -1, nil
+	}
+
+	return count(rest.At(0))
+}
+
+// regexMethod declares a built-in regex method
+func regexMethod(ps []ast.Identifier, f func(r *Regex, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("a regex", ps, f)
+}
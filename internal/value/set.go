@@ -0,0 +1,181 @@
+package value
+
+import (
+	"calabash/internal/slice"
+	"fmt"
+	"sort"
+)
+
+// Set holds distinct values. Like the keys of a record, elements are kept in
+// a persistent vector in the order they were first added and found through a
+// persistent hash trie, so two elements are the same when they are `Equal`.
+type Set struct {
+	items vector[Value]
+	keys  hamt // Positions in `items` by element
+	proto *Proto
+	hash  string
+	code  uint64
+	coded bool
+}
+
+func (v *Set) v() vtype {
+	return value
+}
+
+func (v *Set) Hash() string {
+	if v.hash == "" {
+		// Elements are hashed in a canonical order so that sets built with
+		// different insertion orders are equal
+		hs, _ := slice.Map(v.Items(), func(x Value) (string, error) {
+			return x.Hash(), nil
+		})
+		sort.Strings(hs)
+
+		v.hash = fmt.Sprintf("set:%s", slice.Fold(hs, "", func(h string, acc string, _ int) string {
+			return acc + "," + h
+		}))
+	}
+
+	return v.hash
+}
+
+func (v *Set) Proto() *Proto {
+	return v.proto
+}
+
+func (v *Set) Inherit(p *Proto) Value {
+	return &Set{
+		items: v.items,
+		keys:  v.keys,
+		proto: p,
+	}
+}
+
+func (v *Set) String() string {
+	return Display(v)
+}
+
+func (v *Set) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+// Sets are equal when they have equal elements, whatever order the elements
+// were added in
+func (v *Set) Equal(o Value) bool {
+	s, ok := o.(*Set)
+
+	if !ok || s.Len() != v.Len() {
+		return false
+	}
+
+	if s == v {
+		return true
+	}
+
+	if v.coded && s.coded && v.code != s.code {
+		return false
+	}
+
+	return v.items.Each(func(_ int, x Value) bool {
+		return s.Has(x)
+	})
+}
+
+func (v *Set) HashCode() uint64 {
+	if !v.coded {
+		h := seedSet
+
+		// Elements are summed so that their order does not matter
+		v.items.Each(func(_ int, x Value) bool {
+			h += scramble(x.HashCode())
+			return true
+		})
+
+		v.code, v.coded = h, true
+	}
+
+	return v.code
+}
+
+func (v *Set) Len() int {
+	return v.items.Len()
+}
+
+// At returns the element added `i`th, so that sets can be spread in the
+// order their elements were added
+func (v *Set) At(i int) Value {
+	return v.items.At(i)
+}
+
+// Has tells whether the set holds an element equal to `x`
+func (v *Set) Has(x Value) bool {
+	_, ok := v.keys.get(x, x.HashCode())
+
+	return ok
+}
+
+// Items copies the elements of the set in the order they were added
+func (v *Set) Items() []Value {
+	return v.items.Slice()
+}
+
+// Add returns a set that also holds `x`, sharing the elements of `v`
+func (v *Set) Add(x Value) *Set {
+	c := x.HashCode()
+
+	if _, ok := v.keys.get(x, c); ok {
+		return &Set{items: v.items, keys: v.keys, proto: ProtoSet}
+	}
+
+	return &Set{
+		items: v.items.Push(x),
+		keys:  v.keys.set(x, c, v.items.Len(), false),
+		proto: ProtoSet,
+	}
+}
+
+// NewSet creates a set holding the distinct values of `vs`, keeping the
+// first of those that are equal
+func NewSet(vs []Value) *Set {
+	b := &setBuilder{items: make([]Value, 0, len(vs))}
+
+	for _, x := range vs {
+		b.add(x)
+	}
+
+	return b.set()
+}
+
+// setBuilder collects the elements of a new set, changing its trie in place
+// until the set is built
+type setBuilder struct {
+	items []Value
+	keys  hamt
+}
+
+func (b *setBuilder) add(x Value) {
+	c := x.HashCode()
+
+	if _, ok := b.keys.get(x, c); ok {
+		return
+	}
+
+	b.keys = b.keys.set(x, c, len(b.items), true)
+	b.items = append(b.items, x)
+}
+
+func (b *setBuilder) set() *Set {
+	return &Set{
+		items: vectorOf(b.items),
+		keys:  b.keys,
+		proto: ProtoSet,
+	}
+}
+
+var ProtoSet = &Proto{
+	Members: map[string]Value{},
+}
+
+// Compile time checks
+var _ Value = (*Set)(nil)
+var _ Sequence = (*Set)(nil)
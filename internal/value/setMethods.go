@@ -0,0 +1,138 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+)
+
+func init() {
+	ProtoSet.Define(NewString("add"), setMethod(params("x"), func(s *Set, e Evaluator) (interface{}, error) {
+		return s.Add(arg(e, "x")), nil
+	}))
+
+	ProtoSet.Define(NewString("remove"), setMethod(params("x"), func(s *Set, e Evaluator) (interface{}, error) {
+		x := arg(e, "x")
+
+		return s.keep(func(y Value) bool {
+			return !y.Equal(x)
+		}), nil
+	}))
+
+	ProtoSet.Define(NewString("has"), setMethod(params("x"), func(s *Set, e Evaluator) (interface{}, error) {
+		return NewBoolean(s.Has(arg(e, "x"))), nil
+	}))
+
+	ProtoSet.Define(NewString("len"), setMethod(nil, func(s *Set, _ Evaluator) (interface{}, error) {
+		return NewInteger(int64(s.Len())), nil
+	}))
+
+	ProtoSet.Define(NewString("toTuple"), setMethod(nil, func(s *Set, _ Evaluator) (interface{}, error) {
+		return &Tuple{items: s.items, proto: ProtoTuple}, nil
+	}))
+
+	ProtoSet.Define(NewString("union"), setMethod(params("...ss"), func(s *Set, e Evaluator) (interface{}, error) {
+		ss, err := sets(arg(e, "ss"), "union")
+
+		if err != nil {
+			return nil, err
+		}
+
+		c := s
+
+		for _, o := range ss {
+			o.items.Each(func(_ int, x Value) bool {
+				c = c.Add(x)
+				return true
+			})
+		}
+
+		return c, nil
+	}))
+
+	ProtoSet.Define(NewString("intersect"), setMethod(params("...ss"), func(s *Set, e Evaluator) (interface{}, error) {
+		ss, err := sets(arg(e, "ss"), "intersect")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return s.keep(func(x Value) bool {
+			for _, o := range ss {
+				if !o.Has(x) {
+					return false
+				}
+			}
+
+			return true
+		}), nil
+	}))
+
+	ProtoSet.Define(NewString("difference"), setMethod(params("...ss"), func(s *Set, e Evaluator) (interface{}, error) {
+		ss, err := sets(arg(e, "ss"), "difference")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return s.keep(func(x Value) bool {
+			for _, o := range ss {
+				if o.Has(x) {
+					return false
+				}
+			}
+
+			return true
+		}), nil
+	}))
+
+	ProtoSet.Define(NewString("isSubset"), setMethod(params("other"), func(s *Set, e Evaluator) (interface{}, error) {
+		o, ok := arg(e, "other").(*Set)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Can only compare sets with other sets"}
+		}
+
+		return NewBoolean(s.Len() <= o.Len() && s.items.Each(func(_ int, x Value) bool {
+			return o.Has(x)
+		})), nil
+	}))
+}
+
+// keep returns a set with the elements for which `f` is true, in order
+func (v *Set) keep(f func(x Value) bool) *Set {
+	b := &setBuilder{}
+
+	v.items.Each(func(_ int, x Value) bool {
+		if f(x) {
+			b.add(x)
+		}
+
+		return true
+	})
+
+	return b.set()
+}
+
+// sets checks that the rest argument `rest` of the method `name` only holds
+// sets
+func sets(rest Value, name string) ([]*Set, error) {
+	vs := rest.(*Tuple).Items()
+	ss := make([]*Set, len(vs))
+
+	for i, v := range vs {
+		s, ok := v.(*Set)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Expect the arguments of '" + name + "' to be sets"}
+		}
+
+		ss[i] = s
+	}
+
+	return ss, nil
+}
+
+// setMethod declares a built-in set method
+func setMethod(ps []ast.Identifier, f func(s *Set, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("a set", ps, f)
+}
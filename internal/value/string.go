@@ -26,6 +26,24 @@ func (v *String) Inherit(p *Proto) Value {
 	return s
 }
 
+func (v *String) String() string {
+	return Display(v)
+}
+
+func (v *String) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *String) Equal(o Value) bool {
+	s, ok := o.(*String)
+
+	return ok && s.Value == v.Value
+}
+
+func (v *String) HashCode() uint64 {
+	return hashString(seedString, v.Value)
+}
+
 func NewString(v string) *String {
 	return &String{
 		Value: v,
@@ -34,7 +52,7 @@ func NewString(v string) *String {
 }
 
 var ProtoString = &Proto{
-	Methods: map[string]Caller{},
+	Members: map[string]Value{},
 }
 
 // Compile time checks
@@ -0,0 +1,279 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+func init() {
+	ProtoString.Define(NewString("upper"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return NewString(strings.ToUpper(s.Value)), nil
+	}))
+
+	ProtoString.Define(NewString("lower"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return NewString(strings.ToLower(s.Value)), nil
+	}))
+
+	ProtoString.Define(NewString("len"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return NewInteger(int64(utf8.RuneCountInString(s.Value))), nil
+	}))
+
+	ProtoString.Define(NewString("split"), stringMethod(params("sep"), func(s *String, e Evaluator) (interface{}, error) {
+		sep, err := stringArg(e, "sep")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return stringTuple(strings.Split(s.Value, sep)), nil
+	}))
+
+	ProtoString.Define(NewString("trim"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return NewString(strings.TrimSpace(s.Value)), nil
+	}))
+
+	ProtoString.Define(NewString("trimStart"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return NewString(strings.TrimLeftFunc(s.Value, unicode.IsSpace)), nil
+	}))
+
+	ProtoString.Define(NewString("trimEnd"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return NewString(strings.TrimRightFunc(s.Value, unicode.IsSpace)), nil
+	}))
+
+	ProtoString.Define(NewString("replace"), stringMethod(params("old", "new"), func(s *String, e Evaluator) (interface{}, error) {
+		return replace(s, e, 1)
+	}))
+
+	ProtoString.Define(NewString("replaceAll"), stringMethod(params("old", "new"), func(s *String, e Evaluator) (interface{}, error) {
+		return replace(s, e, -1)
+	}))
+
+	ProtoString.Define(NewString("contains"), stringMethod(params("sub"), func(s *String, e Evaluator) (interface{}, error) {
+		sub, err := stringArg(e, "sub")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBoolean(strings.Contains(s.Value, sub)), nil
+	}))
+
+	ProtoString.Define(NewString("startsWith"), stringMethod(params("prefix"), func(s *String, e Evaluator) (interface{}, error) {
+		prefix, err := stringArg(e, "prefix")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBoolean(strings.HasPrefix(s.Value, prefix)), nil
+	}))
+
+	ProtoString.Define(NewString("endsWith"), stringMethod(params("suffix"), func(s *String, e Evaluator) (interface{}, error) {
+		suffix, err := stringArg(e, "suffix")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewBoolean(strings.HasSuffix(s.Value, suffix)), nil
+	}))
+
+	ProtoString.Define(NewString("indexOf"), stringMethod(params("sub"), func(s *String, e Evaluator) (interface{}, error) {
+		sub, err := stringArg(e, "sub")
+
+		if err != nil {
+			return nil, err
+		}
+
+		i := strings.Index(s.Value, sub)
+
+		if i < 0 {
+			return NewInteger(-1), nil
+		}
+
+		// Report the position in runes rather than bytes
+		return NewInteger(int64(utf8.RuneCountInString(s.Value[:i]))), nil
+	}))
+
+	ProtoString.Define(NewString("substring"), stringMethod(params("start", "...end"), func(s *String, e Evaluator) (interface{}, error) {
+		rs := []rune(s.Value)
+		start, err := index(arg(e, "start"), len(rs))
+
+		if err != nil {
+			return nil, err
+		}
+
+		end := len(rs)
+
+		if rest := arg(e, "end").(*Tuple); rest.Len() > 0 {
+			end, err = index(rest.At(0), len(rs))
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if end < start {
+			end = start
+		}
+
+		return NewString(string(rs[start:end])), nil
+	}))
+
+	ProtoString.Define(NewString("repeat"), stringMethod(params("n"), func(s *String, e Evaluator) (interface{}, error) {
+		n, err := count(arg(e, "n"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if n > 0 && len(s.Value) > maxStringLen/n {
+			return nil, errors.RuntimeError{Msg: "Repeated string would be too long"}
+		}
+
+		return NewString(strings.Repeat(s.Value, n)), nil
+	}))
+
+	ProtoString.Define(NewString("chars"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		return stringTuple(strings.Split(s.Value, "")), nil
+	}))
+
+	ProtoString.Define(NewString("padStart"), stringMethod(params("n", "...pad"), func(s *String, e Evaluator) (interface{}, error) {
+		p, err := padding(s, e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewString(p + s.Value), nil
+	}))
+
+	ProtoString.Define(NewString("padEnd"), stringMethod(params("n", "...pad"), func(s *String, e Evaluator) (interface{}, error) {
+		p, err := padding(s, e)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewString(s.Value + p), nil
+	}))
+
+	ProtoString.Define(NewString("toNumber"), stringMethod(nil, func(s *String, _ Evaluator) (interface{}, error) {
+		t := strings.TrimSpace(s.Value)
+
+		// Whole numbers written without a decimal point are integers, as
+		// they are in source code
+		if b, ok := new(big.Int).SetString(t, 10); ok {
+			return NewBigInteger(b), nil
+		}
+
+		n, err := strconv.ParseFloat(t, 64)
+
+		// Text that is not a finite decimal number, including "NaN" and
+		// "Inf", does not parse
+		if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+			return &Bottom{}, nil
+		}
+
+		return NewNumber(n), nil
+	}))
+}
+
+// stringMethod declares a built-in string method
+func stringMethod(ps []ast.Identifier, f func(s *String, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("a string", ps, f)
+}
+
+// stringArg reads the argument bound to `name`, which must be a string
+func stringArg(e Evaluator, name string) (string, error) {
+	s, ok := arg(e, name).(*String)
+
+	if !ok {
+		return "", errors.RuntimeError{Msg: "Expect '" + name + "' to be a string"}
+	}
+
+	return s.Value, nil
+}
+
+func stringTuple(ss []string) *Tuple {
+	vs := make([]Value, len(ss))
+
+	for i, s := range ss {
+		vs[i] = NewString(s)
+	}
+
+	return NewTuple(vs)
+}
+
+func replace(s *String, e Evaluator, n int) (Value, error) {
+	old, err := stringArg(e, "old")
+
+	if err != nil {
+		return nil, err
+	}
+
+	repl, err := stringArg(e, "new")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewString(strings.Replace(s.Value, old, repl, n)), nil
+}
+
+// count converts `v` to a non-negative integer
+func count(v Value) (int, error) {
+	n, ok := ToInt(v)
+
+	if !ok || n < 0 {
+		return 0, errors.RuntimeError{Msg: "Expect a count to be a non-negative integer"}
+	}
+
+	return n, nil
+}
+
+// maxStringLen caps the length in bytes of the strings built by methods such as
+// 'repeat' and 'padStart', which could otherwise be asked for more memory
+// than there is
+const maxStringLen = 1 << 26
+
+// padding builds the text needed to pad `s` to the length given by the "n"
+// argument, repeating the optional "pad" argument or a space
+func padding(s *String, e Evaluator) (string, error) {
+	n, err := count(arg(e, "n"))
+
+	if err != nil {
+		return "", err
+	}
+
+	pad := []rune(" ")
+
+	if rest := arg(e, "pad").(*Tuple); rest.Len() > 0 {
+		p, ok := rest.At(0).(*String)
+
+		if !ok || p.Value == "" {
+			return "", errors.RuntimeError{Msg: "Expect padding to be a non-empty string"}
+		}
+
+		pad = []rune(p.Value)
+	}
+
+	// Runes take at most 4 bytes, so this bounds the padded string in bytes
+	if n > maxStringLen/utf8.UTFMax {
+		return "", errors.RuntimeError{Msg: "Padded string would be too long"}
+	}
+
+	rs := []rune{}
+
+	for i := 0; i < n-utf8.RuneCountInString(s.Value); i++ {
+		rs = append(rs, pad[i%len(pad)])
+	}
+
+	return string(rs), nil
+}
@@ -0,0 +1,416 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ProtoTime holds the members of the `time` namespace
+var ProtoTime = &Proto{
+	Members: map[string]Value{},
+}
+
+func init() {
+	Globals["time"] = NewRecord(nil).Inherit(ProtoTime)
+
+	// Layouts are written the way Go writes them: as the reference time,
+	// Mon Jan 2 15:04:05 MST 2006, would be
+	for _, l := range []struct {
+		k      string
+		layout string
+	}{
+		{"rfc3339", time.RFC3339Nano},
+		{"rfc1123", time.RFC1123Z},
+		{"dateTime", "2006-01-02 15:04:05"},
+		{"dateOnly", "2006-01-02"},
+		{"timeOnly", "15:04:05"},
+	} {
+		ProtoTime.Define(NewString(l.k), NewString(l.layout))
+	}
+
+	ProtoTime.Define(NewString("now"), namespaceMethod(nil, func(e Evaluator) (interface{}, error) {
+		return NewInstant(e.Now()), nil
+	}))
+
+	ProtoTime.Define(NewString("instant"), namespaceMethod(params("s"), func(e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		t, err := time.Parse(time.RFC3339Nano, s)
+
+		if err != nil {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("%q is not an RFC 3339 time", s)}
+		}
+
+		return NewInstant(t), nil
+	}))
+
+	ProtoTime.Define(NewString("parse"), namespaceMethod(params("s", "layout", "...zone"), func(e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		layout, err := stringArg(e, "layout")
+
+		if err != nil {
+			return nil, err
+		}
+
+		// Times without an offset are read in UTC unless a zone is given
+		loc := time.UTC
+
+		if rest := arg(e, "zone").(*Tuple); rest.Len() > 0 {
+			loc, err = location(rest.At(0))
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		t, err := time.ParseInLocation(layout, s, loc)
+
+		if err != nil {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("Cannot parse %q with the layout %q", s, layout)}
+		}
+
+		return NewInstant(t), nil
+	}))
+
+	ProtoTime.Define(NewString("fromUnix"), namespaceMethod(params("n"), func(e Evaluator) (interface{}, error) {
+		if n, ok := ToInt(arg(e, "n")); ok {
+			return NewInstant(time.Unix(int64(n), 0).UTC()), nil
+		}
+
+		n, err := numberArg(e, "n")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, errors.RuntimeError{Msg: "Expect a Unix time to be finite"}
+		}
+
+		s, frac := math.Modf(n)
+
+		return NewInstant(time.Unix(int64(s), int64(frac*1e9)).UTC()), nil
+	}))
+
+	ProtoTime.Define(NewString("duration"), namespaceMethod(params("s"), func(e Evaluator) (interface{}, error) {
+		s, err := stringArg(e, "s")
+
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := time.ParseDuration(s)
+
+		if err != nil {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("%q is not a duration", s)}
+		}
+
+		return NewDuration(d), nil
+	}))
+
+	for _, u := range []struct {
+		k    string
+		unit time.Duration
+	}{
+		{"nanoseconds", time.Nanosecond},
+		{"milliseconds", time.Millisecond},
+		{"seconds", time.Second},
+		{"minutes", time.Minute},
+		{"hours", time.Hour},
+	} {
+		unit := u.unit
+
+		ProtoTime.Define(NewString(u.k), namespaceMethod(params("n"), func(e Evaluator) (interface{}, error) {
+			n, err := numberArg(e, "n")
+
+			if err != nil {
+				return nil, err
+			}
+
+			return scaleDuration(unit, n)
+		}))
+	}
+
+	ProtoInstant.Define(NewString("format"), instantMethod(params("layout"), func(t *Instant, e Evaluator) (interface{}, error) {
+		layout, err := stringArg(e, "layout")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewString(t.Value.Format(layout)), nil
+	}))
+
+	ProtoInstant.Define(NewString("in"), instantMethod(params("zone"), func(t *Instant, e Evaluator) (interface{}, error) {
+		loc, err := location(arg(e, "zone"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewInstant(t.Value.In(loc)), nil
+	}))
+
+	ProtoInstant.Define(NewString("utc"), instantMethod(nil, func(t *Instant, _ Evaluator) (interface{}, error) {
+		return NewInstant(t.Value.UTC()), nil
+	}))
+
+	ProtoInstant.Define(NewString("zone"), instantMethod(nil, func(t *Instant, _ Evaluator) (interface{}, error) {
+		return NewString(t.Value.Location().String()), nil
+	}))
+
+	ProtoInstant.Define(NewString("unix"), instantMethod(nil, func(t *Instant, _ Evaluator) (interface{}, error) {
+		return NewInteger(t.Value.Unix()), nil
+	}))
+
+	ProtoInstant.Define(NewString("unixMilli"), instantMethod(nil, func(t *Instant, _ Evaluator) (interface{}, error) {
+		return NewInteger(t.Value.UnixMilli()), nil
+	}))
+
+	for _, f := range []struct {
+		k   string
+		get func(t time.Time) int
+	}{
+		{"year", time.Time.Year},
+		{"month", func(t time.Time) int { return int(t.Month()) }},
+		{"day", time.Time.Day},
+		{"hour", time.Time.Hour},
+		{"minute", time.Time.Minute},
+		{"second", time.Time.Second},
+		{"nanosecond", time.Time.Nanosecond},
+	} {
+		get := f.get
+
+		ProtoInstant.Define(NewString(f.k), instantMethod(nil, func(t *Instant, _ Evaluator) (interface{}, error) {
+			return NewInteger(int64(get(t.Value))), nil
+		}))
+	}
+
+	ProtoInstant.Define(NewString("weekday"), instantMethod(nil, func(t *Instant, _ Evaluator) (interface{}, error) {
+		return NewString(t.Value.Weekday().String()), nil
+	}))
+
+	ProtoInstant.Define(NewString("+"), instantMethod(params("d"), func(t *Instant, e Evaluator) (interface{}, error) {
+		d, ok := arg(e, "d").(*Duration)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Can only add durations to instants"}
+		}
+
+		return NewInstant(t.Value.Add(d.Value)), nil
+	}))
+
+	ProtoInstant.Define(NewString("-"), instantMethod(params("o"), func(t *Instant, e Evaluator) (interface{}, error) {
+		switch o := arg(e, "o").(type) {
+		case *Duration:
+			if o.Value == math.MinInt64 {
+				return nil, errors.RuntimeError{Msg: "Duration overflow"}
+			}
+
+			return NewInstant(t.Value.Add(-o.Value)), nil
+
+		case *Instant:
+			d := t.Value.Sub(o.Value)
+
+			// Sub saturates rather than overflowing
+			if !o.Value.Add(d).Equal(t.Value) {
+				return nil, errors.RuntimeError{Msg: "The time between the instants is too long for a duration"}
+			}
+
+			return NewDuration(d), nil
+		}
+
+		return nil, errors.RuntimeError{Msg: "Can only subtract durations or instants from instants"}
+	}))
+
+	comparisons(ProtoInstant, "an instant", func(a, b *Instant) int {
+		switch {
+		case a.Value.Before(b.Value):
+			return -1
+
+		case a.Value.After(b.Value):
+			return 1
+		}
+
+		return 0
+	})
+
+	ProtoDuration.Define(NewString("nanoseconds"), durationMethod(nil, func(d *Duration, _ Evaluator) (interface{}, error) {
+		return NewInteger(d.Value.Nanoseconds()), nil
+	}))
+
+	for _, u := range []struct {
+		k    string
+		unit time.Duration
+	}{
+		{"milliseconds", time.Millisecond},
+		{"seconds", time.Second},
+		{"minutes", time.Minute},
+		{"hours", time.Hour},
+	} {
+		unit := u.unit
+
+		ProtoDuration.Define(NewString(u.k), durationMethod(nil, func(d *Duration, _ Evaluator) (interface{}, error) {
+			return NewNumber(float64(d.Value) / float64(unit)), nil
+		}))
+	}
+
+	ProtoDuration.Define(NewString("abs"), durationMethod(nil, func(d *Duration, _ Evaluator) (interface{}, error) {
+		if d.Value == math.MinInt64 {
+			return nil, errors.RuntimeError{Msg: "Duration overflow"}
+		}
+
+		if d.Value < 0 {
+			return NewDuration(-d.Value), nil
+		}
+
+		return d, nil
+	}))
+
+	ProtoDuration.Define(NewString("scale"), durationMethod(params("n"), func(d *Duration, e Evaluator) (interface{}, error) {
+		n, err := numberArg(e, "n")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return scaleDuration(d.Value, n)
+	}))
+
+	ProtoDuration.Define(NewString("+"), durationMethod(params("o"), func(d *Duration, e Evaluator) (interface{}, error) {
+		switch o := arg(e, "o").(type) {
+		case *Duration:
+			return addDurations(d.Value, o.Value)
+
+		case *Instant:
+			return NewInstant(o.Value.Add(d.Value)), nil
+		}
+
+		return nil, errors.RuntimeError{Msg: "Can only add durations or instants to durations; convert numbers with time->'seconds' and the like"}
+	}))
+
+	ProtoDuration.Define(NewString("-"), durationMethod(params("o"), func(d *Duration, e Evaluator) (interface{}, error) {
+		o, ok := arg(e, "o").(*Duration)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Can only subtract durations from durations; convert numbers with time->'seconds' and the like"}
+		}
+
+		if o.Value == math.MinInt64 {
+			return nil, errors.RuntimeError{Msg: "Duration overflow"}
+		}
+
+		return addDurations(d.Value, -o.Value)
+	}))
+
+	// Dividing durations gives their ratio, a plain number
+	ProtoDuration.Define(NewString("/"), durationMethod(params("o"), func(d *Duration, e Evaluator) (interface{}, error) {
+		o, ok := arg(e, "o").(*Duration)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Can only divide durations by durations; use 'scale' to divide by a number"}
+		}
+
+		return NewNumber(float64(d.Value) / float64(o.Value)), nil
+	}))
+
+	comparisons(ProtoDuration, "a duration", func(a, b *Duration) int {
+		switch {
+		case a.Value < b.Value:
+			return -1
+
+		case a.Value > b.Value:
+			return 1
+		}
+
+		return 0
+	})
+}
+
+// comparisons defines the ordering operator methods of a kind of value that
+// can only be compared with values of the same kind
+func comparisons[T Value](p *Proto, kind string, cmp func(a, b T) int) {
+	for _, c := range []struct {
+		op   string
+		test func(c int) bool
+	}{
+		{"<", func(c int) bool { return c < 0 }},
+		{"<=", func(c int) bool { return c <= 0 }},
+		{">", func(c int) bool { return c > 0 }},
+		{">=", func(c int) bool { return c >= 0 }},
+	} {
+		op, test := c.op, c.test
+
+		p.Define(NewString(op), method(kind, params("o"), func(me T, e Evaluator) (interface{}, error) {
+			o, ok := arg(e, "o").(T)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: fmt.Sprintf("Can only compare %s with %s using %q", kind, kind, op)}
+			}
+
+			return NewBoolean(test(cmp(me, o))), nil
+		}))
+	}
+}
+
+// location finds the time zone named by `v`, such as "Europe/Paris", "UTC"
+// or "Local"
+func location(v Value) (*time.Location, error) {
+	s, ok := v.(*String)
+
+	if !ok {
+		return nil, errors.RuntimeError{Msg: "Expect a time zone to be a string"}
+	}
+
+	loc, err := time.LoadLocation(s.Value)
+
+	if err != nil {
+		return nil, errors.RuntimeError{Msg: fmt.Sprintf("Unknown time zone %q", s.Value)}
+	}
+
+	return loc, nil
+}
+
+// scaleDuration multiplies `d` by `n`, rounding to the nearest nanosecond
+func scaleDuration(d time.Duration, n float64) (*Duration, error) {
+	x := math.Round(float64(d) * n)
+
+	if math.IsNaN(x) || x < math.MinInt64 || x >= math.MaxInt64 {
+		return nil, errors.RuntimeError{Msg: "Duration overflow"}
+	}
+
+	return NewDuration(time.Duration(x)), nil
+}
+
+func addDurations(a, b time.Duration) (*Duration, error) {
+	s := a + b
+
+	// The sum overflowed when both operands have a sign the sum does not
+	if (a > 0 && b > 0 && s < 0) || (a < 0 && b < 0 && s >= 0) {
+		return nil, errors.RuntimeError{Msg: "Duration overflow"}
+	}
+
+	return NewDuration(s), nil
+}
+
+// instantMethod declares a built-in instant method
+func instantMethod(ps []ast.Identifier, f func(t *Instant, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("an instant", ps, f)
+}
+
+// durationMethod declares a built-in duration method
+func durationMethod(ps []ast.Identifier, f func(d *Duration, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("a duration", ps, f)
+}
@@ -5,10 +5,14 @@ import (
 	"fmt"
 )
 
+// Tuple is an immutable sequence backed by a persistent vector, so tuples
+// made from one another share most of their items
 type Tuple struct {
-	Items []Value
+	items vector[Value]
 	proto *Proto
 	hash  string
+	code  uint64
+	coded bool
 }
 
 func (v *Tuple) v() vtype {
@@ -17,7 +21,7 @@ func (v *Tuple) v() vtype {
 
 func (v *Tuple) Hash() string {
 	if v.hash == "" {
-		v.hash = fmt.Sprintf("tpl:%s", slice.Fold(v.Items, "", func(i Value, acc string, _ int) string {
+		v.hash = fmt.Sprintf("tpl:%s", slice.Fold(v.Items(), "", func(i Value, acc string, _ int) string {
 			return acc + "," + i.Hash()
 		}))
 	}
@@ -30,22 +34,89 @@ func (v *Tuple) Proto() *Proto {
 }
 
 func (v *Tuple) Inherit(p *Proto) Value {
-	t := NewTuple(v.Items)
-	t.proto = p
+	return &Tuple{
+		items: v.items,
+		proto: p,
+	}
+}
+
+func (v *Tuple) String() string {
+	return Display(v)
+}
+
+func (v *Tuple) Format(f fmt.State, verb rune) {
+	format(f, verb, v)
+}
+
+func (v *Tuple) Equal(o Value) bool {
+	t, ok := o.(*Tuple)
+
+	if !ok || t.Len() != v.Len() {
+		return false
+	}
+
+	if t == v {
+		return true
+	}
+
+	// Hash codes already worked out settle most inequalities cheaply
+	if v.coded && t.coded && v.code != t.code {
+		return false
+	}
+
+	return v.items.Each(func(i int, item Value) bool {
+		return item.Equal(t.At(i))
+	})
+}
 
-	return t
+func (v *Tuple) HashCode() uint64 {
+	if !v.coded {
+		h := seedTuple
+
+		v.items.Each(func(_ int, item Value) bool {
+			h = mix(h, item.HashCode())
+			return true
+		})
+
+		v.code, v.coded = h, true
+	}
+
+	return v.code
+}
+
+func (v *Tuple) Len() int {
+	return v.items.Len()
+}
+
+func (v *Tuple) At(i int) Value {
+	return v.items.At(i)
+}
+
+// Items copies the items of the tuple in order
+func (v *Tuple) Items() []Value {
+	return v.items.Slice()
+}
+
+// Push returns a tuple with `x` added at the end, sharing the items of `v`
+func (v *Tuple) Push(x Value) *Tuple {
+	return &Tuple{
+		items: v.items.Push(x),
+		proto: ProtoTuple,
+	}
 }
 
+// NewTuple creates a tuple holding a copy of `vs`
 func NewTuple(vs []Value) *Tuple {
 	return &Tuple{
-		Items: vs,
+		items: vectorOf(vs),
 		proto: ProtoTuple,
 	}
 }
 
 var ProtoTuple = &Proto{
-	Methods: map[string]Caller{},
+	Members: map[string]Value{},
 }
 
 // Compile time checks
 var _ Value = (*Tuple)(nil)
+var _ Sequence = (*Tuple)(nil)
@@ -0,0 +1,370 @@
+package value
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func init() {
+	ProtoTuple.Define(NewString("push"), tupleMethod(params("e"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		return tpl.Push(arg(e, "e")), nil
+	}))
+
+	ProtoTuple.Define(NewString("len"), tupleMethod(nil, func(tpl *Tuple, _ Evaluator) (interface{}, error) {
+		return NewInteger(int64(tpl.Len())), nil
+	}))
+
+	ProtoTuple.Define(NewString("map"), tupleMethod(params("f"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		vs := make([]Value, tpl.Len())
+
+		for i, v := range tpl.Items() {
+			r, err := e.Call(arg(e, "f"), []Value{v})
+
+			if err != nil {
+				return nil, err
+			}
+
+			vs[i] = r
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoTuple.Define(NewString("filter"), tupleMethod(params("f"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		vs := []Value{}
+
+		for _, v := range tpl.Items() {
+			ok, err := test(e, arg(e, "f"), v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				vs = append(vs, v)
+			}
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoTuple.Define(NewString("fold"), tupleMethod(params("init", "f"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		return fold(e, arg(e, "f"), arg(e, "init"), tpl.Items())
+	}))
+
+	ProtoTuple.Define(NewString("reduce"), tupleMethod(params("f"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		if tpl.Len() == 0 {
+			return nil, errors.RuntimeError{Msg: "Cannot reduce an empty tuple"}
+		}
+
+		vs := tpl.Items()
+
+		return fold(e, arg(e, "f"), vs[0], vs[1:])
+	}))
+
+	ProtoTuple.Define(NewString("find"), tupleMethod(params("f"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		for _, v := range tpl.Items() {
+			ok, err := test(e, arg(e, "f"), v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				return v, nil
+			}
+		}
+
+		return &Bottom{}, nil
+	}))
+
+	ProtoTuple.Define(NewString("any"), tupleMethod(params("f"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		for _, v := range tpl.Items() {
+			ok, err := test(e, arg(e, "f"), v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if ok {
+				return NewBoolean(true), nil
+			}
+		}
+
+		return NewBoolean(false), nil
+	}))
+
+	ProtoTuple.Define(NewString("all"), tupleMethod(params("f"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		for _, v := range tpl.Items() {
+			ok, err := test(e, arg(e, "f"), v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				return NewBoolean(false), nil
+			}
+		}
+
+		return NewBoolean(true), nil
+	}))
+
+	ProtoTuple.Define(NewString("contains"), tupleMethod(params("v"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		return NewBoolean(tpl.indexOf(arg(e, "v")) >= 0), nil
+	}))
+
+	ProtoTuple.Define(NewString("indexOf"), tupleMethod(params("v"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		return NewInteger(int64(tpl.indexOf(arg(e, "v")))), nil
+	}))
+
+	ProtoTuple.Define(NewString("slice"), tupleMethod(params("start", "...end"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		start, err := index(arg(e, "start"), tpl.Len())
+
+		if err != nil {
+			return nil, err
+		}
+
+		end := tpl.Len()
+
+		if rest := arg(e, "end").(*Tuple); rest.Len() > 0 {
+			end, err = index(rest.At(0), tpl.Len())
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if end < start {
+			end = start
+		}
+
+		return NewTuple(tpl.Items()[start:end]), nil
+	}))
+
+	ProtoTuple.Define(NewString("concat"), tupleMethod(params("...ts"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		// Pushing shares the items of the receiver instead of copying them
+		c := &Tuple{items: tpl.items, proto: ProtoTuple}
+
+		for _, t := range arg(e, "ts").(*Tuple).Items() {
+			seq, ok := t.(Sequence)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Can only concatenate tuples with sequences"}
+			}
+
+			if r, ok := seq.(*Range); ok && r.Len() > MaxRangeTuple {
+				return nil, errors.RuntimeError{Msg: "Range is too long to concatenate"}
+			}
+
+			for i := 0; i < seq.Len(); i++ {
+				c = c.Push(seq.At(i))
+			}
+		}
+
+		return c, nil
+	}))
+
+	ProtoTuple.Define(NewString("reverse"), tupleMethod(nil, func(tpl *Tuple, _ Evaluator) (interface{}, error) {
+		vs := make([]Value, tpl.Len())
+
+		for i, v := range tpl.Items() {
+			vs[len(vs)-1-i] = v
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoTuple.Define(NewString("sort"), tupleMethod(params("...less"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		vs := tpl.Items()
+		less := defaultLess
+
+		if rest := arg(e, "less").(*Tuple); rest.Len() > 0 {
+			f := rest.At(0)
+
+			less = func(a, b Value) (bool, error) {
+				return test(e, f, a, b)
+			}
+		}
+
+		var err error
+
+		sort.SliceStable(vs, func(i, j int) bool {
+			if err != nil {
+				return false
+			}
+
+			var ok bool
+			ok, err = less(vs[i], vs[j])
+
+			return ok
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoTuple.Define(NewString("zip"), tupleMethod(params("other"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		seq, ok := arg(e, "other").(Sequence)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Can only zip tuples with sequences"}
+		}
+
+		n := tpl.Len()
+
+		if seq.Len() < n {
+			n = seq.Len()
+		}
+
+		vs := make([]Value, n)
+
+		for i := range vs {
+			vs[i] = NewTuple([]Value{tpl.At(i), seq.At(i)})
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoTuple.Define(NewString("flatten"), tupleMethod(nil, func(tpl *Tuple, _ Evaluator) (interface{}, error) {
+		vs := []Value{}
+
+		for _, v := range tpl.Items() {
+			if t, ok := v.(*Tuple); ok {
+				vs = append(vs, t.Items()...)
+				continue
+			}
+
+			vs = append(vs, v)
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoTuple.Define(NewString("uniq"), tupleMethod(nil, func(tpl *Tuple, _ Evaluator) (interface{}, error) {
+		seen := valueSet{}
+		vs := []Value{}
+
+		for _, v := range tpl.Items() {
+			if seen.add(v) {
+				vs = append(vs, v)
+			}
+		}
+
+		return NewTuple(vs), nil
+	}))
+
+	ProtoTuple.Define(NewString("first"), tupleMethod(nil, func(tpl *Tuple, _ Evaluator) (interface{}, error) {
+		if tpl.Len() == 0 {
+			return &Bottom{}, nil
+		}
+
+		return tpl.At(0), nil
+	}))
+
+	ProtoTuple.Define(NewString("last"), tupleMethod(nil, func(tpl *Tuple, _ Evaluator) (interface{}, error) {
+		if tpl.Len() == 0 {
+			return &Bottom{}, nil
+		}
+
+		return tpl.At(tpl.Len() - 1), nil
+	}))
+
+	ProtoTuple.Define(NewString("join"), tupleMethod(params("sep"), func(tpl *Tuple, e Evaluator) (interface{}, error) {
+		sep, ok := arg(e, "sep").(*String)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Expect the separator to be a string"}
+		}
+
+		ss := make([]string, tpl.Len())
+
+		for i, v := range tpl.Items() {
+			s, err := stringify(e, v)
+
+			if err != nil {
+				return nil, err
+			}
+
+			ss[i] = s
+		}
+
+		return NewString(strings.Join(ss, sep.Value)), nil
+	}))
+}
+
+func (v *Tuple) indexOf(x Value) int {
+	for i, item := range v.Items() {
+		if item.Equal(x) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// tupleMethod declares a built-in tuple method
+func tupleMethod(ps []ast.Identifier, f func(tpl *Tuple, e Evaluator) (interface{}, error)) *ProtoMethod {
+	return method("a tuple", ps, f)
+}
+
+func fold(e Evaluator, f Value, acc Value, vs []Value) (Value, error) {
+	for _, v := range vs {
+		r, err := e.Call(f, []Value{acc, v})
+
+		if err != nil {
+			return nil, err
+		}
+
+		acc = r
+	}
+
+	return acc, nil
+}
+
+// index converts `v` to a position in a sequence of length `n`, counting
+// from the end when negative and clamping to the sequence's bounds
+func index(v Value, n int) (int, error) {
+	i, ok := ToInt(v)
+
+	if !ok {
+		return 0, errors.RuntimeError{Msg: "Expect an index to be an integer"}
+	}
+
+	if i < 0 {
+		i += n
+	}
+
+	if i < 0 {
+		return 0, nil
+	}
+
+	if i > n {
+		return n, nil
+	}
+
+	return i, nil
+}
+
+// defaultLess orders numeric values and strings, which are the kinds that
+// can be sorted without a comparator
+func defaultLess(a, b Value) (bool, error) {
+	if c, ok := CompareNumbers(a, b); ok {
+		return c < 0, nil
+	}
+
+	switch av := a.(type) {
+	case *String:
+		if bv, ok := b.(*String); ok {
+			return av.Value < bv.Value, nil
+		}
+	}
+
+	return false, errors.RuntimeError{Msg: fmt.Sprintf("Cannot sort %s and %s values without a comparator", Kind(a), Kind(b))}
+}
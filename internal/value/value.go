@@ -3,6 +3,8 @@ package value
 import (
 	"calabash/ast"
 	"calabash/internal/environment"
+	"fmt"
+	"time"
 )
 
 type vtype int
@@ -17,13 +19,36 @@ type Evaluator interface {
 	PushEnv(*environment.Environment[Value])
 	PopEnv()
 	AddEnv(k string, v Value)
+	PushDefers()
+	RunDefers(error) error
+	// Call invokes a callable value on behalf of a built-in method whose
+	// closure exposes the caller's scope
+	Call(fn Value, args []Value) (Value, error)
+	// Now reads the clock the host gave the evaluator
+	Now() time.Time
+	// Generate makes an iterator that runs `body` in the current scope,
+	// pausing at each value it yields
+	Generate(body ast.Block) *Iterator
 }
 
 type Value interface {
 	v() vtype
+	// Hash names the value as a proto member key
 	Hash() string
+	// Equal tells whether the value is structurally equal to another. Protos
+	// a value inherits are not compared. Numbers compare by value whatever
+	// their kind, so `1` equals `1.0` and `0` equals `-0`, but a NaN is only
+	// equal to itself, the very same value. Functions are only equal to
+	// themselves and protos are equal when they have equal members and
+	// parents.
+	Equal(Value) bool
+	// HashCode is the same for values that are equal
+	HashCode() uint64
 	Proto() *Proto
 	Inherit(*Proto) Value
+	// Values print in the format of `Display`
+	fmt.Stringer
+	fmt.Formatter
 }
 
 type Caller interface {
@@ -36,3 +61,75 @@ type Caller interface {
 	Rest() bool
 	Closure(*environment.Environment[Value]) *environment.Environment[Value]
 }
+
+// Sequence is implemented by values whose contents can be iterated in order
+// (e.g. spread into tuple literals and argument lists)
+type Sequence interface {
+	Value
+	Len() int
+	At(int) Value
+}
+
+// Kind names the kind of value `v` is, regardless of the proto it inherits
+func Kind(v Value) string {
+	switch v.(type) {
+	case *Number:
+		return "number"
+
+	case *Integer:
+		return "integer"
+
+	case *String:
+		return "string"
+
+	case *Bytes:
+		return "bytes"
+
+	case *Boolean:
+		return "boolean"
+
+	case *Bottom:
+		return "bottom"
+
+	case *Tuple:
+		return "tuple"
+
+	case *Record:
+		return "record"
+
+	case *Set:
+		return "set"
+
+	case *Range:
+		return "range"
+
+	case *Instant:
+		return "instant"
+
+	case *Duration:
+		return "duration"
+
+	case *Regex:
+		return "regex"
+
+	case *Iterator:
+		return "iterator"
+
+	case *Done:
+		return "done"
+
+	case *Error:
+		return "error"
+
+	case *Function, *ProtoMethod:
+		return "function"
+
+	case *Proto:
+		return "proto"
+
+	case *Protocol:
+		return "protocol"
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,222 @@
+package value
+
+// vector is a persistent sequence: a trie of nodes with up to 32 children
+// each, whose leaves hold the items, plus a tail holding the last items.
+// Updates copy the path to the item they change and share everything else
+// with the original, so they take time proportional to the trie's depth.
+type vector[T any] struct {
+	size  int
+	shift uint // Bits of an index used above the leaves
+	root  *vnode[T]
+	tail  []T
+}
+
+type vnode[T any] struct {
+	children []*vnode[T]
+	items    []T // Only set on leaves
+}
+
+const (
+	vbits  = 5
+	vwidth = 1 << vbits
+	vmask  = vwidth - 1
+)
+
+// vectorOf builds a vector holding a copy of `xs`
+func vectorOf[T any](xs []T) vector[T] {
+	v := vector[T]{}
+
+	for len(xs) > 0 {
+		n := vwidth
+
+		if len(xs) < n {
+			n = len(xs)
+		}
+
+		if len(v.tail) == vwidth {
+			v = v.flushTail()
+		}
+
+		v.tail = append([]T(nil), xs[:n]...)
+		v.size += n
+		xs = xs[n:]
+	}
+
+	return v
+}
+
+func (v vector[T]) Len() int {
+	return v.size
+}
+
+// tailOffset is the index of the first item in the tail
+func (v vector[T]) tailOffset() int {
+	if v.size < vwidth {
+		return 0
+	}
+
+	return ((v.size - 1) >> vbits) << vbits
+}
+
+func (v vector[T]) At(i int) T {
+	if i >= v.tailOffset() {
+		return v.tail[i-v.tailOffset()]
+	}
+
+	n := v.root
+
+	for level := v.shift; level > 0; level -= vbits {
+		n = n.children[(i>>level)&vmask]
+	}
+
+	return n.items[i&vmask]
+}
+
+// Push returns a vector with `x` added at the end
+func (v vector[T]) Push(x T) vector[T] {
+	if len(v.tail) == vwidth {
+		v = v.flushTail()
+	}
+
+	// The tail is copied so that vectors sharing it keep their own items
+	v.tail = append(v.tail[:len(v.tail):len(v.tail)], x)
+	v.size++
+
+	return v
+}
+
+// Set returns a vector with `x` at the index `i`, which must be in range
+func (v vector[T]) Set(i int, x T) vector[T] {
+	if off := v.tailOffset(); i >= off {
+		v.tail = append([]T(nil), v.tail...)
+		v.tail[i-off] = x
+
+		return v
+	}
+
+	v.root = v.root.set(v.shift, i, x)
+
+	return v
+}
+
+func (n *vnode[T]) set(level uint, i int, x T) *vnode[T] {
+	c := &vnode[T]{}
+
+	if level == 0 {
+		c.items = append([]T(nil), n.items...)
+		c.items[i&vmask] = x
+
+		return c
+	}
+
+	c.children = append([]*vnode[T](nil), n.children...)
+	sub := (i >> level) & vmask
+	c.children[sub] = n.children[sub].set(level-vbits, i, x)
+
+	return c
+}
+
+// Slice copies the items of the vector in order
+func (v vector[T]) Slice() []T {
+	xs := make([]T, 0, v.size)
+	v.Each(func(_ int, x T) bool {
+		xs = append(xs, x)
+		return true
+	})
+
+	return xs
+}
+
+// Each calls `f` with the items of the vector in order until it returns
+// false. It reports whether every call returned true.
+func (v vector[T]) Each(f func(i int, x T) bool) bool {
+	i := 0
+
+	var walk func(n *vnode[T], level uint) bool
+	walk = func(n *vnode[T], level uint) bool {
+		if level == 0 {
+			for _, x := range n.items {
+				if !f(i, x) {
+					return false
+				}
+
+				i++
+			}
+
+			return true
+		}
+
+		for _, c := range n.children {
+			if !walk(c, level-vbits) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if v.root != nil && !walk(v.root, v.shift) {
+		return false
+	}
+
+	for _, x := range v.tail {
+		if !f(i, x) {
+			return false
+		}
+
+		i++
+	}
+
+	return true
+}
+
+// flushTail moves the full tail into the trie, leaving an empty tail
+func (v vector[T]) flushTail() vector[T] {
+	leaf := &vnode[T]{items: v.tail}
+
+	// Vectors that fit in their tail have no trie yet
+	if v.root == nil {
+		v.root, v.shift = &vnode[T]{}, vbits
+	}
+
+	// The root is full: the trie grows a level
+	if (v.size >> vbits) > (1 << v.shift) {
+		v.root = &vnode[T]{children: []*vnode[T]{v.root, newPath(v.shift, leaf)}}
+		v.shift += vbits
+	} else {
+		v.root = v.pushLeaf(v.shift, v.root, leaf)
+	}
+
+	v.tail = nil
+
+	return v
+}
+
+func (v vector[T]) pushLeaf(level uint, parent *vnode[T], leaf *vnode[T]) *vnode[T] {
+	sub := ((v.size - 1) >> level) & vmask
+	c := &vnode[T]{children: append([]*vnode[T](nil), parent.children...)}
+
+	if level == vbits {
+		c.children = append(c.children, leaf)
+		return c
+	}
+
+	if sub < len(parent.children) {
+		c.children[sub] = v.pushLeaf(level-vbits, parent.children[sub], leaf)
+		return c
+	}
+
+	c.children = append(c.children, newPath(level-vbits, leaf))
+
+	return c
+}
+
+// newPath wraps `leaf` in enough nodes to hang it `level` bits above the
+// leaves
+func newPath[T any](level uint, leaf *vnode[T]) *vnode[T] {
+	if level == 0 {
+		return leaf
+	}
+
+	return &vnode[T]{children: []*vnode[T]{newPath(level-vbits, leaf)}}
+}
@@ -11,18 +11,24 @@ type evisitor[T any] interface {
 	VisitGroupingExpr(e ast.GroupingExpr) (T, error)
 	VisitNumLitExpr(e ast.NumericLiteralExpr) (T, error)
 	VisitStrLitExpr(e ast.StringLiteralExpr) (T, error)
+	VisitBytesLitExpr(e ast.BytesLiteralExpr) (T, error)
+	VisitRegexLitExpr(e ast.RegexLiteralExpr) (T, error)
 	VisitBottomLitExpr(e ast.BottomLiteralExpr) (T, error)
 	VisitBooleanLitExpr(e ast.BooleanLiteralExpr) (T, error)
 	VisitTupleLitExpr(e ast.TupleLiteralExpr) (T, error)
 	VisitRecordLitExpr(e ast.RecordLiteralExpr) (T, error)
+	VisitSetLitExpr(e ast.SetLiteralExpr) (T, error)
 	VisitSpreadExpr(e ast.SpreadExpr) (T, error)
 	VisitIdentifierExpr(e ast.IdentifierExpr) (T, error)
 	VisitFuncExpr(e ast.FuncExpr) (T, error)
 	VisitCallExpr(e ast.CallExpr) (T, error)
 	VisitMeExpr(e ast.MeExpr) (T, error)
+	VisitSuperExpr(e ast.SuperExpr) (T, error)
 	VisitProtoExpr(e ast.ProtoExpr) (T, error)
 	VisitGetExpr(e ast.GetExpr) (T, error)
 	VisitQuestionExpr(e ast.QuestionExpr) (T, error)
+	VisitRangeExpr(e ast.RangeExpr) (T, error)
+	VisitLoopExpr(e ast.LoopExpr) (T, error)
 }
 
 type svisitor[T any] interface {
@@ -34,6 +40,12 @@ type svisitor[T any] interface {
 	VisitWhileStmt(s ast.WhileStmt) (T, error)
 	VisitContStmt(s ast.ContinueStmt) (T, error)
 	VisitBrkStmt(s ast.BreakStmt) (T, error)
+	VisitDeferStmt(s ast.DeferStmt) (T, error)
+	VisitYieldStmt(s ast.YieldStmt) (T, error)
+	VisitThrowStmt(s ast.ThrowStmt) (T, error)
+	VisitTryStmt(s ast.TryStmt) (T, error)
+	VisitAssertStmt(s ast.AssertStmt) (T, error)
+	VisitProtocolStmt(s ast.ProtocolStmt) (T, error)
 }
 
 type visitor[T any] interface {
@@ -70,6 +82,16 @@ func AcceptExpr[T any](e ast.Expr, v evisitor[T]) (T, error) {
 
 		return v.VisitStrLitExpr(e)
 
+	case ast.BytesLiteralExpr:
+		e := e.(ast.BytesLiteralExpr)
+
+		return v.VisitBytesLitExpr(e)
+
+	case ast.RegexLiteralExpr:
+		e := e.(ast.RegexLiteralExpr)
+
+		return v.VisitRegexLitExpr(e)
+
 	case ast.BottomLiteralExpr:
 		e := e.(ast.BottomLiteralExpr)
 
@@ -90,6 +112,11 @@ func AcceptExpr[T any](e ast.Expr, v evisitor[T]) (T, error) {
 
 		return v.VisitRecordLitExpr(e)
 
+	case ast.SetLiteralExpr:
+		e := e.(ast.SetLiteralExpr)
+
+		return v.VisitSetLitExpr(e)
+
 	case ast.SpreadExpr:
 		e := e.(ast.SpreadExpr)
 
@@ -119,6 +146,11 @@ func AcceptExpr[T any](e ast.Expr, v evisitor[T]) (T, error) {
 
 		return v.VisitMeExpr(e)
 
+	case ast.SuperExpr:
+		e := e.(ast.SuperExpr)
+
+		return v.VisitSuperExpr(e)
+
 	case ast.ProtoExpr:
 		e := e.(ast.ProtoExpr)
 
@@ -133,6 +165,16 @@ func AcceptExpr[T any](e ast.Expr, v evisitor[T]) (T, error) {
 		e := e.(ast.QuestionExpr)
 
 		return v.VisitQuestionExpr(e)
+
+	case ast.RangeExpr:
+		e := e.(ast.RangeExpr)
+
+		return v.VisitRangeExpr(e)
+
+	case ast.LoopExpr:
+		e := e.(ast.LoopExpr)
+
+		return v.VisitLoopExpr(e)
 	}
 
 	return empty, errors.New("Unexpected expression")
@@ -187,6 +229,36 @@ func Accept[T any](n ast.Node, v visitor[T]) (T, error) {
 		s := n.(ast.BreakStmt)
 
 		return v.VisitBrkStmt(s)
+
+	case ast.DeferStmt:
+		s := n.(ast.DeferStmt)
+
+		return v.VisitDeferStmt(s)
+
+	case ast.YieldStmt:
+		s := n.(ast.YieldStmt)
+
+		return v.VisitYieldStmt(s)
+
+	case ast.ThrowStmt:
+		s := n.(ast.ThrowStmt)
+
+		return v.VisitThrowStmt(s)
+
+	case ast.TryStmt:
+		s := n.(ast.TryStmt)
+
+		return v.VisitTryStmt(s)
+
+	case ast.AssertStmt:
+		s := n.(ast.AssertStmt)
+
+		return v.VisitAssertStmt(s)
+
+	case ast.ProtocolStmt:
+		s := n.(ast.ProtocolStmt)
+
+		return v.VisitProtocolStmt(s)
 	}
 
 	return empty, errors.New("Supplied node did not match any node type")
@@ -0,0 +1,83 @@
+package interpreter
+
+import (
+	"calabash/errors"
+	"calabash/internal/tokentype"
+	"calabash/internal/value"
+	"math"
+)
+
+// arithmetic applies a numeric operator to two numeric values. Integers stay
+// exact under `+`, `-`, `*` and `**` with a non-negative exponent; any other
+// operation, or one involving a float, is computed with floats. Comparisons
+// are exact whatever the kinds of the operands.
+func arithmetic(op tokentype.Tokentype, l, r value.Value) (value.Value, error) {
+	li, okl := l.(*value.Integer)
+	ri, okr := r.(*value.Integer)
+
+	if okl && okr {
+		switch op {
+		case tokentype.PLUS:
+			return li.Add(ri), nil
+
+		case tokentype.MINUS:
+			return li.Sub(ri), nil
+
+		case tokentype.ASTERISK:
+			return li.Mul(ri), nil
+
+		case tokentype.ASTERISK_ASTERISK:
+			if ri.Sign() >= 0 {
+				p, ok := li.Pow(ri)
+
+				if !ok {
+					return nil, errors.RuntimeError{Msg: "Integer power is too large"}
+				}
+
+				return p, nil
+			}
+		}
+	}
+
+	switch op {
+	case tokentype.LESS, tokentype.LESS_EQUAL, tokentype.GREAT, tokentype.GREAT_EQUAL:
+		c, ok := value.CompareNumbers(l, r)
+
+		// NaN is unordered, so every comparison with it is false
+		if !ok {
+			return value.NewBoolean(false), nil
+		}
+
+		switch op {
+		case tokentype.LESS:
+			return value.NewBoolean(c < 0), nil
+
+		case tokentype.LESS_EQUAL:
+			return value.NewBoolean(c <= 0), nil
+
+		case tokentype.GREAT:
+			return value.NewBoolean(c > 0), nil
+		}
+
+		return value.NewBoolean(c >= 0), nil
+	}
+
+	lf, _ := value.ToFloat(l)
+	rf, _ := value.ToFloat(r)
+
+	switch op {
+	case tokentype.PLUS:
+		return value.NewNumber(lf + rf), nil
+
+	case tokentype.MINUS:
+		return value.NewNumber(lf - rf), nil
+
+	case tokentype.ASTERISK:
+		return value.NewNumber(lf * rf), nil
+
+	case tokentype.SLASH:
+		return value.NewNumber(lf / rf), nil
+	}
+
+	return value.NewNumber(math.Pow(lf, rf)), nil
+}
@@ -0,0 +1,84 @@
+package interpreter_test
+
+import (
+	"calabash/interpreter"
+	"calabash/lexer/scanner"
+	"calabash/parser"
+	"fmt"
+	"testing"
+)
+
+// benchmarkProgram is a named script run by a benchmark
+type benchmarkProgram struct {
+	name string
+	text string
+}
+
+func BenchmarkRecords(b *testing.B) {
+	table := []benchmarkProgram{
+		{
+			name: "building and reading a record with tuple keys",
+			text: "let mut r = {}; let mut i = 0; while i < 200 { r = r->'set'([i, 'k'], { 'n' -> i }); i = i + 1; } let mut j = 0; while j < 200 { let v = r->'get'([j, 'k']); j = j + 1; }",
+		},
+		{
+			name: "comparing nested records",
+			text: "let row = fn (n) -> { 'id' -> n, 'tags' -> ['a', 'b', 'c'], 'meta' -> { 'x' -> n, 'y' -> [n, n] } }; let a = (0..200)->'toTuple'()->'map'(row); let b = (0..200)->'toTuple'()->'map'(row); let mut i = 0; while i < 50 { let eq = a == b; i = i + 1; }",
+		},
+	}
+
+	benchmarkPrograms(b, table)
+}
+
+func BenchmarkBuilding(b *testing.B) {
+	table := []benchmarkProgram{}
+
+	for _, n := range []int{1000, 4000} {
+		table = append(table, benchmarkProgram{
+			name: fmt.Sprintf("pushing %d elements", n),
+			text: fmt.Sprintf("let mut t = []; let mut i = 0; while i < %d { t = t->'push'(i); i = i + 1; }", n),
+		}, benchmarkProgram{
+			name: fmt.Sprintf("setting %d record keys", n),
+			text: fmt.Sprintf("let mut r = {}; let mut i = 0; while i < %d { r = r->'set'(i, i); i = i + 1; }", n),
+		}, benchmarkProgram{
+			name: fmt.Sprintf("deleting %d record keys", n),
+			text: fmt.Sprintf("let mut r = {}; let mut i = 0; while i < %[1]d { r = r->'set'(i, i); i = i + 1; } i = 0; while i < %[1]d { r = r->'delete'(i); i = i + 1; }", n),
+		})
+	}
+
+	benchmarkPrograms(b, table)
+}
+
+func BenchmarkRegexes(b *testing.B) {
+	table := []benchmarkProgram{
+		{
+			name: "matching a regex literal in a loop",
+			text: "let mut n = 0; let mut i = 0; while i < 1000 { if r'^(\\w+)@(\\w+)\\.com$'->'test'('someone@example.com') { n = n + 1; } i = i + 1; }",
+		},
+	}
+
+	benchmarkPrograms(b, table)
+}
+
+func benchmarkPrograms(b *testing.B, table []benchmarkProgram) {
+	for _, e := range table {
+		ts, err := scanner.New().Read(e.text)
+
+		if err != nil {
+			b.Fatalf("%q: %s", e.name, err)
+		}
+
+		nodes, err := parser.New(ts).Parse()
+
+		if err != nil {
+			b.Fatalf("%q: %s", e.name, err)
+		}
+
+		b.Run(e.name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				if _, err := interpreter.New().Eval(nodes); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package interpreter
+
+import (
+	"calabash/ast"
+	"calabash/errors"
+	"calabash/internal/environment"
+	"calabash/internal/stack"
+	"calabash/internal/value"
+	"calabash/lexer/tokens"
+	errs "errors"
+)
+
+// A generator runs the body of a generator function one step at a time.
+// The interpreter walks the tree recursively, so a generator cannot simply
+// pause the Go call stack at a yield. Instead, the statements that can hold a
+// yield (blocks, `if`, `while`, `loop` and `try`) are run by frames that
+// remember how far they got and the scope they run in. Resuming the
+// outermost frame resumes each frame nested in it down to the yield that
+// paused it. No goroutine is involved, so a generator that is dropped before
+// it finishes is simply garbage collected.
+type generator struct {
+	i       *interpreter
+	body    frame
+	defers  *stack.Stack[deferred]
+	running bool
+}
+
+// frame is a statement of a generator's body that can be paused at a yield
+type frame interface {
+	// resume runs the statement until it yields a value or finishes.
+	// Control flow such as `break` and `return` is reported as an error, as
+	// it is when evaluating the statement directly.
+	resume(i *interpreter) (v value.Value, yielded bool, err error)
+}
+
+// Generate makes the iterator returned by a call to a generator function. The
+// body runs in the scope the call bound its arguments in.
+func (i *interpreter) Generate(body ast.Block) *value.Iterator {
+	g := &generator{
+		i:      i,
+		body:   &blockFrame{nodes: body.Contents, env: i.env},
+		defers: stack.New[deferred](),
+	}
+
+	return value.NewIterator(g.next, g.close)
+}
+
+func (g *generator) next() (value.Value, bool, error) {
+	if g.running {
+		return nil, false, errors.RuntimeError{Msg: "A generator cannot advance itself while it is running"}
+	}
+
+	g.running = true
+	env := g.i.env
+
+	defer func() {
+		g.running = false
+		g.i.env = env
+	}()
+
+	// Deferred expressions belong to the generator rather than to whichever
+	// call happens to advance it
+	g.i.defers.Push(g.defers)
+
+	v, yielded, err := g.body.resume(g.i)
+
+	if yielded {
+		g.i.defers.Pop()
+		return v, true, nil
+	}
+
+	// The body finished, returned or failed, so it is over and its deferred
+	// expressions run now
+	if errs.Is(err, errors.ReturnError{}) {
+		err = nil
+	}
+
+	return nil, false, g.i.RunDefers(err)
+}
+
+// close ends a generator that is paused at a yield, running its deferred
+// expressions
+func (g *generator) close() error {
+	if g.running {
+		return errors.RuntimeError{Msg: "A generator cannot close itself while it is running"}
+	}
+
+	env := g.i.env
+	defer func() { g.i.env = env }()
+
+	g.i.defers.Push(g.defers)
+
+	return g.i.RunDefers(nil)
+}
+
+// yields tells whether `n` is or holds a yield that a frame has to pause at.
+// Yields in nested functions belong to those functions, and the static
+// analyzer keeps yields out of expressions, so only statements are searched.
+func yields(n ast.Node) bool {
+	switch n := n.(type) {
+	case ast.YieldStmt:
+		return true
+
+	case ast.Block:
+		for _, c := range n.Contents {
+			if yields(c) {
+				return true
+			}
+		}
+
+	case ast.IfStmt:
+		return yields(n.Then) || (n.Else != nil && yields(n.Else))
+
+	case ast.WhileStmt:
+		return yields(n.Block)
+
+	case ast.LoopExpr:
+		return yields(n.Body)
+
+	case ast.TryStmt:
+		return yields(n.Body) || yields(n.Catch)
+	}
+
+	return false
+}
+
+// frameFor makes the frame that runs `n` in `env`
+func frameFor(n ast.Node, env *environment.Environment[value.Value]) frame {
+	switch n := n.(type) {
+	case ast.Block:
+		return &blockFrame{nodes: n.Contents, env: environment.New(env)}
+
+	case ast.IfStmt:
+		return &ifFrame{stmt: n, parent: env}
+
+	case ast.WhileStmt:
+		return &whileFrame{stmt: n, parent: env}
+
+	case ast.LoopExpr:
+		return &loopFrame{expr: n, env: env}
+
+	case ast.TryStmt:
+		return &tryFrame{stmt: n, env: env}
+	}
+
+	return &nodeFrame{node: n, env: env}
+}
+
+// nodeFrame runs a statement without yields in one go
+type nodeFrame struct {
+	node ast.Node
+	env  *environment.Environment[value.Value]
+}
+
+func (f *nodeFrame) resume(i *interpreter) (value.Value, bool, error) {
+	i.env = f.env
+	_, err := i.evalNode(f.node)
+
+	return nil, false, err
+}
+
+type blockFrame struct {
+	nodes []ast.Node
+	env   *environment.Environment[value.Value]
+	next  int
+	child frame
+}
+
+func (f *blockFrame) resume(i *interpreter) (value.Value, bool, error) {
+	for {
+		if f.child != nil {
+			v, yielded, err := f.child.resume(i)
+
+			if yielded || err != nil {
+				return v, yielded, err
+			}
+
+			f.child = nil
+		}
+
+		if f.next == len(f.nodes) {
+			return nil, false, nil
+		}
+
+		i.env = f.env
+		n := f.nodes[f.next]
+		f.next++
+
+		if y, ok := n.(ast.YieldStmt); ok {
+			v, err := i.evalNode(y.Expr)
+
+			if err != nil {
+				return nil, false, err
+			}
+
+			return v.(value.Value), true, nil
+		}
+
+		if yields(n) {
+			f.child = frameFor(n, f.env)
+			continue
+		}
+
+		if _, err := i.evalNode(n); err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+type ifFrame struct {
+	stmt   ast.IfStmt
+	parent *environment.Environment[value.Value]
+	branch frame
+}
+
+func (f *ifFrame) resume(i *interpreter) (value.Value, bool, error) {
+	if f.branch == nil {
+		env := environment.New(f.parent)
+		i.env = env
+
+		if _, err := i.VisitVarDeclStmt(f.stmt.Decls); err != nil {
+			return nil, false, err
+		}
+
+		cond, err := condition(i, f.stmt.Condition, "If condition must resolve to a boolean value.")
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		n := f.stmt.Then
+
+		if !cond {
+			n = f.stmt.Else
+		}
+
+		if n == nil {
+			return nil, false, nil
+		}
+
+		f.branch = frameFor(n, env)
+	}
+
+	return f.branch.resume(i)
+}
+
+type whileFrame struct {
+	stmt   ast.WhileStmt
+	parent *environment.Environment[value.Value]
+	env    *environment.Environment[value.Value]
+	body   frame
+}
+
+func (f *whileFrame) resume(i *interpreter) (value.Value, bool, error) {
+	if f.env == nil {
+		f.env = environment.New(f.parent)
+		i.env = f.env
+
+		if _, err := i.VisitVarDeclStmt(f.stmt.Decls); err != nil {
+			return nil, false, err
+		}
+	}
+
+	for {
+		if f.body == nil {
+			i.env = f.env
+			cond, err := condition(i, f.stmt.Condition, "While condition must be a boolean value.")
+
+			if err != nil || !cond {
+				return nil, false, err
+			}
+
+			f.body = frameFor(f.stmt.Block, f.env)
+		}
+
+		v, yielded, err := f.body.resume(i)
+
+		if yielded {
+			return v, true, nil
+		}
+
+		f.body = nil
+
+		if stop, err := loopJump(f.stmt.Label, err); stop || err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+// loopFrame runs a `loop` used as a statement, so its break value is dropped
+type loopFrame struct {
+	expr ast.LoopExpr
+	env  *environment.Environment[value.Value]
+	body frame
+}
+
+func (f *loopFrame) resume(i *interpreter) (value.Value, bool, error) {
+	for {
+		if f.body == nil {
+			f.body = frameFor(f.expr.Body, f.env)
+		}
+
+		v, yielded, err := f.body.resume(i)
+
+		if yielded {
+			return v, true, nil
+		}
+
+		f.body = nil
+
+		if stop, err := loopJump(f.expr.Label, err); stop || err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+type tryFrame struct {
+	stmt     ast.TryStmt
+	env      *environment.Environment[value.Value]
+	body     frame
+	catching bool
+}
+
+func (f *tryFrame) resume(i *interpreter) (value.Value, bool, error) {
+	if f.body == nil {
+		f.body = frameFor(f.stmt.Body, f.env)
+	}
+
+	for {
+		v, yielded, err := f.body.resume(i)
+
+		if yielded || err == nil || f.catching {
+			return v, yielded, err
+		}
+
+		ev, ok := catchable(err)
+
+		if !ok {
+			return nil, false, err
+		}
+
+		env := environment.New(f.env)
+
+		if f.stmt.Name != nil {
+			env.Add(f.stmt.Name.Lexeme, ev)
+		}
+
+		f.catching = true
+		f.body = frameFor(f.stmt.Catch, env)
+	}
+}
+
+// loopJump settles how a pass through the body of the loop labeled `label`
+// ended: a `break` aimed at the loop stops it, a `continue` aimed at it
+// carries on, and other errors are handed back
+func loopJump(label *tokens.Token, err error) (bool, error) {
+	var brk errors.BreakError
+
+	if errs.As(err, &brk) && targetsLoop(label, brk.Label) {
+		return true, nil
+	}
+
+	var cont errors.ContinueError
+
+	if errs.As(err, &cont) && targetsLoop(label, cont.Label) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+// condition evaluates the condition of an `if` or `while`, failing with `msg`
+// when it is not a boolean
+func condition(i *interpreter, e ast.Expr, msg string) (bool, error) {
+	v, err := i.evalNode(e)
+
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := v.(*value.Boolean)
+
+	if !ok {
+		return false, errors.RuntimeError{Msg: msg}
+	}
+
+	return b.Value, nil
+}
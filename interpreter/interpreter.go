@@ -4,17 +4,52 @@ import (
 	"calabash/ast"
 	"calabash/errors"
 	"calabash/internal/environment"
+	"calabash/internal/stack"
 	"calabash/internal/tokentype"
 	"calabash/internal/value"
 	"calabash/internal/visitor"
+	"calabash/lexer/tokens"
+	"encoding/hex"
 	errs "errors"
 	"fmt"
 	"math"
+	"math/big"
+	"regexp"
 	"strconv"
+	"time"
 )
 
 type interpreter struct {
-	env *environment.Environment[value.Value]
+	env     *environment.Environment[value.Value]
+	defers  *stack.Stack[*stack.Stack[deferred]]
+	asserts bool
+	clock   func() time.Time
+	// regexes holds the regex compiled for each regex literal, so that a
+	// literal in a loop or function body is compiled once
+	regexes map[tokens.Token]*value.Regex
+}
+
+// Option configures an interpreter created with `New`
+type Option func(i *interpreter)
+
+// DisableAsserts turns every `assert` statement into a no-op
+func DisableAsserts() Option {
+	return func(i *interpreter) {
+		i.asserts = false
+	}
+}
+
+// WithClock makes `time->'now'()` read `now` instead of the system clock, so
+// that hosts can freeze or fake time
+func WithClock(now func() time.Time) Option {
+	return func(i *interpreter) {
+		i.clock = now
+	}
+}
+
+type deferred struct {
+	expr ast.Expr
+	env  *environment.Environment[value.Value]
 }
 
 func (i *interpreter) Eval(ns []ast.Node) (interface{}, error) {
@@ -37,7 +72,16 @@ func (i *interpreter) Eval(ns []ast.Node) (interface{}, error) {
 }
 
 func (i *interpreter) evalNode(n ast.Node) (interface{}, error) {
-	return visitor.Accept[interface{}](n, i)
+	v, err := visitor.Accept[interface{}](n, i)
+
+	// Nodes hand errors up through the nodes holding them, so the first node
+	// with a token to place the error at is the innermost one
+	if rt, ok := err.(errors.RuntimeError); ok && rt.Token == nil {
+		rt.Token = nodeToken(n)
+		err = rt
+	}
+
+	return v, err
 }
 
 func (i *interpreter) evalBooleanAnd(l interface{}, r ast.Expr) (interface{}, error) {
@@ -134,16 +178,33 @@ func (i *interpreter) VisitBinaryExpr(e ast.BinaryExpr) (interface{}, error) {
 		return nil, err
 	}
 
+	return i.binaryOp(e.Operator, l, r)
+}
+
+// binaryOp applies a non-short-circuiting binary operator to operands that
+// have already been evaluated. Built-in semantics for numbers, strings and
+// proto inheritance take precedence; otherwise the operator method of the left
+// operand's proto (keyed by the operator, e.g. "+") is called with the right
+// operand. Equality instead checks for an "==" method first and falls back to
+// comparing numbers by value and anything else with the operands' structural
+// `Equal`, which containers back with `HashCode`.
+func (i *interpreter) binaryOp(operator tokens.Token, l, r interface{}) (interface{}, error) {
+	op := operator.Type
+
+	lv, okl := l.(value.Value)
+	rv, okr := r.(value.Value)
+
+	if !okl || !okr {
+		return nil, errors.RuntimeError{Msg: fmt.Sprintf("Operands of binary %q must be values", operator.Lexeme)}
+	}
+
 	if op == tokentype.EQUAL_EQUAL || op == tokentype.BANG_EQUAL {
-		l, okl := l.(value.Value)
-		r, okr := r.(value.Value)
+		b, err := i.equals(lv, rv)
 
-		if !okl || !okr {
-			return nil, errors.RuntimeError{Msg: "Can not check equality of non-values"}
+		if err != nil {
+			return nil, err
 		}
 
-		b := l.Hash() == r.Hash()
-
 		if op == tokentype.BANG_EQUAL {
 			b = !b
 		}
@@ -154,81 +215,88 @@ func (i *interpreter) VisitBinaryExpr(e ast.BinaryExpr) (interface{}, error) {
 	// The '+' operator is overloaded for different data types. The left and right
 	// sides must be of the same type but they could be many different types.
 	if op == tokentype.PLUS {
-		ln, okl := l.(*value.Number)
-		rn, okr := r.(*value.Number)
+		ls, okl := l.(*value.String)
+		rs, okr := r.(*value.String)
 
 		if okl && okr {
-			return value.NewNumber(ln.Value + rn.Value), nil
+			return value.NewString(ls.Value + rs.Value), nil
 		}
 
-		ls, okl := l.(*value.String)
-		rs, okr := r.(*value.String)
+		lp, okl := l.(*value.Proto)
+		rp, okr := r.(*value.Proto)
 
 		if okl && okr {
-			return value.NewString(ls.Value + rs.Value), nil
+			return value.Compose([]*value.Proto{lp, rp}, nil)
 		}
+	}
 
-		return nil, errors.RuntimeError{Msg: "The types for binary '+' are not the same"}
+	if (isNumericOp(op) || op == tokentype.PLUS) && areNumbers(l, r) {
+		return arithmetic(op, lv, rv)
 	}
 
-	if isNumericOp(op) && areNumbers(l, r) {
-		ln, _ := l.(*value.Number)
-		rn, _ := r.(*value.Number)
-		var val value.Value
+	if op == tokentype.LESS {
+		if rp, ok := r.(*value.Proto); ok {
+			return lv.Inherit(rp), nil
+		}
+	}
 
-		switch op {
-		case tokentype.MINUS:
-			val = value.NewNumber(ln.Value - rn.Value)
+	if m, ok := operatorMethod(lv, operator.Lexeme); ok {
+		return i.call(m, []value.Value{rv})
+	}
 
-		case tokentype.ASTERISK:
-			val = value.NewNumber(ln.Value * rn.Value)
+	if op == tokentype.PLUS {
+		return nil, errors.RuntimeError{Msg: "The types for binary '+' are not the same"}
+	}
 
-		case tokentype.SLASH:
-			val = value.NewNumber(ln.Value / rn.Value)
+	if op == tokentype.LESS {
+		return nil, errors.RuntimeError{Msg: "Non-numeric `<` requires RHS to be a prototype or LHS to define a \"<\" method"}
+	}
 
-		case tokentype.ASTERISK_ASTERISK:
-			val = value.NewNumber(math.Pow(ln.Value, rn.Value))
+	if isNumericOp(op) {
+		return nil, errors.RuntimeError{Msg: fmt.Sprintf("Received a non-numeric value for numeric binary operator %q", operator.Lexeme)}
+	}
 
-		case tokentype.GREAT:
-			val = value.NewBoolean(ln.Value > rn.Value)
+	return nil, errors.RuntimeError{Msg: fmt.Sprintf("Received unsupported binary operator %q", operator.Lexeme)}
+}
 
-		case tokentype.GREAT_EQUAL:
-			val = value.NewBoolean(ln.Value >= rn.Value)
+// equals compares two values with the "==" method of the left value's proto
+// when it defines one and structurally otherwise
+func (i *interpreter) equals(l, r value.Value) (bool, error) {
+	m, ok := operatorMethod(l, "==")
 
-		case tokentype.LESS:
-			val = value.NewBoolean(ln.Value < rn.Value)
+	if !ok {
+		// Numbers compare by value, whatever their kinds, so that `1 == 1.0`
+		// and NaN is unequal to itself
+		if value.IsNumeric(l) && value.IsNumeric(r) {
+			c, ok := value.CompareNumbers(l, r)
 
-		case tokentype.LESS_EQUAL:
-			val = value.NewBoolean(ln.Value <= rn.Value)
+			return ok && c == 0, nil
 		}
 
-		return val, nil
+		return l.Equal(r), nil
 	}
 
-	if op == tokentype.LESS {
-		rp, ok := r.(*value.Proto)
-
-		if !ok {
-			return nil, errors.RuntimeError{Msg: "Non-numeric `<` requires RHS to be a prototype"}
-		}
-
-		lv, ok := l.(value.Value)
-
-		if !ok {
-			return nil, errors.RuntimeError{Msg: "Did not receive a value"}
-		}
+	v, err := i.call(m, []value.Value{r})
 
-		return lv.Inherit(rp), nil
+	if err != nil {
+		return false, err
 	}
 
-	if isNumericOp(op) {
-		return nil, errors.RuntimeError{Msg: fmt.Sprintf("Received a non-numeric value for numeric binary operator %q", e.Operator.Lexeme)}
+	b, ok := v.(*value.Boolean)
+
+	if !ok {
+		return false, errors.RuntimeError{Msg: "The \"==\" method must return a boolean"}
 	}
 
-	return nil, errors.RuntimeError{Msg: fmt.Sprintf("Received unsupported binary operator %q", e.Operator.Lexeme)}
+	return b.Value, nil
 }
 
 func (i *interpreter) VisitNumLitExpr(e ast.NumericLiteralExpr) (interface{}, error) {
+	// Literals without a decimal point are integers
+	if b, ok := new(big.Int).SetString(e.Value.Lexeme, 10); ok {
+		return value.NewBigInteger(b), nil
+	}
+
 	n, err := strconv.ParseFloat(e.Value.Lexeme, 64)
 
 	if err != nil {
@@ -263,16 +331,72 @@ func (i *interpreter) VisitUnaryExpr(e ast.UnaryExpr) (interface{}, error) {
 	switch op {
 	case tokentype.MINUS:
 		{
-			if val, ok := expr.(*value.Number); ok {
-				val.Value *= -1
-				return val, nil
+			switch val := expr.(type) {
+			case *value.Number:
+				return value.NewNumber(-val.Value), nil
+
+			case *value.Integer:
+				return val.Neg(), nil
+
+			case *value.Duration:
+				if val.Value == math.MinInt64 {
+					return nil, errors.RuntimeError{Msg: "Duration overflow"}
+				}
+
+				return value.NewDuration(-val.Value), nil
+			}
+
+			return nil, errors.RuntimeError{Msg: "Can only use unary minus with numbers and durations."}
+		}
+
+	case tokentype.BANG:
+		{
+			if val, ok := expr.(*value.Boolean); ok {
+				return value.NewBoolean(!val.Value), nil
 			}
 
-			return nil, errors.RuntimeError{Msg: "Can only use unary minus with numbers."}
+			return nil, errors.RuntimeError{Msg: "Can only use unary not with booleans."}
 		}
 	}
 
-	return nil, errors.RuntimeError{Msg: fmt.Sprintf("The only supported unary operators are '-': got %q", e.Operator.Lexeme)}
+	return nil, errors.RuntimeError{Msg: fmt.Sprintf("The only supported unary operators are '-' and '!': got %q", e.Operator.Lexeme)}
+}
+
+func (i *interpreter) VisitBytesLitExpr(e ast.BytesLiteralExpr) (interface{}, error) {
+	l := e.Value.Lexeme
+	text := l[2 : len(l)-1]
+
+	if l[0] == 'b' {
+		return value.NewBytes([]byte(text)), nil
+	}
+
+	// The scanner has already checked the digits
+	bs, err := hex.DecodeString(text)
+
+	if err != nil {
+		return nil, errors.RuntimeError{Msg: err.Error()}
+	}
+
+	return value.NewBytes(bs), nil
+}
+
+func (i *interpreter) VisitRegexLitExpr(e ast.RegexLiteralExpr) (interface{}, error) {
+	if r, ok := i.regexes[e.Value]; ok {
+		return r, nil
+	}
+
+	// The scanner has already checked the pattern
+	l := e.Value.Lexeme
+	re, err := regexp.Compile(l[2 : len(l)-1])
+
+	if err != nil {
+		return nil, errors.RuntimeError{Msg: err.Error()}
+	}
+
+	r := value.NewRegex(re)
+	i.regexes[e.Value] = r
+
+	return r, nil
 }
 
 func (i *interpreter) VisitBottomLitExpr(e ast.BottomLiteralExpr) (interface{}, error) {
@@ -297,8 +421,7 @@ func (i *interpreter) VisitTupleLitExpr(e ast.TupleLiteralExpr) (interface{}, er
 		}
 
 		if spreadable {
-			tpl := ifc.(*value.Tuple)
-			vs = append(vs, tpl.Items...)
+			vs = appendSequence(vs, ifc.(value.Sequence))
 			continue
 		}
 
@@ -314,6 +437,35 @@ func (i *interpreter) VisitTupleLitExpr(e ast.TupleLiteralExpr) (interface{}, er
 	return value.NewTuple(vs), nil
 }
 
+func (i *interpreter) VisitSetLitExpr(e ast.SetLiteralExpr) (interface{}, error) {
+	vs := make([]value.Value, 0, len(e.Contents))
+
+	for _, c := range e.Contents {
+		_, spreadable := c.(ast.SpreadExpr)
+
+		ifc, err := i.evalNode(c)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if spreadable {
+			vs = appendSequence(vs, ifc.(value.Sequence))
+			continue
+		}
+
+		v, ok := ifc.(value.Value)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Did not receive a Value for set element"}
+		}
+
+		vs = append(vs, v)
+	}
+
+	return value.NewSet(vs), nil
+}
+
 func (i *interpreter) VisitSpreadExpr(e ast.SpreadExpr) (interface{}, error) {
 	exp, err := i.evalNode(e.Expr)
 
@@ -321,16 +473,51 @@ func (i *interpreter) VisitSpreadExpr(e ast.SpreadExpr) (interface{}, error) {
 		return nil, err
 	}
 
-	tpl, ok := exp.(*value.Tuple)
+	if r, ok := exp.(*value.Range); ok && r.Len() > value.MaxRangeTuple {
+		return nil, errors.RuntimeError{Msg: "Range is too long to spread"}
+	}
 
-	if !ok {
-		return nil, errors.RuntimeError{Msg: "Only tuple values can be spread"}
+	if seq, ok := exp.(value.Sequence); ok {
+		return seq, nil
+	}
+
+	// Iterators are spread by draining them. Callbacks of lazy iterator
+	// methods are called as if from a built-in method, so a scope is pushed
+	// for them to skip.
+	if _, ok := operatorMethod(exp, "next"); !ok {
+		return nil, errors.RuntimeError{Msg: "Only tuple, set, range, bytes and iterator values can be spread"}
+	}
+
+	i.PushEnv(nil)
+	defer i.PopEnv()
+
+	it, err := value.Iterate(exp.(value.Value), func(c value.Caller) (value.Value, error) {
+		r, err := i.call(c, nil)
+		rv, _ := r.(value.Value)
+
+		return rv, err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	vs, err := value.Drain(it)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return tpl, nil
+	return value.NewTuple(vs), nil
 }
 
 func (i *interpreter) VisitIdentifierExpr(e ast.IdentifierExpr) (interface{}, error) {
+	// Globals are visible from every scope, including functions that do not
+	// close over their environment, unless a declaration shadows them
+	if g, ok := value.Globals[e.Name.Lexeme]; ok && !i.env.Has(e.Name.Lexeme) {
+		return g, nil
+	}
+
 	return i.env.Get(e.Name.Lexeme), nil
 }
 
@@ -339,6 +526,7 @@ func (i *interpreter) VisitFuncExpr(e ast.FuncExpr) (interface{}, error) {
 		Body:      e.Body,
 		ParamList: e.Params,
 		Depth:     e.Depth,
+		Generator: e.Generator,
 	}
 
 	return fn, nil
@@ -355,6 +543,11 @@ func (i *interpreter) VisitCallExpr(e ast.CallExpr) (interface{}, error) {
 
 	vfunc, ok := callee.(value.Caller)
 
+	// Values whose proto defines a "call" method can be called like functions
+	if !ok {
+		vfunc, ok = operatorMethod(callee, "call")
+	}
+
 	if !ok {
 		return nil, errors.RuntimeError{Msg: "Attempting to call a non-functional value."}
 	}
@@ -371,8 +564,7 @@ func (i *interpreter) VisitCallExpr(e ast.CallExpr) (interface{}, error) {
 		}
 
 		if spreadable {
-			v := v.(*value.Tuple)
-			vals = append(vals, v.Items...)
+			vals = appendSequence(vals, v.(value.Sequence))
 			continue
 		}
 
@@ -385,6 +577,12 @@ func (i *interpreter) VisitCallExpr(e ast.CallExpr) (interface{}, error) {
 		vals = append(vals, val)
 	}
 
+	return i.call(vfunc, vals)
+}
+
+// call invokes `vfunc` with arguments that have already been evaluated,
+// partially applying it if too few were supplied
+func (i *interpreter) call(vfunc value.Caller, vals []value.Value) (interface{}, error) {
 	// Check if function is being partially applied and return a new function if so
 	if len(vals) < vfunc.Arity() {
 		return vfunc.Apply(vals), nil
@@ -427,7 +625,7 @@ func (i *interpreter) VisitCallExpr(e ast.CallExpr) (interface{}, error) {
 		return nil, err
 	}
 
-	if pm, ok := callee.(*value.ProtoMethod); ok && pm.Inheritable != nil {
+	if pm, ok := vfunc.(*value.ProtoMethod); ok && pm.Inheritable != nil {
 		vl := v.(value.Value)
 		v = vl.Inherit(pm.Inheritable)
 	}
@@ -444,11 +642,27 @@ func (i *interpreter) VisitMeExpr(e ast.MeExpr) (interface{}, error) {
 }
 
 func (i *interpreter) VisitProtoExpr(e ast.ProtoExpr) (interface{}, error) {
-	ks := make([]string, len(e.MethodSet))
-	vs := map[string]value.Caller{}
-	p := &value.Proto{Keys: ks, Methods: vs}
+	p := &value.Proto{Members: map[string]value.Value{}}
+
+	parents := make([]*value.Proto, len(e.Parents))
+
+	for idx, parent := range e.Parents {
+		pv, err := i.evalNode(parent)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pp, ok := pv.(*value.Proto)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Protos can only extend other protos"}
+		}
+
+		parents[idx] = pp
+	}
 
-	for idx, m := range e.MethodSet {
+	for _, m := range e.MethodSet {
 		k, err := i.evalNode(m.K)
 
 		if err != nil {
@@ -469,23 +683,75 @@ func (i *interpreter) VisitProtoExpr(e ast.ProtoExpr) (interface{}, error) {
 
 		vf, ok := v.(*value.Function)
 
+		// Anything other than a function is stored as a plain member
 		if !ok {
-			return nil, errors.RuntimeError{Msg: "Proto function could not be converted to a function"}
+			vv, ok := v.(value.Value)
+
+			if !ok {
+				return nil, errors.RuntimeError{Msg: "Proto member could not be converted to a value"}
+			}
+
+			p.Define(kv, vv)
+			continue
 		}
 
-		pm := value.ProtoMethodFromFn(vf)
+		pm := value.ProtoMethodFromFn(vf, p)
 
 		if m.I {
 			pm.Inheritable = p
 		}
 
-		kstr := kv.Hash()
+		pm.Getter = m.G
+
+		p.Define(kv, pm)
+	}
+
+	// A single parent is linked directly while several are composed into one,
+	// with the members defined here settling any clashes between them
+	if len(parents) == 1 {
+		p.Parent = parents[0]
+	}
+
+	if len(parents) > 1 {
+		c, err := value.Compose(parents, func(k string) bool {
+			_, ok := p.Members[k]
+			return ok
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		p.Parent = c
+	}
+
+	for _, pe := range e.Protocols {
+		pv, err := i.evalNode(pe)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pc, ok := pv.(*value.Protocol)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Protos can only implement protocols"}
+		}
+
+		if k, missing := pc.MissingFrom(p); missing {
+			kv, _ := pc.KeyValue(k)
+
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("Proto does not implement protocol %q: method %s is missing or has the wrong arity", pc.Name, value.Display(kv))}
+		}
 
-		ks[idx] = kstr
-		vs[kstr] = pm
+		p.Protocols = append(p.Protocols, pc)
 	}
 
-	return &value.Proto{Keys: ks, Methods: vs}, nil
+	return p, nil
+}
+
+func (i *interpreter) VisitSuperExpr(e ast.SuperExpr) (interface{}, error) {
+	return nil, errors.RuntimeError{Msg: "'super' can only be used to access proto methods"}
 }
 
 func (i *interpreter) VisitQuestionExpr(e ast.QuestionExpr) (interface{}, error) {
@@ -533,49 +799,158 @@ func (i *interpreter) VisitRecordLitExpr(e ast.RecordLiteralExpr) (interface{},
 }
 
 func (i *interpreter) VisitGetExpr(e ast.GetExpr) (interface{}, error) {
-	gettee, err := i.evalNode(e.Gettee)
+	v, p, err := i.getTarget(e.Gettee)
+
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := i.evalNode(e.Field)
 
 	if err != nil {
 		return nil, err
 	}
 
-	v, ok := gettee.(value.Value)
+	fval, ok := f.(value.Value)
+
+	if !ok {
+		return nil, errors.RuntimeError{Msg: "Field was not a value"}
+	}
+
+	m, ok := value.Member(v, p, fval.Hash())
+
+	// Unknown fields are handed to the proto's "missing" method if it has one
+	if !ok {
+		missing, _ := value.Member(v, p, value.NewString("missing").Hash())
+		mm, ok := missing.(*value.ProtoMethod)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: fmt.Sprintf("Field %q did not exist in prototype", fval.Hash())}
+		}
+
+		return i.call(mm.Bind(v), []value.Value{fval})
+	}
+
+	// Plain members are returned as is; only methods are bound to the receiver
+	pm, ok := m.(*value.ProtoMethod)
+
+	if !ok {
+		return m, nil
+	}
+
+	if pm.Getter {
+		return i.call(pm.Bind(v), []value.Value{})
+	}
+
+	return pm.Bind(v), nil
+}
+
+// getTarget resolves the receiver of a get expression and the proto its
+// lookup starts from. `super` keeps the current `me` as the receiver but
+// starts from the parent of the proto the running method was declared in.
+func (i *interpreter) getTarget(gettee ast.Expr) (value.Value, *value.Proto, error) {
+	if _, ok := gettee.(ast.SuperExpr); ok {
+		if !i.env.HasDirectly("super") {
+			return nil, nil, errors.RuntimeError{Msg: "'super' does not exist in immediate lexical scope"}
+		}
+
+		owner, ok := i.env.Get("super").(*value.Proto)
+
+		if !ok {
+			return nil, nil, errors.RuntimeError{Msg: "'super' did not resolve to a proto"}
+		}
+
+		return i.env.Get("me"), owner.Parent, nil
+	}
+
+	g, err := i.evalNode(gettee)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v, ok := g.(value.Value)
 
 	if !ok {
-		return nil, errors.RuntimeError{Msg: "Gettee was not a value"}
+		return nil, nil, errors.RuntimeError{Msg: "Gettee was not a value"}
 	}
 
 	p := v.Proto()
 
 	if p == nil {
-		return nil, errors.RuntimeError{Msg: "Value received does not have a proto"}
+		return nil, nil, errors.RuntimeError{Msg: "Value received does not have a proto"}
 	}
 
-	f, err := i.evalNode(e.Field)
+	return v, p, nil
+}
+
+func (i *interpreter) VisitRangeExpr(e ast.RangeExpr) (interface{}, error) {
+	start, err := i.evalNode(e.Start)
 
 	if err != nil {
 		return nil, err
 	}
 
-	fval, ok := f.(value.Value)
+	end, err := i.evalNode(e.End)
 
-	if !ok {
-		return nil, errors.RuntimeError{Msg: "Field was not a value"}
+	if err != nil {
+		return nil, err
+	}
+
+	if !areNumbers(start, end) {
+		return nil, errors.RuntimeError{Msg: "Range bounds must be numbers"}
 	}
 
-	m, ok := p.Methods[fval.Hash()]
+	sv, ev := start.(value.Value), end.(value.Value)
 
-	if !ok {
-		return nil, errors.RuntimeError{Msg: fmt.Sprintf("Field %q did not exist in prototype", fval.Hash())}
+	// A range over an infinite span has no length to count items by
+	if !isFinite(sv) || !isFinite(ev) {
+		return nil, errors.RuntimeError{Msg: "Range bounds must be finite numbers"}
 	}
 
-	pm, ok := m.(*value.ProtoMethod)
+	// Ranges count down by default when the end is below the start
+	var step value.Value = value.NewInteger(1)
 
-	if !ok {
-		return nil, errors.RuntimeError{Msg: fmt.Sprintf("Field %q did not resolve to a proto method", fval.Hash())}
+	if c, _ := value.CompareNumbers(ev, sv); c < 0 {
+		step = value.NewInteger(-1)
 	}
 
-	return pm.Bind(v), nil
+	if e.Step != nil {
+		st, err := i.evalNode(e.Step)
+
+		if err != nil {
+			return nil, err
+		}
+
+		stv, ok := st.(value.Value)
+
+		if !ok || !value.IsNumeric(stv) {
+			return nil, errors.RuntimeError{Msg: "Range step must be a number"}
+		}
+
+		if c, _ := value.CompareNumbers(stv, value.NewInteger(0)); c == 0 || !isFinite(stv) {
+			return nil, errors.RuntimeError{Msg: "Range step must be a finite, non-zero number"}
+		}
+
+		step = stv
+	}
+
+	inclusive := e.Operator.Type == tokentype.DOT_DOT_EQUAL
+	si, ok1 := sv.(*value.Integer)
+	ei, ok2 := ev.(*value.Integer)
+	sti, ok3 := step.(*value.Integer)
+
+	// Integer ranges are kept exact, however far they are past the integers
+	// a float can hold
+	if ok1 && ok2 && ok3 {
+		return value.NewIntegerRange(si, ei, sti, inclusive), nil
+	}
+
+	sn, _ := value.ToFloat(sv)
+	en, _ := value.ToFloat(ev)
+	stn, _ := value.ToFloat(step)
+
+	return value.NewRange(sn, en, stn, inclusive), nil
 }
 
 func (i *interpreter) VisitVarDeclStmt(s ast.VarDeclStmt) (interface{}, error) {
@@ -648,10 +1023,10 @@ func (i *interpreter) VisitIfStmt(s ast.IfStmt) (interface{}, error) {
 	}
 
 	if cond.Value {
-		_, err = i.evalNode(s.Then)
+		v, err := i.evalNode(s.Then)
 
 		if err != nil {
-			return nil, err
+			return v, err
 		}
 
 		return nil, nil
@@ -661,10 +1036,10 @@ func (i *interpreter) VisitIfStmt(s ast.IfStmt) (interface{}, error) {
 		return nil, nil
 	}
 
-	_, err = i.evalNode(s.Else)
+	v, err := i.evalNode(s.Else)
 
 	if err != nil {
-		return nil, err
+		return v, err
 	}
 
 	return nil, nil
@@ -675,10 +1050,12 @@ func (i *interpreter) VisitBlock(s ast.Block) (interface{}, error) {
 	defer i.PopEnv()
 
 	for _, n := range s.Contents {
-		_, err := i.evalNode(n)
+		v, err := i.evalNode(n)
 
+		// Hand back the value alongside the error so that returns nested in
+		// blocks keep their value
 		if err != nil {
-			return nil, err
+			return v, err
 		}
 	}
 
@@ -718,14 +1095,22 @@ func (i *interpreter) VisitWhileStmt(s ast.WhileStmt) (interface{}, error) {
 	}
 
 	for boolCond.Value {
-		_, err := i.evalNode(s.Block)
+		v, err := i.evalNode(s.Block)
+
+		var brk errors.BreakError
 
-		if errs.Is(err, errors.BreakError{}) {
+		if errs.As(err, &brk) && targetsLoop(s.Label, brk.Label) {
 			break
 		}
 
-		if err != nil && !errs.Is(err, errors.ContinueError{}) {
-			return nil, err
+		var cont errors.ContinueError
+
+		if errs.As(err, &cont) && targetsLoop(s.Label, cont.Label) {
+			err = nil
+		}
+
+		if err != nil {
+			return v, err
 		}
 
 		cond, _ = i.evalNode(s.Condition)
@@ -735,18 +1120,269 @@ func (i *interpreter) VisitWhileStmt(s ast.WhileStmt) (interface{}, error) {
 	return nil, nil
 }
 
-func (i *interpreter) VisitBrkStmt(_ ast.BreakStmt) (interface{}, error) {
-	return nil, errors.BreakError{}
+func (i *interpreter) VisitLoopExpr(e ast.LoopExpr) (interface{}, error) {
+	for {
+		v, err := i.evalNode(e.Body)
+
+		var brk errors.BreakError
+
+		if errs.As(err, &brk) && targetsLoop(e.Label, brk.Label) {
+			if brk.Value == nil {
+				return &value.Bottom{}, nil
+			}
+
+			return brk.Value, nil
+		}
+
+		var cont errors.ContinueError
+
+		if errs.As(err, &cont) && targetsLoop(e.Label, cont.Label) {
+			continue
+		}
+
+		if err != nil {
+			return v, err
+		}
+	}
+}
+
+func (i *interpreter) VisitBrkStmt(s ast.BreakStmt) (interface{}, error) {
+	brk := errors.BreakError{}
+
+	if s.Label != nil {
+		brk.Label = s.Label.Lexeme
+	}
+
+	if s.Expr != nil {
+		v, err := i.evalNode(s.Expr)
+
+		if err != nil {
+			return nil, err
+		}
+
+		brk.Value = v
+	}
+
+	return nil, brk
+}
+
+func (i *interpreter) VisitContStmt(s ast.ContinueStmt) (interface{}, error) {
+	cont := errors.ContinueError{}
+
+	if s.Label != nil {
+		cont.Label = s.Label.Lexeme
+	}
+
+	return nil, cont
+}
+
+func (i *interpreter) VisitDeferStmt(s ast.DeferStmt) (interface{}, error) {
+	// Capture the current scope so the expression sees the same bindings when
+	// it is eventually evaluated
+	i.defers.Peek().Push(deferred{expr: s.Expr, env: i.env})
+
+	return nil, nil
+}
+
+// VisitYieldStmt is only reached when a yield is evaluated outside of the
+// generator running it, since generators pause at yields themselves
+func (i *interpreter) VisitYieldStmt(s ast.YieldStmt) (interface{}, error) {
+	return nil, errors.RuntimeError{Msg: "Yield can only be used in the statements of a generator body"}
+}
+
+func (i *interpreter) VisitThrowStmt(s ast.ThrowStmt) (interface{}, error) {
+	v, err := i.evalNode(s.Expr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Error values that were previously caught can be re-thrown as is
+	if ev, ok := v.(*value.Error); ok && s.Data == nil {
+		return nil, throw(ev)
+	}
+
+	msg, ok := v.(*value.String)
+
+	if !ok {
+		return nil, errors.RuntimeError{Msg: "Thrown value must be a string message or an error"}
+	}
+
+	var data value.Value = &value.Bottom{}
+
+	if s.Data != nil {
+		d, err := i.evalNode(s.Data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data, ok = d.(value.Value)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Did not receive a value for thrown error's data"}
+		}
+	}
+
+	return nil, throw(value.NewError(msg.Value, data, &s.Token))
+}
+
+func (i *interpreter) VisitAssertStmt(s ast.AssertStmt) (interface{}, error) {
+	if !i.asserts {
+		return nil, nil
+	}
+
+	var cond interface{}
+	var operands []value.Value
+	var err error
+
+	// Comparisons have their operands evaluated separately so they can be
+	// reported if the assertion fails
+	if bin, ok := unwrapGrouping(s.Expr).(ast.BinaryExpr); ok && isComparisonOp(bin.Operator.Type) {
+		l, err := i.evalNode(bin.Left)
+
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := i.evalNode(bin.Right)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cond, err = i.binaryOp(bin.Operator, l, r)
+
+		if err != nil {
+			return nil, err
+		}
+
+		lv, okl := l.(value.Value)
+		rv, okr := r.(value.Value)
+
+		if okl && okr {
+			operands = []value.Value{lv, rv}
+		}
+	} else {
+		cond, err = i.evalNode(s.Expr)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b, ok := cond.(*value.Boolean)
+
+	if !ok {
+		return nil, errors.RuntimeError{Msg: "Assertion must resolve to a boolean value"}
+	}
+
+	if b.Value {
+		return nil, nil
+	}
+
+	msg := fmt.Sprintf("Assertion `%s` failed", s.Source)
+
+	if s.Message != nil {
+		m, err := i.evalNode(s.Message)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ms, ok := m.(*value.String)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Assertion message must be a string"}
+		}
+
+		msg = fmt.Sprintf("%s: %s", msg, ms.Value)
+	}
+
+	var data value.Value = &value.Bottom{}
+
+	if operands != nil {
+		msg = fmt.Sprintf("%s (left: %s, right: %s)", msg, i.display(operands[0]), i.display(operands[1]))
+		data = value.NewTuple(operands)
+	}
+
+	return nil, throw(value.NewError(msg, data, &s.Token))
+}
+
+func (i *interpreter) VisitProtocolStmt(s ast.ProtocolStmt) (interface{}, error) {
+	ks := make([]value.Value, len(s.Methods))
+	arities := make([]int, len(s.Methods))
+
+	for idx, m := range s.Methods {
+		k, err := i.evalNode(m.K)
+
+		if err != nil {
+			return nil, err
+		}
+
+		kv, ok := k.(value.Value)
+
+		if !ok {
+			return nil, errors.RuntimeError{Msg: "Protocol key could not be converted to a value"}
+		}
+
+		n, err := strconv.ParseUint(m.Arity.Lexeme, 10, 64)
+
+		if err != nil {
+			return nil, errors.RuntimeError{Msg: "Protocol method arity must be a non-negative integer"}
+		}
+
+		ks[idx], arities[idx] = kv, int(n)
+	}
+
+	i.env.Add(s.Name.Lexeme, value.NewProtocol(s.Name.Lexeme, ks, arities))
+
+	return nil, nil
 }
 
-func (i *interpreter) VisitContStmt(_ ast.ContinueStmt) (interface{}, error) {
-	return nil, errors.ContinueError{}
+func (i *interpreter) VisitTryStmt(s ast.TryStmt) (interface{}, error) {
+	v, err := i.evalNode(s.Body)
+
+	if err == nil {
+		return nil, nil
+	}
+
+	ev, ok := catchable(err)
+
+	if !ok {
+		return v, err
+	}
+
+	i.PushEnv(nil)
+	defer i.PopEnv()
+
+	if s.Name != nil {
+		i.env.Add(s.Name.Lexeme, ev)
+	}
+
+	v, err = i.evalNode(s.Catch)
+
+	if err != nil {
+		return v, err
+	}
+
+	return nil, nil
 }
 
-func New() *interpreter {
-	return &interpreter{
-		env: environment.New[value.Value](nil),
+func New(opts ...Option) *interpreter {
+	i := &interpreter{
+		env:     environment.New[value.Value](nil),
+		defers:  stack.New[*stack.Stack[deferred]](),
+		asserts: true,
+		clock:   time.Now,
+		regexes: map[tokens.Token]*value.Regex{},
+	}
+
+	for _, opt := range opts {
+		opt(i)
 	}
+
+	return i
 }
 
 type IntpState = struct {
@@ -773,3 +1409,72 @@ func (i *interpreter) PopEnv() {
 func (i *interpreter) AddEnv(k string, v value.Value) {
 	i.env.Add(k, v)
 }
+
+// Now reads the interpreter's clock, the system clock unless the host set one
+// with `WithClock`
+func (i *interpreter) Now() time.Time {
+	return i.clock()
+}
+
+// Call invokes `fn` with `args` from inside a built-in method. The built-in
+// method's own scope is skipped so that callbacks closing over their
+// environment see the scope the built-in method was called from.
+func (i *interpreter) Call(fn value.Value, args []value.Value) (value.Value, error) {
+	vfunc, ok := fn.(value.Caller)
+
+	if !ok {
+		vfunc, ok = operatorMethod(fn, "call")
+	}
+
+	if !ok {
+		return nil, errors.RuntimeError{Msg: fmt.Sprintf("Expected a function, got %s", i.display(fn))}
+	}
+
+	env := i.env
+
+	if env.Parent != nil {
+		i.env = env.Parent
+	}
+
+	defer func() { i.env = env }()
+
+	v, err := i.call(vfunc, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vl, ok := v.(value.Value)
+
+	if !ok {
+		return nil, errors.RuntimeError{Msg: "Function did not return a value"}
+	}
+
+	return vl, nil
+}
+
+func (i *interpreter) PushDefers() {
+	i.defers.Push(stack.New[deferred]())
+}
+
+// RunDefers evaluates the current call's deferred expressions in LIFO order.
+// An error the call already exited with takes precedence over any raised by
+// the deferred expressions; otherwise the first deferred error is returned.
+func (i *interpreter) RunDefers(err error) error {
+	ds := i.defers.Pop()
+	env := i.env
+	defer func() { i.env = env }()
+
+	for ds.Size() > 0 {
+		d := ds.Pop()
+		i.env = d.env
+
+		_, derr := i.evalNode(d.expr)
+
+		if err == nil {
+			err = derr
+		}
+	}
+
+	return err
+}
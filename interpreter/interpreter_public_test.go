@@ -2,6 +2,7 @@ package interpreter_test
 
 import (
 	"calabash/ast"
+	cerrors "calabash/errors"
 	"calabash/internal/tokentype"
 	"calabash/internal/value"
 	"calabash/interpreter"
@@ -11,8 +12,11 @@ import (
 	staticanalyzer "calabash/static_analyzer"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestEval(t *testing.T) {
@@ -48,7 +52,7 @@ func TestEval(t *testing.T) {
 				name: "literal number 1",
 				text: "123",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(123)) {
+					if !reflect.DeepEqual(v, value.NewInteger(123)) {
 						return errors.New("Values does not equal 123")
 					}
 
@@ -172,15 +176,15 @@ func TestEval(t *testing.T) {
 						return errors.New("Literal value was not a tuple")
 					}
 
-					if !reflect.DeepEqual(tuple.Items[0], value.NewNumber(1)) {
+					if !reflect.DeepEqual(tuple.At(0), value.NewInteger(1)) {
 						return errors.New("First tuple item is not equal to 1")
 					}
 
-					if !reflect.DeepEqual(tuple.Items[1], value.NewString("a")) {
+					if !reflect.DeepEqual(tuple.At(1), value.NewString("a")) {
 						return errors.New("Second tuple item is not equal to \"a\"")
 					}
 
-					fn, ok := tuple.Items[2].(*value.Function)
+					fn, ok := tuple.At(2).(*value.Function)
 
 					if !ok {
 						return errors.New("Third tuple item is not a function")
@@ -206,7 +210,7 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a tuple")
 					}
 
-					if len(tpl.Items) != 0 {
+					if tpl.Len() != 0 {
 						return errors.New("Tuple should be empty")
 					}
 
@@ -223,7 +227,7 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a record")
 					}
 
-					if len(rec.Entries) > 0 {
+					if rec.Len() > 0 {
 						return errors.New("Record should be empty")
 					}
 
@@ -240,58 +244,58 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a record")
 					}
 
-					if len(rec.Entries) != 7 {
+					if rec.Len() != 7 {
 						return errors.New("Record should have seven properties")
 					}
 
 					v1 := value.NewString("a")
 					v2 := &value.Bottom{}
-					v3 := value.NewNumber(5)
+					v3 := value.NewInteger(5)
 					v4 := value.NewBoolean(true)
-					v5 := value.NewTuple([]value.Value{value.NewNumber(1)})
+					v5 := value.NewTuple([]value.Value{value.NewInteger(1)})
 					v6 := value.NewRecord([]struct {
 						K value.Value
 						V value.Value
 					}{})
-					v7 := value.NewNumber(10)
+					v7 := value.NewInteger(10)
 
-					if v, ok := rec.Entries[v1.Hash()]; !ok {
+					if v, ok := rec.Get(v1); !ok {
 						return errors.New("Record does not contain key 'a'")
-					} else if !reflect.DeepEqual(v, value.NewNumber(1)) {
+					} else if !reflect.DeepEqual(v, value.NewInteger(1)) {
 						return errors.New("Record property 'a' was not assigned the value 1")
 					}
 
-					if v, ok := rec.Entries[v2.Hash()]; !ok {
+					if v, ok := rec.Get(v2); !ok {
 						return errors.New("Record does not contain key bottom")
-					} else if !reflect.DeepEqual(v, value.NewNumber(2)) {
+					} else if !reflect.DeepEqual(v, value.NewInteger(2)) {
 						return errors.New("Record property bottom was not assigned the value 2")
 					}
 
-					if v, ok := rec.Entries[v3.Hash()]; !ok {
+					if v, ok := rec.Get(v3); !ok {
 						return errors.New("Record does not contain key 5")
-					} else if !reflect.DeepEqual(v, value.NewNumber(3)) {
+					} else if !reflect.DeepEqual(v, value.NewInteger(3)) {
 						return errors.New("Record property 5 was not assigned the value 3")
 					}
 
-					if v, ok := rec.Entries[v4.Hash()]; !ok {
+					if v, ok := rec.Get(v4); !ok {
 						return errors.New("Record does not contain key true")
-					} else if !reflect.DeepEqual(v, value.NewNumber(4)) {
+					} else if !reflect.DeepEqual(v, value.NewInteger(4)) {
 						return errors.New("Record property true was not assigned the value 4")
 					}
 
-					if v, ok := rec.Entries[v5.Hash()]; !ok {
+					if v, ok := rec.Get(v5); !ok {
 						return errors.New("Record does not contain key [1]")
-					} else if !reflect.DeepEqual(v, value.NewNumber(5)) {
+					} else if !reflect.DeepEqual(v, value.NewInteger(5)) {
 						return errors.New("Record property [1] was not assigned the value 5")
 					}
-					if v, ok := rec.Entries[v6.Hash()]; !ok {
+					if v, ok := rec.Get(v6); !ok {
 						return errors.New("Record does not contain key {}")
-					} else if !reflect.DeepEqual(v, value.NewNumber(6)) {
+					} else if !reflect.DeepEqual(v, value.NewInteger(6)) {
 						return errors.New("Record property {} was not assigned the value 6")
 					}
-					if v, ok := rec.Entries[v7.Hash()]; !ok {
+					if v, ok := rec.Get(v7); !ok {
 						return errors.New("Record does not contain key 10")
-					} else if !reflect.DeepEqual(v, value.NewNumber(7)) {
+					} else if !reflect.DeepEqual(v, value.NewInteger(7)) {
 						return errors.New("Record property 10 was not assigned the value 7")
 					}
 
@@ -308,19 +312,19 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a record")
 					}
 
-					if len(rec.Entries) != 1 {
+					if rec.Len() != 1 {
 						return errors.New("Record should have seven properties")
 					}
 
 					fn := is.Env.Get("a")
 
-					val, ok := rec.Entries[fn.Hash()]
+					val, ok := rec.Get(fn)
 
 					if !ok {
 						return errors.New("Function was not properly keyed to object")
 					}
 
-					if !reflect.DeepEqual(val, value.NewNumber(1)) {
+					if !reflect.DeepEqual(val, value.NewInteger(1)) {
 						return errors.New("Value for function key is not the number 1")
 					}
 
@@ -331,7 +335,7 @@ func TestEval(t *testing.T) {
 				name: "literal record proto 'get'",
 				text: "{ 'a' -> 1 }->'get'('a')",
 				validate: func(v interface{}, is interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(1)) {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
 						return errors.New("Did not properly get value for key 'a'")
 					}
 
@@ -357,10 +361,10 @@ func TestEval(t *testing.T) {
 				validate: func(v interface{}, is interpreter.IntpState) error {
 					tpl := value.NewTuple(
 						[]value.Value{
-							value.NewNumber(1),
+							value.NewInteger(1),
 							value.NewBoolean(true),
 							value.NewBoolean(false),
-							value.NewNumber(2),
+							value.NewInteger(2),
 						},
 					)
 
@@ -375,18 +379,239 @@ func TestEval(t *testing.T) {
 				name: "function calls can be spreadable",
 				text: "let a = fn () -> [1]; [a()...]",
 				validate: func(v interface{}, is interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewNumber(1)})) {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1)})) {
 						return errors.New("Result of function call did not spread properly")
 					}
 
 					return nil
 				},
 			},
+			{
+				name: "range expressions are lazy",
+				text: "0..1000000",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					r := value.NewIntegerRange(value.NewInteger(0), value.NewInteger(1000000), value.NewInteger(1), false)
+
+					if !reflect.DeepEqual(v, r) {
+						return errors.New("Range should only hold its bounds and step")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "range expressions can be spread into tuples",
+				text: "[(0..3)...]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(0), value.NewInteger(1), value.NewInteger(2)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Contents of tuple should be `0`, `1`, `2`")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "inclusive range expressions include their end",
+				text: "[(1..=3)...]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2), value.NewInteger(3)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Contents of tuple should be `1`, `2`, `3`")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "range expressions can have a step",
+				text: "[(0..=10 step 5)...]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(0), value.NewInteger(5), value.NewInteger(10)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Contents of tuple should be `0`, `5`, `10`")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "range expressions count down when end is below start",
+				text: "[(3..0)...]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(3), value.NewInteger(2), value.NewInteger(1)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Contents of tuple should be `3`, `2`, `1`")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "range expressions with a step against their direction are empty",
+				text: "[(0..10 step -1)...]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{})) {
+						return errors.New("Tuple should be empty")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "range expressions can be spread into function calls",
+				text: "(fn (a, b, c) -> a + b + c)((1..=3)...)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(6)) {
+						return errors.New("Range did not spread into arguments list")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer ranges stay exact past the integers a float can hold",
+				text: "let r = 9007199254740993..9007199254740996; r->'toTuple'()->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[9007199254740993, 9007199254740994, 9007199254740995]")) {
+						return fmt.Errorf("Range items past 2^53 should stay exact, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the length of an integer range past 2^53",
+				text: "let r = 9007199254740993..9007199254740996; r->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
+						return fmt.Errorf("'len' should count exactly, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer ranges past 2^53 contain their items",
+				text: "let r = 9007199254740993..9007199254740996; r->'contains'(9007199254740995)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'contains' should find an item exactly, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer ranges past 2^53 do not contain the integer below",
+				text: "let r = 9007199254740993..9007199254740996; r->'contains'(9007199254740992)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("'contains' should not round the bounds, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "spreading a stepped integer range past 2^53",
+				text: "[(9007199254740993..=9007199254740999 step 3)...]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[9007199254740993, 9007199254740996, 9007199254740999]")) {
+						return fmt.Errorf("Spread items should stay exact, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "stringifying an integer range past 2^53",
+				text: "(9007199254740993..=9007199254740999 step 3)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("9007199254740993..=9007199254740999 step 3")) {
+						return fmt.Errorf("The bounds should be rendered exactly, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer and float ranges are unequal",
+				text: "(0..3) == (0.0..3.0)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Ranges of different kinds should be unequal, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer ranges with a negative step",
+				text: "(0..=-6 step -3)->'toTuple'()->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[0, -3, -6]")) {
+						return fmt.Errorf("Negative steps should count down, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "proto method tests: Range->'len'",
+				text: "(0..100 step 5)->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(20)) {
+						return errors.New("Range should have 20 items")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "proto method tests: Range->'contains' 1",
+				text: "(0..100 step 5)->'contains'(95)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return errors.New("Range should contain 95")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "proto method tests: Range->'contains' 2",
+				text: "(0..100 step 5)->'contains'(100)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return errors.New("Exclusive range should not contain its end")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "proto method tests: Range->'toTuple'",
+				text: "(1..=2)->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Contents of tuple should be `1`, `2`")
+					}
+
+					return nil
+				},
+			},
 			{
 				name: "binary addition 1",
 				text: "1 + 1",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(2)) {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
 						return errors.New("Values does not equal 2")
 					}
 
@@ -408,7 +633,7 @@ func TestEval(t *testing.T) {
 				name: "binary subtraction",
 				text: "2 - 5",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(-3)) {
+					if !reflect.DeepEqual(v, value.NewInteger(-3)) {
 						return errors.New("Values does not equal -3")
 					}
 
@@ -419,7 +644,7 @@ func TestEval(t *testing.T) {
 				name: "binary multiplication",
 				text: "2 * 5",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(10)) {
+					if !reflect.DeepEqual(v, value.NewInteger(10)) {
 						return errors.New("Values does not equal 10")
 					}
 
@@ -441,7 +666,7 @@ func TestEval(t *testing.T) {
 				name: "binary exponentiation",
 				text: "5 ** 2",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(25)) {
+					if !reflect.DeepEqual(v, value.NewInteger(25)) {
 						return errors.New("Values does not equal 25")
 					}
 
@@ -595,7 +820,7 @@ func TestEval(t *testing.T) {
 				name: "grouping expression",
 				text: "(5 ** 2)",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(25)) {
+					if !reflect.DeepEqual(v, value.NewInteger(25)) {
 						return errors.New("Values does not equal 25")
 					}
 
@@ -606,7 +831,7 @@ func TestEval(t *testing.T) {
 				name: "pipe expression 1",
 				text: "1 |> ? + 1",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(2)) {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
 						return errors.New("Pipe expression did not properly resolve value for '?'")
 					}
 
@@ -617,7 +842,7 @@ func TestEval(t *testing.T) {
 				name: "pipe expression 2",
 				text: "1 |> ? + 1 |> 3 ** ?",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(9)) {
+					if !reflect.DeepEqual(v, value.NewInteger(9)) {
 						return errors.New("Pipe expression did not properly resolve value for chains of '?'")
 					}
 
@@ -628,7 +853,7 @@ func TestEval(t *testing.T) {
 				name: "pipe expression can properly nest",
 				text: "1 |> ? + (2 |> ? - 1) |> 3 * ?",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(6)) {
+					if !reflect.DeepEqual(v, value.NewInteger(6)) {
 						return errors.New("Could not properly resolve nested '?'")
 					}
 
@@ -639,7 +864,7 @@ func TestEval(t *testing.T) {
 				name: "container values can be composed from '?'",
 				text: "1 |> [?, ? + 1]",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewNumber(1), value.NewNumber(2)})) {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})) {
 						return errors.New("Could not properly build tuple from '?'")
 					}
 
@@ -663,7 +888,7 @@ func TestEval(t *testing.T) {
 				name: "unary minus",
 				text: "-5",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(-5)) {
+					if !reflect.DeepEqual(v, value.NewInteger(-5)) {
 						return errors.New("Values does not equal -5")
 					}
 
@@ -708,11 +933,11 @@ func TestEval(t *testing.T) {
 				name: "multi variable declaration (with init) 1",
 				text: "let a, b = 1, 3 + 4;",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(1)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
 						return errors.New("Variable 'a' was not set to value 1")
 					}
 
-					if !reflect.DeepEqual(i.Env.Get("b"), value.NewNumber(7)) {
+					if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(7)) {
 						return errors.New("Variable 'b' was not resolved to value 7")
 					}
 
@@ -723,7 +948,7 @@ func TestEval(t *testing.T) {
 				name: "multi variable declaration (with init) 2",
 				text: "let a, b = 1, a;",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					n := value.NewNumber(1)
+					n := value.NewInteger(1)
 
 					if !reflect.DeepEqual(i.Env.Get("a"), n) {
 						return errors.New("Variable 'a' was not set to value 1")
@@ -755,7 +980,7 @@ func TestEval(t *testing.T) {
 				name: "functions should be called when their arguments list equal their arity",
 				text: "let a = fn (a, b) -> a + b; a(1, 2)",
 				validate: func(v interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(3)) {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
 						return errors.New("Function was not properly called")
 					}
 
@@ -776,7 +1001,7 @@ func TestEval(t *testing.T) {
 						return errors.New("Arity was not updated for a partially applied function")
 					}
 
-					if !reflect.DeepEqual(fn.Apps, []value.Value{value.NewNumber(1)}) {
+					if !reflect.DeepEqual(fn.Apps, []value.Value{value.NewInteger(1)}) {
 						return errors.New("Function applied arguments were not evaluated properly")
 					}
 
@@ -787,7 +1012,7 @@ func TestEval(t *testing.T) {
 				name: "partially applied functions are callable",
 				text: "let a = fn (a, b) -> a + b; let b = a(1); b(2)",
 				validate: func(v interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(3)) {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
 						return errors.New("Partially applied arguments were not stored properly")
 					}
 
@@ -809,7 +1034,7 @@ func TestEval(t *testing.T) {
 				name: "functions can be applied with more arguments than arity",
 				text: "let a = fn (a, b) -> a + b; a(1,2,3)",
 				validate: func(v interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(3)) {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
 						return errors.New("Extra arguments should be discarded when functions are called")
 					}
 
@@ -833,7 +1058,7 @@ func TestEval(t *testing.T) {
 				name: "functions with rest param should be called if 'all but rest param' are applied",
 				text: "let a = fn(a, b, ...c) -> 1; a(1, 2)",
 				validate: func(v interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(1)) {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
 						return errors.New("rest param was not ignored as far as function application is concerned")
 					}
 
@@ -844,7 +1069,7 @@ func TestEval(t *testing.T) {
 				name: "functions with rest param wrap extra args into a tuple 1",
 				text: "let a = fn(...a) -> a; a(1,2,3)",
 				validate: func(v interface{}, i interpreter.IntpState) error {
-					expected := value.NewTuple([]value.Value{value.NewNumber(1), value.NewNumber(2), value.NewNumber(3)})
+					expected := value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2), value.NewInteger(3)})
 					if !reflect.DeepEqual(v, expected) {
 						return errors.New("rest param did not tuple-ize values")
 					}
@@ -856,7 +1081,7 @@ func TestEval(t *testing.T) {
 				name: "functions with rest param wrap extra args into a tuple 2",
 				text: "let a = fn(a, ...b) -> b; a(1,2,3)",
 				validate: func(v interface{}, i interpreter.IntpState) error {
-					expected := value.NewTuple([]value.Value{value.NewNumber(2), value.NewNumber(3)})
+					expected := value.NewTuple([]value.Value{value.NewInteger(2), value.NewInteger(3)})
 					if !reflect.DeepEqual(v, expected) {
 						return errors.New("rest param did not tuple-ize 'rest' values")
 					}
@@ -894,7 +1119,7 @@ func TestEval(t *testing.T) {
 				name: "iife 1",
 				text: "fn(a){ return a + 1; }(1)",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(2)) {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
 						return errors.New("Function expression was not immediately invoked")
 					}
 
@@ -905,7 +1130,7 @@ func TestEval(t *testing.T) {
 				name: "iife 2",
 				text: "(fn(a) -> a + 1)(1)",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(2)) {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
 						return errors.New("Function expression was not immediately invoked")
 					}
 
@@ -916,7 +1141,7 @@ func TestEval(t *testing.T) {
 				name: "functions can have tuples spread into them",
 				text: "let a, b = fn (a, b) -> a + b, [1, 2]; a(b...)",
 				validate: func(v interface{}, is interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(3)) {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
 						return errors.New("Tuple was not properly spread into function call")
 					}
 
@@ -944,7 +1169,7 @@ func TestEval(t *testing.T) {
 				name: "function calls can be over-applied with spreading",
 				text: "let a, b = fn (a) -> a + 1, [1,2,3]; a(b...)",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(2)) {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
 						return errors.New("Over-applied function did not call correctly")
 					}
 
@@ -955,7 +1180,7 @@ func TestEval(t *testing.T) {
 				name: "functions with full closure can access any variables",
 				text: "let a, mut z = 1, bottom; if true { let b = 2; if true { z = (fn<> () -> a + b)(); } } z",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(3)) {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
 						return errors.New("Full closure did not properly reference all the variables")
 					}
 
@@ -984,7 +1209,7 @@ func TestEval(t *testing.T) {
 				name: "functions with limited closure can access any variable in their scope",
 				text: "let mut a = 1; if true { let b = 2; if true { a = (fn<3> () -> 3 + b)(); } } a",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(5)) {
+					if !reflect.DeepEqual(v, value.NewInteger(5)) {
 						return errors.New("Full closure did not properly reference all the variables")
 					}
 
@@ -995,7 +1220,7 @@ func TestEval(t *testing.T) {
 				name: "spread values can be combined with rest params",
 				text: "let a, b = fn(a, ...b) -> b, [1,2,3]; a(b...)",
 				validate: func(v interface{}, is interpreter.IntpState) error {
-					tpl := value.NewTuple([]value.Value{value.NewNumber(2), value.NewNumber(3)})
+					tpl := value.NewTuple([]value.Value{value.NewInteger(2), value.NewInteger(3)})
 					if !reflect.DeepEqual(v, tpl) {
 						return errors.New("Spread argument did not get combined into rest param")
 					}
@@ -1009,9 +1234,9 @@ func TestEval(t *testing.T) {
 				validate: func(v interface{}, is interpreter.IntpState) error {
 					tpl := value.NewTuple(
 						[]value.Value{
-							value.NewNumber(2),
-							value.NewNumber(3),
-							value.NewNumber(4),
+							value.NewInteger(2),
+							value.NewInteger(3),
+							value.NewInteger(4),
 							value.NewBoolean(false),
 						},
 					)
@@ -1033,11 +1258,11 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a proto value")
 					}
 
-					if len(p.Methods) != 1 {
+					if len(p.Members) != 1 {
 						return errors.New("Proto method set is not of size 1")
 					}
 
-					_, ok = p.Methods["s:\"a\""]
+					_, ok = p.Members["s:\"a\""]
 
 					if !ok {
 						return errors.New("Method in method set is not keyed by 'a'")
@@ -1117,11 +1342,11 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a tuple for variable 'b'")
 					}
 
-					if len(atpl.Items) != 0 {
+					if atpl.Len() != 0 {
 						return errors.New("Tuple was mutated inadvertently")
 					}
 
-					if len(btpl.Items) != 1 {
+					if btpl.Len() != 1 {
 						return errors.New("Tuple was incorrectly appended to")
 					}
 
@@ -1147,19 +1372,19 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a tuple for variable 'c'")
 					}
 
-					if len(btpl.Items) != 1 || len(ctpl.Items) != 1 {
+					if btpl.Len() != 1 || ctpl.Len() != 1 {
 						return errors.New("Tuples were not given the right number of arguments")
 					}
 
-					n := value.NewNumber(1)
+					n := value.NewInteger(1)
 
-					if !reflect.DeepEqual(btpl.Items[0], n) {
+					if !reflect.DeepEqual(btpl.At(0), n) {
 						return errors.New("Tuple 'b' should only have value 1 inside")
 					}
 
-					n.Value = 2
+					n = value.NewInteger(2)
 
-					if !reflect.DeepEqual(ctpl.Items[0], n) {
+					if !reflect.DeepEqual(ctpl.At(0), n) {
 						return errors.New("Tuple 'b' should only have value 2 inside")
 					}
 
@@ -1176,19 +1401,19 @@ func TestEval(t *testing.T) {
 						return errors.New("Did not receive a tuple")
 					}
 
-					if len(tpl.Items) != 2 {
+					if tpl.Len() != 2 {
 						return errors.New("Tuple should have exactly two elements")
 					}
 
-					n := value.NewNumber(1)
+					n := value.NewInteger(1)
 
-					if !reflect.DeepEqual(tpl.Items[0], n) {
+					if !reflect.DeepEqual(tpl.At(0), n) {
 						return errors.New("First element should be 1 for tuple")
 					}
 
-					n.Value = 2
+					n = value.NewInteger(2)
 
-					if !reflect.DeepEqual(tpl.Items[1], n) {
+					if !reflect.DeepEqual(tpl.At(1), n) {
 						return errors.New("Second element should be 2 for tuple")
 					}
 
@@ -1199,7 +1424,7 @@ func TestEval(t *testing.T) {
 				name: "protos can access closed variables",
 				text: "let a, b = 1, true < proto { 'abc' -> fn<> () -> a }; b->'abc'()",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(1)) {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
 						return errors.New("proto method could not access outer scope")
 					}
 
@@ -1239,11 +1464,147 @@ func TestEval(t *testing.T) {
 					return nil
 				},
 			},
+			{
+				name: "every value has a canonical stringify",
+				text: "[1, 2.0, 'a', bottom, { 'k' -> [true] }, 0..3, fn (a, ...b) -> a]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[1, 2.0, 'a', bottom, { 'k' -> [true] }, 0..3, fn (a, ...b)]")) {
+						return fmt.Errorf("Unexpected rendering %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "strings stringify to themselves",
+				text: "'a'->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("a")) {
+						return fmt.Errorf("Unexpected rendering %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "stringify uses overrides for nested values and super for the canonical format",
+				text: "let P = proto { 'stringify' -> fn () -> '<' + super->'stringify'() + '>' }; [({} < P)]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[<{} < proto { 'stringify' -> fn () }>]")) {
+						return fmt.Errorf("Unexpected rendering %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "stringify shows partially applied functions by the parameters they still expect",
+				text: "let f = fn (a, b, ...c) -> a; f(1)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("fn (b, ...c)")) {
+						return fmt.Errorf("Unexpected rendering %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "stringify does not follow closures into self-referential records",
+				text: "let mut r = {}; let g = fn<> () -> r; r = { 'g' -> g }; r->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("{ 'g' -> fn () }")) {
+						return fmt.Errorf("Unexpected rendering %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record keys and equality are structural across numeric kinds",
+				text: "let r = { [1, { 'a' -> 2 }] -> 'x' }; [r->'get'([1.0, { 'a' -> 2.0 }]), [1, 2] == [1.0, 2], { 'a' -> 1, 'b' -> 2 } == { 'b' -> 2, 'a' -> 1 }]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					want := value.NewTuple([]value.Value{value.NewString("x"), value.NewBoolean(true), value.NewBoolean(true)})
+
+					if vl, ok := v.(value.Value); !ok || !vl.Equal(want) {
+						return fmt.Errorf("Expected %v, got %v", want, v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set literals hold distinct elements",
+				text: "#{1, [1, 2], 1.0, [1.0, 2]}->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
+						return fmt.Errorf("Equal elements should be held once, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set equality ignores insertion order",
+				text: "#{1, [1, 2]} == #{[1, 2], 1}",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Sets with the same elements should be equal, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set literals spread iterables",
+				text: "#{2, [3, 1]..., (0..2)...}->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{2, 3, 1, 0}")) {
+						return fmt.Errorf("Spread elements should be added in order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "sets can be spread",
+				text: "[#{'a', 'b'}...]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("['a', 'b']")) {
+						return fmt.Errorf("Spreading a set should yield its elements in insertion order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "sets are equal record keys regardless of order",
+				text: "{ #{1, 2} -> 'x' }->'get'(#{2, 1})",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("x")) {
+						return fmt.Errorf("Equal sets should find the same key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "a method accessed twice on a value is the same function, unlike partial applications",
+				text: "let P = proto { 'm' -> fn () -> 1 }; let v = {} < P; let f = fn (a, b) -> a; [v->'m' == v->'m', f == f, f(1) == f(1)]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					want := value.NewTuple([]value.Value{value.NewBoolean(true), value.NewBoolean(true), value.NewBoolean(false)})
+
+					if vl, ok := v.(value.Value); !ok || !vl.Equal(want) {
+						return fmt.Errorf("Expected %v, got %v", want, v)
+					}
+
+					return nil
+				},
+			},
 			{
 				name: "values can have their prototype reassigned",
 				text: "let p, v = proto { 'inc' -> fn () -> me + 1 }, 3 < p; v->'inc'()",
 				validate: func(v interface{}, _ interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(4)) {
+					if !reflect.DeepEqual(v, value.NewInteger(4)) {
 						return errors.New("value `p` did not increment correctly")
 					}
 
@@ -1254,14 +1615,14 @@ func TestEval(t *testing.T) {
 				name: "values reassigning prototypes do not change that of original value",
 				text: "let p, a, b = proto { 'inc' -> fn () -> me + 1 }, 3, a < p; a",
 				validate: func(v interface{}, is interpreter.IntpState) error {
-					val, ok := v.(*value.Number)
+					val, ok := v.(*value.Integer)
 
 					if !ok {
-						return errors.New("Value should have been a number")
+						return errors.New("Value should have been an integer")
 					}
 
 					p := val.Proto().Hash()
-					numberP := value.ProtoNumber.Hash()
+					numberP := value.ProtoInteger.Hash()
 
 					if p != numberP {
 						return errors.New("Number had its proto erroneously re-assigned")
@@ -1274,7 +1635,7 @@ func TestEval(t *testing.T) {
 				name: "values with reassigned prototypes do not impact original value",
 				text: "let p, a, b, c = proto { 'inc' -> fn () -> me + 1 }, 3, a < p, b->'inc'(); a",
 				validate: func(v interface{}, is interpreter.IntpState) error {
-					if !reflect.DeepEqual(v, value.NewNumber(3)) {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
 						return errors.New("value `c` should not have incremented `a`")
 					}
 
@@ -1285,17 +1646,17 @@ func TestEval(t *testing.T) {
 				name: "prototype methods can autoinherit",
 				text: "let p, a = proto { 'inc' ->< fn () -> me + 1 }, 3 < p; a->'inc'()->'inc'()",
 				validate: func(v interface{}, is interpreter.IntpState) error {
-					vn, ok := v.(*value.Number)
+					vn, ok := v.(*value.Integer)
 
 					if !ok {
-						return errors.New("value `a` is not fundamentally a number")
+						return errors.New("value `a` is not fundamentally an integer")
 					}
 
-					if vn.Value != 5 {
+					if vn.Hash() != value.NewInteger(5).Hash() {
 						return errors.New("value `a` is not wrapping the value `5`")
 					}
 
-					n := value.NewNumber(5)
+					n := value.NewInteger(5)
 
 					if vn.Proto() == n.Proto() {
 						return errors.New("value `a` should have a different prototype than NumberProto")
@@ -1308,7 +1669,7 @@ func TestEval(t *testing.T) {
 				name: "assign statement 1",
 				text: "let mut a; a = 4;",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(4)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(4)) {
 						return errors.New("Variable \"a\" was not properly assigned the value 4")
 					}
 
@@ -1319,11 +1680,11 @@ func TestEval(t *testing.T) {
 			// 	name: "assign statement 2",
 			// 	text: "let mut a, mut b = 1, 2; a, b = b, a;",
 			// 	validate: func(_ interface{}, i interpreter.IntpState) error {
-			// 		if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(2)) {
+			// 		if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(2)) {
 			// 			return errors.New("Variable \"a\" was not properly assigned \"b\"'s value 2")
 			// 		}
 
-			// 		if !reflect.DeepEqual(i.Env.Get("b"), value.NewNumber(1)) {
+			// 		if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(1)) {
 			// 			return errors.New("Variable \"b\" was not properly assigned \"a\"'s value 1")
 			// 		}
 
@@ -1334,7 +1695,7 @@ func TestEval(t *testing.T) {
 				name: "if statement (no init) enters then block",
 				text: "let mut a; if true { a = 1; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(1)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
 						return errors.New("The `then` block in the if statement was not entered for a true value")
 					}
 
@@ -1345,7 +1706,7 @@ func TestEval(t *testing.T) {
 				name: "if statement (no init) enters else block",
 				text: "let mut a; if false { a = 1; } else { a = 2; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(2)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(2)) {
 						return errors.New("The `else` block in the if statement was not entered for a false value")
 					}
 
@@ -1367,7 +1728,7 @@ func TestEval(t *testing.T) {
 				name: "if statement (no init) generates new scope for each block",
 				text: "let a = 1; if true { let a = 2; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(1)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
 						return errors.New("Outer variable \"a\" should not have been reassigned")
 					}
 
@@ -1378,7 +1739,7 @@ func TestEval(t *testing.T) {
 				name: "if statement (with init) shadows outer variables",
 				text: "let a = 1; if let mut a = 2; true { a = 3; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(1)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
 						return errors.New("Outer variable \"a\" should have been shadowed")
 					}
 
@@ -1389,7 +1750,7 @@ func TestEval(t *testing.T) {
 				name: "else statement can reference initialized if variables",
 				text: "let mut b; if let a = 2; false {} else { b = a; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("b"), value.NewNumber(2)) {
+					if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(2)) {
 						return errors.New("Else branches should be able to access variables declared in `if` blocks")
 					}
 
@@ -1400,7 +1761,7 @@ func TestEval(t *testing.T) {
 				name: "nested if statements should be able to access outer if statements variables",
 				text: "let mut b; if let a = 2; false {} else if true { b = a; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("b"), value.NewNumber(2)) {
+					if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(2)) {
 						return errors.New("Nested if statements should be able to access outer if statements' variables")
 					}
 
@@ -1411,7 +1772,7 @@ func TestEval(t *testing.T) {
 				name: "nested if statements should be able to shadow outer if statements variables",
 				text: "let mut b; if let a = 2; false {} else if let a = 3; true { b = a; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("b"), value.NewNumber(3)) {
+					if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(3)) {
 						return errors.New("Nested if statements should be able to shadow outer if statements' variables")
 					}
 
@@ -1422,7 +1783,7 @@ func TestEval(t *testing.T) {
 				name: "while statements create their own scope",
 				text: "let a = 1; while let mut a = 10; a < 11 { a = a + 1; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(1)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
 						return errors.New("Variables initialized in while loop should shadow outer scope")
 					}
 
@@ -1433,7 +1794,7 @@ func TestEval(t *testing.T) {
 				name: "while loops do not execute block when condition is false",
 				text: "let mut a = 1; while false { a = a + 1; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(1)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
 						return errors.New("While loop body should not have been entered")
 					}
 
@@ -1444,7 +1805,7 @@ func TestEval(t *testing.T) {
 				name: "while loops can be broken out of",
 				text: "let mut a = 1; while true { if a == 1 { break; } a = a + 1; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("a"), value.NewNumber(1)) {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
 						return errors.New("While loop body was not properly broken out of")
 					}
 
@@ -1455,16 +1816,3228 @@ func TestEval(t *testing.T) {
 				name: "while loops can be continued",
 				text: "let mut a, mut b = 1, 10; while a <= 3 { a = a + 1;  if a == 2 { continue; } b = b + 1; }",
 				validate: func(_ interface{}, i interpreter.IntpState) error {
-					if !reflect.DeepEqual(i.Env.Get("b"), value.NewNumber(12)) {
+					if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(12)) {
 						return errors.New("While loop body was not properly continued")
 					}
 
 					return nil
 				},
 			},
-		}
-
-		for _, e := range table {
+			{
+				name: "deferred expressions run when a function completes",
+				text: "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; fn<> () { defer rec(1); rec(0) }() log",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(0), value.NewInteger(1)})) {
+						return errors.New("Deferred expression should run after the function body")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deferred expressions run in LIFO order",
+				text: "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; fn<> () { defer rec(1); defer rec(2); rec(0) }() log",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(0), value.NewInteger(2), value.NewInteger(1)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Deferred expressions should run last-in-first-out")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deferred expressions run on return",
+				text: "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; let r = fn<> () { defer rec(1); return 'a'; rec(2) }(); [r, log]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{
+						value.NewString("a"),
+						value.NewTuple([]value.Value{value.NewInteger(1)}),
+					})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Deferred expression should run on return without changing the returned value")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "returns nested in blocks and loops keep their value",
+				text: "let f = fn () { if true { return 'a'; } return 'z'; }; let g = fn () { if false {} else { return 'b'; } }; let h = fn () { while true { return 'c'; } }; let k = fn () { loop { return 'd'; } }; [f(), g(), h(), k()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{
+						value.NewString("a"),
+						value.NewString("b"),
+						value.NewString("c"),
+						value.NewString("d"),
+					})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return fmt.Errorf("Expected the returned values, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deferred expressions see the scope they were declared in",
+				text: "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; fn<> () { if true { let x = 3; defer rec(x); } rec(0) }() log",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(0), value.NewInteger(3)})) {
+						return errors.New("Deferred expression should capture block scope")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deferred expressions run in proto methods",
+				text: "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; let p = proto { 'a' -> fn<> () { defer rec(me); rec(0) } }; (1 < p)->'a'() log",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl, ok := v.(*value.Tuple)
+
+					if !ok || tpl.Len() != 2 {
+						return errors.New("Deferred expression should run after the proto method body")
+					}
+
+					if n, ok := tpl.At(1).(*value.Integer); !ok || n.Hash() != value.NewInteger(1).Hash() {
+						return errors.New("Deferred expression should be able to reference 'me'")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deferred expressions are scoped to their own call",
+				text: "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; let f = fn<> () { defer rec(1); }; fn<> () { defer rec(2); f() rec(0) }() log",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(0), value.NewInteger(2)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Deferred expressions should only run when their own call exits")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "thrown errors can be caught",
+				text: "let mut m; try { throw 'boom'; } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("boom")) {
+						return errors.New("Caught error should have message 'boom'")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "custom protos keep the methods of the receiver's kind",
+				text: "let p = proto { 'shout' -> fn () -> me->'upper'() + '!' }; let s = 'a' < p; [s->'upper'(), s->'shout'(), ([1] < p)->'push'(2)]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{
+						value.NewString("A"),
+						value.NewString("A!"),
+						value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)}),
+					})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Custom protos should fall back to the built-in proto of the receiver")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "protos extending other protos",
+				text: "let a = proto { 'f' -> fn () -> 1, 'g' -> fn () -> 2 }; let b = proto extends a { 'g' -> fn () -> 3 }; let v = {} < b; [v->'f'(), v->'g'()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(3)})) {
+						return errors.New("Lookups should walk the proto chain with overrides taking precedence")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "super calls the parent's method",
+				text: "let a = proto { 'g' -> fn (n) -> n }; let b = proto extends a { 'g' -> fn (n) -> super->'g'(n) + 1 }; let c = proto extends b { 'g' -> fn (n) -> super->'g'(n) * 10 }; ({} < c)->'g'(1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(20)) {
+						return fmt.Errorf("Super calls should resolve from the declaring proto's parent, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "super reaches the built-in proto",
+				text: "let p = proto { 'upper' -> fn () -> super->'upper'() + '!' }; ('a' < p)->'upper'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("A!")) {
+						return errors.New("Super calls should fall back to the receiver's built-in proto")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "composing protos with extends",
+				text: "let A = proto { 'a' -> fn () -> me->'get'('n') }; let B = proto { 'b' -> fn () -> me->'get'('n') * 2 }; let v = { 'n' -> 2 } < (proto extends A, B { 'c' -> fn () -> me->'a'() + me->'b'() }); [v->'a'(), v->'b'(), v->'c'()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(2), value.NewInteger(4), value.NewInteger(6)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Methods from every composed proto should be bound to the receiver")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "composing protos with '+'",
+				text: "let Base = proto { 'x' -> fn () -> 1 }; let A = proto extends Base { 'a' -> fn () -> 2 }; let B = proto extends Base { 'b' -> fn () -> me->'get'('n') }; let v = { 'n' -> 3 } < (A + B); [v->'x'(), v->'a'(), v->'b'()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2), value.NewInteger(3)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Composed protos should combine members, including shared inherited ones")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "overriding clashes between composed protos",
+				text: "let A = proto { 'x' -> fn () -> 1 }; let B = proto { 'x' -> fn () -> 2 }; let C = proto extends A, B { 'x' -> fn () -> super->'x'() + 10 }; ({} < C)->'x'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(11)) {
+						return errors.New("Explicit overrides should settle clashes with super resolving to the first source")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "checking a protocol through the proto chain",
+				text: "protocol Show { 'show' -> 0 } let Base = proto { 'show' -> fn () -> 1 }; let P = proto extends Base implements Show { 'x' -> 1 }; Show->'check'({} < P)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Protocols should be checked against the receiver's proto chain, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "checking a protocol on a value without its methods",
+				text: "protocol Show { 'show' -> 0 } Show->'check'(1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Values without the methods should not satisfy the protocol, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "checking a protocol on a built-in kind",
+				text: "protocol Sized { 'len' -> 0 } Sized->'check'(1..3)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Built-in methods should satisfy the protocol, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "checking a protocol on a built-in kind without its methods",
+				text: "protocol Sized { 'len' -> 0 } Sized->'check'(true)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Built-in kinds without the methods should not satisfy the protocol, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "protocol 'name'",
+				text: "protocol Show { 'show' -> 0 } Show->'name'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("Show")) {
+						return fmt.Errorf("'name' should return the declared name, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of integers",
+				text: "1->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("integer")) {
+						return fmt.Errorf("The kind of integers should be 'integer', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of numbers",
+				text: "1.5->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("number")) {
+						return fmt.Errorf("The kind of numbers should be 'number', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of strings",
+				text: "'a'->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("string")) {
+						return fmt.Errorf("The kind of strings should be 'string', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of bottom",
+				text: "bottom->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("bottom")) {
+						return fmt.Errorf("The kind of bottom should be 'bottom', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of tuples",
+				text: "[1]->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("tuple")) {
+						return fmt.Errorf("The kind of tuples should be 'tuple', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of records",
+				text: "{}->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("record")) {
+						return fmt.Errorf("The kind of records should be 'record', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of functions",
+				text: "(fn () -> 1)->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("function")) {
+						return fmt.Errorf("The kind of functions should be 'function', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind of protos",
+				text: "(proto { 'a' -> 1 })->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("proto")) {
+						return fmt.Errorf("The kind of protos should be 'proto', got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "the kind does not depend on inherited protos",
+				text: "([1] < proto { 'a' -> 1 })->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("tuple")) {
+						return fmt.Errorf("Kinds should not depend on inherited protos, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "reflecting on proto keys",
+				text: "let P = proto { 'x' -> 1, 2 -> fn () -> 2 }; let Q = proto extends P { 'y' -> 3 }; (1 < Q)->'protoKeys'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl, ok := v.(*value.Tuple)
+
+					if !ok || tpl.Len() < 3 {
+						return fmt.Errorf("Expected a tuple of proto keys, got %v", v)
+					}
+
+					want := []value.Value{value.NewString("y"), value.NewString("x"), value.NewInteger(2)}
+
+					if !reflect.DeepEqual(tpl.Items()[:3], want) {
+						return fmt.Errorf("Proto keys should list the chain in order before the built-in proto, got %v", v)
+					}
+
+					for _, k := range tpl.Items() {
+						if reflect.DeepEqual(k, value.NewString("kind")) {
+							return errors.New("Proto keys should not include the members shared by every value")
+						}
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "function 'arity' leaves out the rest parameter",
+				text: "let f = fn (a, b, ...c) -> a; f->'arity'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
+						return fmt.Errorf("'arity' should count the required parameters, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "function 'params'",
+				text: "let f = fn (a, b, ...c) -> a; f->'params'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("b"), value.NewString("c")})) {
+						return fmt.Errorf("'params' should name every parameter, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "function 'rest'",
+				text: "let f = fn (a, b, ...c) -> a; f->'rest'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'rest' should report a rest parameter, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "function 'args' of a partial application",
+				text: "let f = fn (a, b, ...c) -> a; f(1)->'args'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1)})) {
+						return fmt.Errorf("'args' should list the applied arguments, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "function 'arity' of a partial application",
+				text: "let f = fn (a, b, ...c) -> a; f(1)->'arity'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return fmt.Errorf("'arity' should count the parameters left, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "function 'arity' of a bound method",
+				text: "let P = proto { 'm' -> fn (x) -> x }; ({} < P)->'m'->'arity'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return fmt.Errorf("'arity' should not count the receiver, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'len'",
+				text: "let t = [3, 1, 2]; t->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
+						return fmt.Errorf("'len' should count the items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'contains'",
+				text: "let t = [3, 1, 2]; t->'contains'(2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'contains' should find an item, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'indexOf' of a missing item",
+				text: "let t = [3, 1, 2]; t->'indexOf'(4)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(-1)) {
+						return fmt.Errorf("'indexOf' should return -1 for a missing item, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'slice' to the end",
+				text: "let t = [3, 1, 2]; t->'slice'(1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("'slice' should slice to the end, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'slice' to a negative index",
+				text: "let t = [3, 1, 2]; t->'slice'(0, -1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(3), value.NewInteger(1)})) {
+						return fmt.Errorf("'slice' should count negative indexes from the end, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'concat' of tuples and ranges",
+				text: "let t = [3, 1, 2]; t->'concat'([4], 5..6)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(3), value.NewInteger(1), value.NewInteger(2), value.NewInteger(4), value.NewInteger(5)})) {
+						return fmt.Errorf("'concat' should append tuples and ranges, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'reverse'",
+				text: "let t = [3, 1, 2]; t->'reverse'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(2), value.NewInteger(1), value.NewInteger(3)})) {
+						return fmt.Errorf("'reverse' should reverse the items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'sort'",
+				text: "let t = [3, 1, 2]; t->'sort'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2), value.NewInteger(3)})) {
+						return fmt.Errorf("'sort' should sort the items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'zip' stops at the shortest",
+				text: "let t = [3, 1, 2]; t->'zip'(['a', 'b'])",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewTuple([]value.Value{value.NewInteger(3), value.NewString("a")}), value.NewTuple([]value.Value{value.NewInteger(1), value.NewString("b")})})) {
+						return fmt.Errorf("'zip' should pair items up to the shortest tuple, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'flatten' flattens one level",
+				text: "[[1], 2, [[3]]]->'flatten'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2), value.NewTuple([]value.Value{value.NewInteger(3)})})) {
+						return fmt.Errorf("'flatten' should flatten one level, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'uniq'",
+				text: "[1, 1, 'a', 'a']->'uniq'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewString("a")})) {
+						return fmt.Errorf("'uniq' should drop repeated items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'first' of an empty tuple",
+				text: "[]->'first'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, &value.Bottom{}) {
+						return fmt.Errorf("'first' should return bottom for an empty tuple, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'last'",
+				text: "let t = [3, 1, 2]; t->'last'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
+						return fmt.Errorf("'last' should return the last item, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'join'",
+				text: "let t = [3, 1, 2]; t->'join'('-')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("3-1-2")) {
+						return fmt.Errorf("'join' should join the items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple methods leave the receiver unchanged",
+				text: "let t = [3, 1, 2]; let s = t->'sort'(); let r = t->'reverse'(); let c = t->'concat'([4]); t",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(3), value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("Tuple methods should not change the receiver, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'map' with a closure",
+				text: "let k = 10; let t = [1, 2, 3]; t->'map'(fn<> (x) -> x * k)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(10), value.NewInteger(20), value.NewInteger(30)})) {
+						return fmt.Errorf("'map' should call closures, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'filter' with a bound method",
+				text: "let t = [1, 2, 3]; let P = proto { 'big' -> fn (x) -> x > me->'get'('min') }; let m = { 'min' -> 1 } < P; t->'filter'(m->'big')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(2), value.NewInteger(3)})) {
+						return fmt.Errorf("'filter' should call bound methods, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'fold'",
+				text: "let t = [1, 2, 3]; let add = fn (a, b) -> a + b; t->'fold'(10, add)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(16)) {
+						return fmt.Errorf("'fold' should start from the initial value, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'reduce'",
+				text: "let t = [1, 2, 3]; let add = fn (a, b) -> a + b; t->'reduce'(add)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(6)) {
+						return fmt.Errorf("'reduce' should start from the first item, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'map' with a partially applied function",
+				text: "let t = [1, 2, 3]; let add = fn (a, b) -> a + b; t->'map'(add(1))",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(2), value.NewInteger(3), value.NewInteger(4)})) {
+						return fmt.Errorf("'map' should call partially applied functions, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'map' with a variadic function",
+				text: "let t = [1, 2, 3]; t->'map'(fn (...xs) -> xs)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewTuple([]value.Value{value.NewInteger(1)}), value.NewTuple([]value.Value{value.NewInteger(2)}), value.NewTuple([]value.Value{value.NewInteger(3)})})) {
+						return fmt.Errorf("'map' should pass a single argument, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'find'",
+				text: "let t = [1, 2, 3]; t->'find'(fn (x) -> x > 1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
+						return fmt.Errorf("'find' should return the first match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'find' without a match",
+				text: "let t = [1, 2, 3]; t->'find'(fn (x) -> x > 5)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, &value.Bottom{}) {
+						return fmt.Errorf("'find' should return bottom without a match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'any'",
+				text: "let t = [1, 2, 3]; t->'any'(fn (x) -> x > 2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'any' should be true when an item matches, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'all'",
+				text: "let t = [1, 2, 3]; t->'all'(fn (x) -> x > 2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("'all' should be false when an item does not match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'sort' with a comparator",
+				text: "let t = [1, 2, 3]; t->'sort'(fn (a, b) -> a > b)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(3), value.NewInteger(2), value.NewInteger(1)})) {
+						return fmt.Errorf("'sort' should order by the comparator, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'has'",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'has'('a')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'has' should find a key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'has' of a missing key",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'has'('z')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("'has' should not find a missing key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'getOr' of a missing key",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'getOr'('z', 0)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(0)) {
+						return fmt.Errorf("'getOr' should return the default for a missing key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'set' of an existing key keeps its place",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'set'('a', 3)->'entries'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewTuple([]value.Value{value.NewString("a"), value.NewInteger(3)}), value.NewTuple([]value.Value{value.NewString("b"), value.NewInteger(2)})})) {
+						return fmt.Errorf("'set' should replace the value in place, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'set' of a new key",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'set'('c', 3)->'keys'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("b"), value.NewString("c")})) {
+						return fmt.Errorf("'set' should add new keys last, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'delete'",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'delete'('a')->'keys'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("b")})) {
+						return fmt.Errorf("'delete' should drop the key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'values'",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'values'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("'values' should list the values in key order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'merge'",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'merge'({ 'c' -> 3, 'a' -> 0 })->'values'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(0), value.NewInteger(2), value.NewInteger(3)})) {
+						return fmt.Errorf("'merge' should prefer the argument's values, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'map' with a closure",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; let k = 10; r->'map'(fn<> (v, key) -> v * k)->'values'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(10), value.NewInteger(20)})) {
+						return fmt.Errorf("'map' should map the values, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'filter' by key",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'filter'(fn (v, key) -> key == 'b')->'keys'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("b")})) {
+						return fmt.Errorf("'filter' should pass the keys, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'len'",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
+						return fmt.Errorf("'len' should count the keys, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'pick' ignores missing keys",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'pick'('b', 'z')->'keys'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("b")})) {
+						return fmt.Errorf("'pick' should keep only present keys, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record 'omit'",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; r->'omit'('b')->'keys'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a")})) {
+						return fmt.Errorf("'omit' should drop the keys, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record methods leave the receiver unchanged",
+				text: "let r = { 'a' -> 1, 'b' -> 2 }; let s = r->'set'('c', 3); let d = r->'delete'('a'); r->'keys'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("b")})) {
+						return fmt.Errorf("Record methods should not change the receiver, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deleting from records that share their entries",
+				text: "let a = { 'x' -> 1, 'y' -> 2, 'z' -> 3 }; let b = a->'set'('w', 4); let c = b->'delete'('x'); let d = a->'delete'('y')->'set'('x', 9); a->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("{ 'x' -> 1, 'y' -> 2, 'z' -> 3 }")) {
+						return fmt.Errorf("The original record should keep every entry, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "setting on a record whose entries are shared",
+				text: "let a = { 'x' -> 1, 'y' -> 2, 'z' -> 3 }; let b = a->'set'('w', 4); let c = b->'delete'('x'); b->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("{ 'x' -> 1, 'y' -> 2, 'z' -> 3, 'w' -> 4 }")) {
+						return fmt.Errorf("A deletion should not change the record it was made from, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deleting from a record whose entries are shared",
+				text: "let a = { 'x' -> 1, 'y' -> 2, 'z' -> 3 }; let b = a->'set'('w', 4); let c = b->'delete'('x'); c->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("{ 'y' -> 2, 'z' -> 3, 'w' -> 4 }")) {
+						return fmt.Errorf("A deletion should keep the other entries in order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "setting a key deleted from a shared record",
+				text: "let a = { 'x' -> 1, 'y' -> 2, 'z' -> 3 }; a->'delete'('y')->'set'('x', 9)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("{ 'x' -> 9, 'z' -> 3 }")) {
+						return fmt.Errorf("Setting an existing key should keep its place, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "records left by a deletion are equal to literals",
+				text: "let a = { 'x' -> 1, 'y' -> 2, 'z' -> 3 }; let b = a->'set'('w', 4); let c = b->'delete'('x'); c == { 'w' -> 4, 'z' -> 3, 'y' -> 2 }",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Records with the same entries should be equal, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "setting a deleted key on a shared record adds it last",
+				text: "let a = { 'x' -> 1, 'y' -> 2, 'z' -> 3 }; let b = a->'set'('w', 4); let c = b->'delete'('x'); c->'set'('x', 5)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("{ 'y' -> 2, 'z' -> 3, 'w' -> 4, 'x' -> 5 }")) {
+						return fmt.Errorf("A deleted key should be set as a new key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deleting a missing key leaves the record equal",
+				text: "let a = { 'x' -> 1, 'y' -> 2, 'z' -> 3 }; a->'delete'('q') == a",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Deleting a missing key should change nothing, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deleting most of a record's keys shrinks it",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } r->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(10)) {
+						return fmt.Errorf("Deleted keys should not be counted, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deleting most of a record's keys keeps the rest in order",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } r->'keys'()->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[90, 91, 92, 93, 94, 95, 96, 97, 98, 99]")) {
+						return fmt.Errorf("Records should keep their remaining entries in order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "reading what is left after deleting most of a record's keys",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } r->'get'(95)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(190)) {
+						return fmt.Errorf("Remaining keys should keep their values, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deleted keys are gone",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } r->'has'(3)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Deleted keys should not be found, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "deleting keys leaves the original record whole",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } [full->'len'(), full->'get'(3)]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[100, 6]")) {
+						return fmt.Errorf("The original record should keep every entry, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "setting a deleted key adds it last",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } r->'set'(3, 0)->'keys'()->'last'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
+						return fmt.Errorf("A deleted key should be set as a new key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "omitting most of a record's keys",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } full->'omit'((0..95)...)->'keys'()->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[95, 96, 97, 98, 99]")) {
+						return fmt.Errorf("'omit' should keep the remaining entries in order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "records with the same entries left are equal",
+				text: "let mut r = {}; let mut i = 0; while i < 100 { r = r->'set'(i, i * 2); i = i + 1; } let full = r; i = 0; while i < 90 { r = r->'delete'(i); i = i + 1; } full->'omit'((0..95)...) == r->'omit'(90, 91, 92, 93, 94)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Records reached through different deletions should be equal, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "record equality ignores key order",
+				text: "[{ 'a' -> 1, 'b' -> 2 } == { 'b' -> 2, 'a' -> 1 }, { 'a' -> 1 } == { 'a' -> 2 }, [{ 'a' -> 1, 'b' -> 2 }, { 'b' -> 2, 'a' -> 1 }]->'uniq'()->'len'()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewBoolean(true), value.NewBoolean(false), value.NewInteger(1)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return fmt.Errorf("Records with the same entries should be equal, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'add'",
+				text: "let s = #{1, 2, 3}; s->'add'(4)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{1, 2, 3, 4}")) {
+						return fmt.Errorf("'add' should add the item last, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'add' of an equal number",
+				text: "let s = #{1, 2, 3}; s->'add'(1.0)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{1, 2, 3}")) {
+						return fmt.Errorf("'add' should not add an item equal to a member, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'remove'",
+				text: "let s = #{1, 2, 3}; s->'remove'(2)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{1, 3}")) {
+						return fmt.Errorf("'remove' should drop the item, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'has' of an equal number",
+				text: "let s = #{1, 2, 3}; s->'has'(2.0)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'has' should find an item equal to a member, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'has' of a missing item",
+				text: "let s = #{1, 2, 3}; s->'has'(5)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("'has' should not find a missing item, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'union'",
+				text: "let s = #{1, 2, 3}; s->'union'(#{5}, #{1, 6})->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{1, 2, 3, 5, 6}")) {
+						return fmt.Errorf("'union' should combine every set, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'intersect'",
+				text: "let s = #{1, 2, 3}; s->'intersect'(#{2, 3, 4}, #{3, 2})->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{2, 3}")) {
+						return fmt.Errorf("'intersect' should keep the items of every set in the receiver's order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'difference'",
+				text: "let s = #{1, 2, 3}; s->'difference'(#{1}, #{3})->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{2}")) {
+						return fmt.Errorf("'difference' should drop the items of every set, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'isSubset'",
+				text: "let s = #{1, 2, 3}; #{1}->'isSubset'(s)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'isSubset' should be true for a subset, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'isSubset' of a superset",
+				text: "let s = #{1, 2, 3}; s->'isSubset'(#{1})",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("'isSubset' should be false for a superset, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'len'",
+				text: "let s = #{1, 2, 3}; s->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
+						return fmt.Errorf("'len' should count the items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set 'toTuple'",
+				text: "let s = #{1, 2, 3}; s->'toTuple'()->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[1, 2, 3]")) {
+						return fmt.Errorf("'toTuple' should list the items in insertion order, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "set methods leave the receiver unchanged",
+				text: "let s = #{1, 2, 3}; let a = s->'add'(4); let r = s->'remove'(2); s->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("#{1, 2, 3}")) {
+						return fmt.Errorf("Set methods should not change the receiver, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'len' counts bytes",
+				text: "let b = b'héllo'; b->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(6)) {
+						return fmt.Errorf("'len' should count bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'at'",
+				text: "let b = b'héllo'; b->'at'(0)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(104)) {
+						return fmt.Errorf("'at' should return the byte, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'at' a negative index",
+				text: "let b = b'héllo'; b->'at'(-1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(111)) {
+						return fmt.Errorf("'at' should count negative indexes from the end, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'slice'",
+				text: "let b = b'héllo'; b->'slice'(1, 3)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("x'c3a9'")) {
+						return fmt.Errorf("'slice' should slice bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'slice' from a negative index",
+				text: "let b = b'héllo'; b->'slice'(-2)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("x'6c6f'")) {
+						return fmt.Errorf("'slice' should count negative indexes from the end, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'concat'",
+				text: "let b = b'héllo'; b->'concat'(x'00ff', b'!')->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("x'68c3a96c6c6f00ff21'")) {
+						return fmt.Errorf("'concat' should append every argument, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'toHex'",
+				text: "x'00fF7f'->'toHex'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("00ff7f")) {
+						return fmt.Errorf("'toHex' should return lowercase hex, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'toBase64'",
+				text: "let b = b'héllo'; b->'toBase64'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("aMOpbGxv")) {
+						return fmt.Errorf("'toBase64' should encode the bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'decodeUtf8'",
+				text: "let b = b'héllo'; b->'decodeUtf8'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("héllo")) {
+						return fmt.Errorf("'decodeUtf8' should decode the bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'toTuple'",
+				text: "x'0102'->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("'toTuple' should list the bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes can be spread",
+				text: "[x'0102'...]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("Spreading bytes should yield the bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'toBytes' encodes UTF-8",
+				text: "'hé'->'toBytes'() == b'hé'",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'toBytes' should encode UTF-8, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'fromHex' ignores case",
+				text: "bytes->'fromHex'('00FF') == x'00ff'",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'fromHex' should decode either case, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'fromBase64'",
+				text: "let b = b'héllo'; bytes->'fromBase64'('aMOpbGxv') == b",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'fromBase64' should decode the bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes 'of'",
+				text: "bytes->'of'(104, 105)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("x'6869'")) {
+						return fmt.Errorf("'of' should build bytes from integers, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes are equal record keys",
+				text: "{ b'a' -> 1 }->'get'(x'61')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return fmt.Errorf("Equal bytes should find the same key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes are unequal to strings",
+				text: "b'a' == 'a'",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Bytes should not equal strings, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "time 'parse' in a zone",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); t->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'instant'('2024-02-29T12:30:00+01:00')")) {
+						return fmt.Errorf("'parse' should read the instant in the zone, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instant 'zone'",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); t->'zone'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("Europe/Paris")) {
+						return fmt.Errorf("'zone' should name the instant's zone, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instant 'format'",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); t->'format'(time->'rfc1123')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("Thu, 29 Feb 2024 12:30:00 +0100")) {
+						return fmt.Errorf("'format' should follow the layout, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instant 'in' another zone",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); t->'in'('Asia/Tokyo')->'hour'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(20)) {
+						return fmt.Errorf("'in' should move the instant to the zone, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instant 'utc'",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); t->'utc'()->'hour'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(11)) {
+						return fmt.Errorf("'utc' should move the instant to UTC, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instant 'unix'",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); t->'unix'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1709206200)) {
+						return fmt.Errorf("'unix' should count seconds since the epoch, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instant calendar fields",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); [t->'year'(), t->'month'(), t->'day'(), t->'weekday'()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(2024), value.NewInteger(2), value.NewInteger(29), value.NewString("Thursday")})) {
+						return fmt.Errorf("Calendar fields should be read in the instant's zone, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "adding a duration to an instant",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); let d = time->'duration'('1h30m'); (t + d)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'instant'('2024-02-29T14:00:00+01:00')")) {
+						return fmt.Errorf("Adding a duration should move the instant later, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "adding an instant to a duration",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); let d = time->'duration'('1h30m'); (d + t)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'instant'('2024-02-29T14:00:00+01:00')")) {
+						return fmt.Errorf("Addition should commute, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "subtracting a duration from an instant",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); let d = time->'duration'('1h30m'); (t - d)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'instant'('2024-02-29T11:00:00+01:00')")) {
+						return fmt.Errorf("Subtracting a duration should move the instant earlier, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "subtracting instants",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); (t - time->'instant'('2024-01-01T00:00:00Z'))->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'duration'('1427h30m0s')")) {
+						return fmt.Errorf("Subtracting instants should give a duration, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "adding durations",
+				text: "let d = time->'duration'('1h30m'); (d + time->'minutes'(1))->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'duration'('1h31m0s')")) {
+						return fmt.Errorf("Adding durations should sum them, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "subtracting fractional durations",
+				text: "let d = time->'duration'('1h30m'); (d - time->'seconds'(1.5))->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'duration'('1h29m58.5s')")) {
+						return fmt.Errorf("Subtracting durations should keep fractions of a second, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "negating a duration",
+				text: "let d = time->'duration'('1h30m'); (-d)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'duration'('-1h30m0s')")) {
+						return fmt.Errorf("Negation should flip the duration, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "duration 'abs'",
+				text: "let d = time->'duration'('1h30m'); (-d)->'abs'()->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'duration'('1h30m0s')")) {
+						return fmt.Errorf("'abs' should drop the sign, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "duration 'scale'",
+				text: "let d = time->'duration'('1h30m'); d->'scale'(0.5)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'duration'('45m0s')")) {
+						return fmt.Errorf("'scale' should multiply the duration, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "dividing durations",
+				text: "let d = time->'duration'('1h30m'); d / time->'minutes'(1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(90)) {
+						return fmt.Errorf("Dividing durations should give their ratio, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "duration 'hours'",
+				text: "let d = time->'duration'('1h30m'); d->'hours'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(1.5)) {
+						return fmt.Errorf("'hours' should count fractional hours, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "duration 'nanoseconds'",
+				text: "let d = time->'duration'('1h30m'); d->'nanoseconds'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(5400000000000)) {
+						return fmt.Errorf("'nanoseconds' should count nanoseconds, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "comparing durations",
+				text: "let d = time->'duration'('1h30m'); d < time->'hours'(2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Durations should compare by length, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "comparing instants",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); let d = time->'duration'('1h30m'); t >= t + d",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Instants should compare by time, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "time 'fromUnix' keeps fractions of a second",
+				text: "time->'fromUnix'(1.5)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("time->'instant'('1970-01-01T00:00:01.5Z')")) {
+						return fmt.Errorf("'fromUnix' should keep fractions of a second, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instants in different zones are equal",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); time->'instant'('2024-02-29T11:30:00Z') == t",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("The same instant should be equal across zones, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "instants are equal record keys across zones",
+				text: "let t = time->'parse'('2024-02-29 12:30', '2006-01-02 15:04', 'Europe/Paris'); { t -> 1 }->'get'(t->'utc'())",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return fmt.Errorf("The same instant should find the same key across zones, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'kind'",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; re->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("regex")) {
+						return fmt.Errorf("Regexes should be of the regex kind, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'pattern'",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; re->'pattern'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?")) {
+						return fmt.Errorf("'pattern' should return the source, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'test'",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'test'(s)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'test' should match the string, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'test' without a match",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; re->'test'('x')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("'test' should not match the string, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'find'",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'find'(s)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("2024-02")) {
+						return fmt.Errorf("'find' should return the first match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'find' without a match",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; re->'find'('x')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, &value.Bottom{}) {
+						return fmt.Errorf("'find' should return bottom without a match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'findAll'",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'findAll'(s)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("2024-02"), value.NewString("2025-03-15")})) {
+						return fmt.Errorf("'findAll' should return every match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'findAll' with a limit",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'findAll'(s, 1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("2024-02")})) {
+						return fmt.Errorf("'findAll' should stop at the limit, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'captures' of unmatched groups",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'captures'(s)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("2024-02"), value.NewString("2024"), value.NewString("02"), &value.Bottom{}, &value.Bottom{}})) {
+						return fmt.Errorf("'captures' should return bottom for unmatched groups, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'captures' without a match",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; re->'captures'('x')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, &value.Bottom{}) {
+						return fmt.Errorf("'captures' should return bottom without a match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'namedCaptures'",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'namedCaptures'(s)->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("{ 'y' -> '2024', 'm' -> '02' }")) {
+						return fmt.Errorf("'namedCaptures' should record the named groups, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'replace' with a template",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'replace'(s, '${m}/$y')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("from 02/2024 to 03/2025")) {
+						return fmt.Errorf("'replace' should expand group references, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'replace' with a function",
+				text: "let re = r'(?P<y>\\d{4})-(?P<m>\\d\\d)(-(\\d\\d))?'; let s = 'from 2024-02 to 2025-03-15'; re->'replace'(s, fn (m, g, n) -> n->'get'('y') + g->'first'())",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("from 20242024-02 to 20252025-03-15")) {
+						return fmt.Errorf("'replace' should call the function with the match, groups and named groups, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'split'",
+				text: "r'\\s*,\\s*'->'split'('a , b,c')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("b"), value.NewString("c")})) {
+						return fmt.Errorf("'split' should split on every match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'split' with a limit",
+				text: "r'\\s*,\\s*'->'split'('a , b,c', 2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("b,c")})) {
+						return fmt.Errorf("'split' should stop at the limit, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex literals escape quotes",
+				text: "r'it\\'s' == regex->'compile'(\"it's\")",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("An escaped quote in a regex literal should match the quote, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regex 'escape'",
+				text: "regex->'escape'('a.b')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("a\\.b")) {
+						return fmt.Errorf("'escape' should quote metacharacters, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "regexes are equal record keys",
+				text: "{ r'a' -> 1 }->'get'(regex->'compile'('a'))",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return fmt.Errorf("Equal regexes should find the same key, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "generators yield lazily from nested statements",
+				text: "let range = fn (n) { let mut i = 0; while i < n { yield i; i = i + 1; } }; let it = range(2); [[range(4)...], it->'next'(), it->'next'(), it->'next'(), it->'next'(), it->'kind'(), it == it, range(1) == range(1)]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[[0, 1, 2, 3], 0, 1, done, done, 'iterator', true, false]")) {
+						return fmt.Errorf("Unexpected values yielded by generators %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "generators resume inside try, loop and if statements",
+				text: "let g = fn (xs) { try { yield xs->'first'(); throw 'boom'; } catch e { yield e->'message'(); } loop { yield 'loop'; break; } if xs->'len'() > 1 { yield 'long'; } else { yield 'short'; } return 1; yield 'unreachable'; }; [[g([1])...], [g([1, 2])...]]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[[1, 'boom', 'loop', 'short'], [1, 'boom', 'loop', 'long']]")) {
+						return fmt.Errorf("Unexpected values yielded by generators %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "generators run deferred expressions when drained, closed or cut short",
+				text: "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; let g = fn<> (n) { defer rec(n); yield 1; yield 2; }; let a = [g('drained')...]; let it = g('closed'); let first = it->'next'(); let closed = it->'close'(); let b = [g('taken')->'take'(1)...]; [a, b, log, first, closed, it->'next'()]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[[1, 2], [1], ['drained', 'closed', 'taken'], 1, bottom, done]")) {
+						return fmt.Errorf("Unexpected deferred expressions of generators %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "proto methods can be generators",
+				text: "let P = proto { 'pairs' -> fn () { let x = me->'get'('x'); yield [x, 1]; yield [x, 2]; } }; [(({ 'x' -> 'a' } < P)->'pairs'())...]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[['a', 1], ['a', 2]]")) {
+						return fmt.Errorf("Unexpected values yielded by proto methods %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "values implementing the iterator protocol can be spread",
+				text: "let mut n = 0; let Counter = proto implements Iterator { 'next' -> fn<> () { n = n + 1; if n > 3 { return done; } n } }; [[({} < Counter)...], Iterator->'check'({} < Counter)]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[[1, 2, 3], true]")) {
+						return fmt.Errorf("Unexpected values of custom iterators %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "iterator methods are lazy",
+				text: "let nat = fn () { let mut i = 0; while true { yield i; i = i + 1; } }; [nat()->'map'(fn (x) -> x * x)->'filter'(fn (x) -> x > 1)->'take'(3)...]->'stringify'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("[4, 9, 16]")) {
+						return fmt.Errorf("Unexpected results of iterator methods %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuples are iterable",
+				text: "[1, 2]->'iter'()->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("A tuple iterator should yield the items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "sets are iterable",
+				text: "#{1}->'iter'()->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1)})) {
+						return fmt.Errorf("A set iterator should yield the items, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "ranges are iterable",
+				text: "(1..3)->'iter'()->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("A range iterator should yield the numbers, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "bytes are iterable",
+				text: "b'ab'->'iter'()->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(97), value.NewInteger(98)})) {
+						return fmt.Errorf("A bytes iterator should yield the bytes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "strings are iterable by rune",
+				text: "'añ'->'iter'()->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("ñ")})) {
+						return fmt.Errorf("A string iterator should yield the runes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "records are iterable by entry",
+				text: "{ 'a' -> 1 }->'iter'()->'toTuple'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewTuple([]value.Value{value.NewString("a"), value.NewInteger(1)})})) {
+						return fmt.Errorf("A record iterator should yield the entries, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "built-in iterables satisfy the Iterable protocol",
+				text: "[Iterable->'check'('a'), Iterable->'check'({}), Iterable->'check'([])]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewBoolean(true), value.NewBoolean(true), value.NewBoolean(true)})) {
+						return fmt.Errorf("Strings, records and tuples should be Iterable, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "built-in iterators satisfy the Iterator protocol",
+				text: "Iterator->'check'([]->'iter'())",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("A tuple iterator should be an Iterator, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "numbers are not iterable",
+				text: "Iterable->'check'(1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Numbers should not be Iterable, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'len' counts runes",
+				text: "'Ünïcödé ✓'->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(9)) {
+						return fmt.Errorf("'len' should count runes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'lower'",
+				text: "'Ünïcödé ✓'->'lower'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("ünïcödé ✓")) {
+						return fmt.Errorf("'lower' should lower every rune, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'upper'",
+				text: "'Ünïcödé ✓'->'upper'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("ÜNÏCÖDÉ ✓")) {
+						return fmt.Errorf("'upper' should upper every rune, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'trim'",
+				text: "'  Ünïcödé ✓ '->'trim'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("Ünïcödé ✓")) {
+						return fmt.Errorf("'trim' should strip whitespace from both ends, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'trimStart'",
+				text: "'  Ünïcödé ✓ '->'trimStart'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("Ünïcödé ✓ ")) {
+						return fmt.Errorf("'trimStart' should strip leading whitespace only, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'trimEnd'",
+				text: "'  Ünïcödé ✓ '->'trimEnd'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("  Ünïcödé ✓")) {
+						return fmt.Errorf("'trimEnd' should strip trailing whitespace only, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'split' keeps empty parts",
+				text: "'a,b,,c'->'split'(',')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("b"), value.NewString(""), value.NewString("c")})) {
+						return fmt.Errorf("'split' should keep empty parts, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'replace' replaces the first match",
+				text: "'aXbXc'->'replace'('X', '-')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("a-bXc")) {
+						return fmt.Errorf("'replace' should only replace the first match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'replaceAll'",
+				text: "'aXbXc'->'replaceAll'('X', '-')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("a-b-c")) {
+						return fmt.Errorf("'replaceAll' should replace every match, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'contains'",
+				text: "'Ünïcödé ✓'->'contains'('cö')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'contains' should find a substring, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'startsWith'",
+				text: "'Ünïcödé ✓'->'startsWith'('Ün')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'startsWith' should match a prefix, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'endsWith'",
+				text: "'Ünïcödé ✓'->'endsWith'('✓')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'endsWith' should match a suffix, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'indexOf' counts runes",
+				text: "'Ünïcödé ✓'->'indexOf'('d')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(5)) {
+						return fmt.Errorf("'indexOf' should return a rune index, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'indexOf' of a missing substring",
+				text: "'Ünïcödé ✓'->'indexOf'('z')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(-1)) {
+						return fmt.Errorf("'indexOf' should return -1 for a missing substring, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'substring' slices runes",
+				text: "'Ünïcödé ✓'->'substring'(1, 3)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("nï")) {
+						return fmt.Errorf("'substring' should slice runes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'substring' from a negative index",
+				text: "'Ünïcödé ✓'->'substring'(-1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("✓")) {
+						return fmt.Errorf("'substring' should count negative indexes from the end, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'repeat'",
+				text: "'ab'->'repeat'(3)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("ababab")) {
+						return fmt.Errorf("'repeat' should repeat the string, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'chars' splits runes",
+				text: "'añ✓'->'chars'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewString("a"), value.NewString("ñ"), value.NewString("✓")})) {
+						return fmt.Errorf("'chars' should split the string into runes, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'padStart'",
+				text: "'7'->'padStart'(3, '0')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("007")) {
+						return fmt.Errorf("'padStart' should pad up to the length, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'padEnd' cuts the padding to fit",
+				text: "'ñ'->'padEnd'(4, 'ab')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("ñaba")) {
+						return fmt.Errorf("'padEnd' should cut the padding to the length, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'toNumber' ignores surrounding whitespace",
+				text: "' 3.5 '->'toNumber'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(3.5)) {
+						return fmt.Errorf("'toNumber' should parse a number, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'toNumber' of a non-number",
+				text: "'abc'->'toNumber'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, &value.Bottom{}) {
+						return fmt.Errorf("'toNumber' should return bottom for a non-number, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'toNumber' rejects NaN",
+				text: "'NaN'->'toNumber'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, &value.Bottom{}) {
+						return fmt.Errorf("'toNumber' should not parse NaN, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'floor'",
+				text: "(2.5)->'floor'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(2)) {
+						return fmt.Errorf("'floor' should round down, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'ceil'",
+				text: "(2.1)->'ceil'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(3)) {
+						return fmt.Errorf("'ceil' should round up, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'round' rounds halves away from zero",
+				text: "(-2.5)->'round'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(-3)) {
+						return fmt.Errorf("'round' should round halves away from zero, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'trunc'",
+				text: "(-2.7)->'trunc'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(-2)) {
+						return fmt.Errorf("'trunc' should round towards zero, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'abs'",
+				text: "(-3)->'abs'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
+						return fmt.Errorf("'abs' should drop the sign, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'sign'",
+				text: "(-3)->'sign'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(-1)) {
+						return fmt.Errorf("'sign' should return -1 for negatives, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'isNaN'",
+				text: "let nan = 0 / 0; nan->'isNaN'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'isNaN' should detect NaN, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'isFinite' of infinity",
+				text: "(1 / 0)->'isFinite'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("'isFinite' should be false for infinity, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'isInteger'",
+				text: "(3)->'isInteger'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'isInteger' should be true for integers, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'toFixed'",
+				text: "(3.14159)->'toFixed'(2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("3.14")) {
+						return fmt.Errorf("'toFixed' should format the digits asked for, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'clamp'",
+				text: "(12)->'clamp'(0, 10)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(10)) {
+						return fmt.Errorf("'clamp' should cap at the upper bound, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'sqrt' as a value",
+				text: "let f = fn (x) -> math->'sqrt'(x); f(16)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(4)) {
+						return fmt.Errorf("'sqrt' should return the square root, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'pi'",
+				text: "math->'pi'",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(math.Pi)) {
+						return fmt.Errorf("'pi' should be pi, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'max'",
+				text: "math->'max'(1, 5, 3)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(5)) {
+						return fmt.Errorf("'max' should return the largest argument, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'min' of a single argument",
+				text: "math->'min'(4)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(4)) {
+						return fmt.Errorf("'min' should return its only argument, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'div' floors",
+				text: "math->'div'(-7, 2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(-4)) {
+						return fmt.Errorf("'div' should floor the quotient, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'mod' follows the divisor's sign",
+				text: "math->'mod'(-7, 2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return fmt.Errorf("'mod' should follow the divisor's sign, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "NaN is unequal to itself",
+				text: "let nan = 0 / 0; nan == nan",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("NaN should not equal itself, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "NaN is different from itself",
+				text: "let nan = 0 / 0; nan != nan",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("NaN should differ from itself, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuples contain NaN",
+				text: "let nan = 0 / 0; [nan]->'contains'(nan)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("'contains' should find NaN, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "zero equals negative zero",
+				text: "0 == -0",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("0 should equal -0, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "dividing by zero gives infinity",
+				text: "1 / 0",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(math.Inf(1))) {
+						return fmt.Errorf("1 / 0 should be infinity, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'atan2'",
+				text: "math->'atan2'(1, 1)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(math.Pi/4)) {
+						return fmt.Errorf("'atan2' should return the angle, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "math 'log' of 'e'",
+				text: "math->'log'(math->'e')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(1)) {
+						return fmt.Errorf("'log' should be the natural logarithm, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer overflow promotes to a big integer",
+				text: "let big = 9223372036854775807; big + 1",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					b, _ := new(big.Int).SetString("9223372036854775808", 10)
+					w := value.NewBigInteger(b)
+
+					if got, ok := v.(value.Value); !ok || value.Kind(got) != value.Kind(w) || got.Hash() != w.Hash() {
+						return fmt.Errorf("Overflowing an integer should promote it, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "big integers demote back to integers",
+				text: "let big = 9223372036854775807; (big + 1) - 1",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(math.MaxInt64)) {
+						return fmt.Errorf("Big integers that fit should demote, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer powers stay exact",
+				text: "2 ** 64",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					b, _ := new(big.Int).SetString("18446744073709551616", 10)
+					w := value.NewBigInteger(b)
+
+					if got, ok := v.(value.Value); !ok || value.Kind(got) != value.Kind(w) || got.Hash() != w.Hash() {
+						return fmt.Errorf("Integer powers should stay exact, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer literals past 2^53 stay exact",
+				text: "9007199254740993",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(9007199254740993)) {
+						return fmt.Errorf("Integer literals should stay exact, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integers past 2^53 compare exactly",
+				text: "9007199254740993 == 9007199254740992",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(false)) {
+						return fmt.Errorf("Distinct integers should be unequal, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integers equal equivalent floats",
+				text: "1 == 1.0",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("1 should equal 1.0, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integers and equivalent floats are the same record key",
+				text: "{ 1 -> 'a' }->'get'(1.0)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("a")) {
+						return fmt.Errorf("1.0 should find the key 1, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer division gives a float",
+				text: "7 / 2",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(3.5)) {
+						return fmt.Errorf("Division should not truncate, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "negative integer powers give floats",
+				text: "2 ** -1",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(0.5)) {
+						return fmt.Errorf("Negative powers should give a float, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "mixing integers and floats gives a float",
+				text: "1 + 0.5",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewNumber(1.5)) {
+						return fmt.Errorf("Mixed arithmetic should give a float, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integers compare exactly with floats",
+				text: "9007199254740993 > 9007199254740992.0",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return fmt.Errorf("Integers should compare exactly with floats, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "negating the smallest integer promotes",
+				text: "(-9223372036854775807 - 1) * -1",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					b, _ := new(big.Int).SetString("9223372036854775808", 10)
+					w := value.NewBigInteger(b)
+
+					if got, ok := v.(value.Value); !ok || value.Kind(got) != value.Kind(w) || got.Hash() != w.Hash() {
+						return fmt.Errorf("Overflowing a product should promote it, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "negating the largest integer",
+				text: "let big = 9223372036854775807; -big",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(-math.MaxInt64)) {
+						return fmt.Errorf("Negation should stay an integer, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "number 'toInteger' truncates",
+				text: "2.5->'toInteger'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
+						return fmt.Errorf("'toInteger' should truncate, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer 'toFloat'",
+				text: "3->'toFloat'()->'kind'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("number")) {
+						return fmt.Errorf("'toFloat' should give a float, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "string 'toNumber' of a big integer",
+				text: "'123456789012345678901234567890'->'toNumber'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					b, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+					w := value.NewBigInteger(b)
+
+					if got, ok := v.(value.Value); !ok || value.Kind(got) != value.Kind(w) || got.Hash() != w.Hash() {
+						return fmt.Errorf("'toNumber' should parse big integers exactly, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "tuple 'uniq' of equivalent numbers",
+				text: "[1, 1.0]->'uniq'()->'len'()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return fmt.Errorf("'uniq' should treat 1 and 1.0 as equal, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer multiplication",
+				text: "3 * 4",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(12)) {
+						return fmt.Errorf("Products of integers should be integers, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "integer subtraction",
+				text: "10 - 12",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(-2)) {
+						return fmt.Errorf("Differences of integers should be integers, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "errors thrown by tuple callbacks can be caught",
+				text: "let mut m; try { let r = [1]->'map'(fn (x) { throw 'boom'; }); } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("boom")) {
+						return fmt.Errorf("Errors thrown by callbacks should propagate, got %v", i.Env.Get("m"))
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "sorting mixed kinds names the kinds",
+				text: "let mut m; try { let r = [1, 'a']->'sort'(); } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("Cannot sort string and integer values without a comparator")) {
+						return fmt.Errorf("Unexpected message %v", i.Env.Get("m"))
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "clashing composed members are named by their key",
+				text: "let A = proto { 'x' -> 1 }; let B = proto extends proto { 'x' -> 2 } { 'y' -> 1 }; let mut m; try { let C = A + B; } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("Member 'x' is defined by more than one composed proto and must be overridden")) {
+						return fmt.Errorf("Unexpected message %v", i.Env.Get("m"))
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "unimplemented protocol methods are named by their key",
+				text: "protocol Show { 'show' -> 0 } let f = fn (a) -> a; let mut m; try { let P = proto implements Show { 'show' -> f }; } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("Proto does not implement protocol \"Show\": method 'show' is missing or has the wrong arity")) {
+						return fmt.Errorf("Unexpected message %v", i.Env.Get("m"))
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "plain proto members",
+				text: "let P = proto { 'unit' -> 'kg', 'Inner' -> proto { 'a' -> fn () -> 1 }, 'fn' -> fn () -> me->'unit' }; let v = {} < P; [v->'unit', v->'fn'(), ({} < v->'Inner')->'a'()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewString("kg"), value.NewString("kg"), value.NewInteger(1)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Plain proto members should be returned as is")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "get is still usable as a name",
+				text: "let get = 1; let P = proto { 'a' -> get, 'b' -> get fn<> () -> get + 1 }; let v = {} < P; [get, v->'a', v->'b']",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(1), value.NewInteger(1), value.NewInteger(2)})) {
+						return fmt.Errorf("Unexpected values of get %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "step is still usable as a name",
+				text: "let step = 3; let r = 0..10 step step; let f = fn (step) -> step * 2; let mut s = 1; if true { let mut step = 0; 0..3 step = 2; s = step; } [r->'toTuple'(), f(step), (0..step)->'len'(), s]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{
+						value.NewTuple([]value.Value{value.NewInteger(0), value.NewInteger(3), value.NewInteger(6), value.NewInteger(9)}),
+						value.NewInteger(6),
+						value.NewInteger(3),
+						value.NewInteger(2),
+					})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return fmt.Errorf("Unexpected values of step %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "getter members",
+				text: "let P = proto { 'double' -> get fn () -> me->'get'('n') * 2, 'next' ->< get fn () -> { 'n' -> me->'get'('n') + 1 } }; let v = { 'n' -> 1 } < P; [v->'double', v->'next'->'next'->'double']",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(2), value.NewInteger(6)})) {
+						return errors.New("Getters should run when accessed and respect auto-inheritance")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "missing methods",
+				text: "let P = proto { 'known' -> fn () -> 'k', 'missing' ->< fn (k) -> { 'key' -> k, 'of' -> me->'get'('id') } }; let v = { 'id' -> 1 } < P; [v->'known'(), v->'other'->'get'('key'), v->'other'->'get'('of'), v->'other'->'known'()]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewString("k"), value.NewString("other"), value.NewInteger(1), value.NewString("k")})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return fmt.Errorf("Unknown fields should be handed to the 'missing' method, got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "proto hashes cover plain members",
+				text: "[(proto { 'a' -> 1 }) == (proto { 'a' -> 1 }), (proto { 'a' -> 1 }) == (proto { 'a' -> 2 })]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewBoolean(true), value.NewBoolean(false)})) {
+						return errors.New("Proto hashes should depend on their plain members")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "operator methods",
+				text: "let M = proto { '+' ->< fn (o) -> { 'amt' -> me->'get'('amt') + o->'get'('amt') }, '*' -> fn (n) -> me->'get'('amt') * n }; let a = { 'amt' -> 1 } < M; let b = { 'amt' -> 2 } < M; [(a + b + b)->'get'('amt'), a * 4]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(5), value.NewInteger(4)})) {
+						return errors.New("Binary operators should dispatch to the left operand's operator methods")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "equality methods",
+				text: "let P = proto { '==' -> fn (o) -> me->'get'('id') == o->'get'('id') }; let a = { 'id' -> 1, 'x' -> 1 } < P; let b = { 'id' -> 1, 'x' -> 2 } < P; [a == b, a != b, { 'id' -> 1 } == a]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewBoolean(true), value.NewBoolean(false), value.NewBoolean(false)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return errors.New("Equality should use the '==' method when the left operand defines one")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "comparison methods",
+				text: "let P = proto { '<' -> fn (o) -> me->'get'('n') < o->'get'('n') }; let a = { 'n' -> 1 } < P; let b = { 'n' -> 2 } < P; [a < b, b < a]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewBoolean(true), value.NewBoolean(false)})) {
+						return errors.New("Non-proto right operands of '<' should use the '<' method")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "call methods",
+				text: "let P = proto { 'call' -> fn (a, b) -> me->'get'('n') + a + b }; let f = { 'n' -> 1 } < P; [f(2, 3), f(2)(3)]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInteger(6), value.NewInteger(6)})) {
+						return errors.New("Values with a 'call' method should be callable")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "stringify methods in assert messages",
+				text: "let P = proto { '==' -> fn (o) -> false, 'stringify' -> fn () -> 'P!' }; let a = {} < P; let mut m; try { assert a == a; } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("Assertion `a == a` failed (left: P!, right: P!)")) {
+						return fmt.Errorf("Failed asserts should stringify operands with their 'stringify' method, got %v", i.Env.Get("m"))
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "unary not",
+				text: "!(1 == 2)",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewBoolean(true)) {
+						return errors.New("Unary not should negate booleans")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "passing asserts",
+				text: "let a = 1; assert a == 1; assert a < 2, 'a is small'; 3",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(3)) {
+						return errors.New("Passing asserts should not interrupt evaluation")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "failed asserts report source and operands",
+				text: "let a = 1; let mut m; let mut d; try { assert (a + 1) == 'b', 'a is b'; } catch e { m = e->'message'(); d = e->'data'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					msg := "Assertion `(a + 1) == 'b'` failed: a is b (left: 2, right: 'b')"
+
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString(msg)) {
+						return fmt.Errorf("Expected failed assert message %q, got %v", msg, i.Env.Get("m"))
+					}
+
+					if !reflect.DeepEqual(i.Env.Get("d"), value.NewTuple([]value.Value{value.NewInteger(2), value.NewString("b")})) {
+						return errors.New("Failed comparison assert should carry its operands")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "failed asserts without comparisons report source",
+				text: "let a = false; let mut m; try { assert a || a; } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("Assertion `a || a` failed")) {
+						return errors.New("Failed assert should report its source text")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "assert operands are evaluated once",
+				text: "let mut n = 0; let inc = fn<> () { n = n + 1; n }; assert inc() == 1; n",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return errors.New("Assert operands should only be evaluated once")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "thrown errors carry data",
+				text: "let mut d; try { throw 'boom', [1]; } catch e { d = e->'data'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("d"), value.NewTuple([]value.Value{value.NewInteger(1)})) {
+						return errors.New("Caught error should carry the thrown data")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "thrown errors carry their source position",
+				text: "let mut p; try { throw 'boom'; } catch e { p = e->'position'(); } p->'get'('col')",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(17)) {
+						return errors.New("Caught error should point at the throw statement")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "runtime errors can be caught",
+				text: "let mut e; try { { 'a' -> 1 }->'get'('b') } catch err { e = err; }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					ev, ok := i.Env.Get("e").(*value.Error)
+
+					if !ok {
+						return errors.New("Runtime error should have been caught as an error value")
+					}
+
+					if ev.Token == nil || ev.Token.Position.Row != 0 || ev.Token.Position.Col != 36 {
+						return errors.New("Runtime error should be placed at the call that failed")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "caught runtime errors know their position",
+				text: "let f = fn () {\n  1 + 'a'\n};\nlet mut p; try { f() } catch err { p = err->'position'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					want := value.NewRecord([]struct {
+						K value.Value
+						V value.Value
+					}{
+						{K: value.NewString("row"), V: value.NewInteger(1)},
+						{K: value.NewString("col"), V: value.NewInteger(4)},
+					})
+
+					if p := i.Env.Get("p").(value.Value); !p.Equal(want) {
+						return fmt.Errorf("Expected the position of the failing operator, got %v", p)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "errors thrown in functions can be caught by callers",
+				text: "let f = fn () { throw 'a'; }; let mut c = false; try { f() } catch { c = true; }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("c"), value.NewBoolean(true)) {
+						return errors.New("Error thrown in function was not caught")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "catch blocks are skipped when nothing is thrown",
+				text: "let mut c = false; try { 1 } catch { c = true; }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("c"), value.NewBoolean(false)) {
+						return errors.New("Catch block should not have run")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "return statements nested in blocks keep their value",
+				text: "fn () { if true { while true { return 1; } } return 2; }()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return errors.New("Nested return statement should return 1")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "return statements are not caught",
+				text: "fn () { try { return 1; } catch { } return 2; }()",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(1)) {
+						return errors.New("Return statement should pass through try blocks")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "break statements are not caught",
+				text: "let mut a = 0; while true { try { break; } catch { } a = 1; }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(0)) {
+						return errors.New("Break statement should pass through try blocks")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "nested try statements catch the innermost error",
+				text: "let mut m; try { try { throw 'inner'; } catch e { throw e->'message'() + '!'; } } catch e { m = e->'message'(); }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("m"), value.NewString("inner!")) {
+						return errors.New("Outer catch should receive the error thrown by the inner catch")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "labeled breaks leave outer while loops",
+				text: "let mut a = 0; outer: while true { while true { a = a + 1; break outer; } a = 100; }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(1)) {
+						return errors.New("Outer while loop was not broken out of")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "breaks take identifiers as values unless they name a loop around them",
+				text: "let v = 5; let r = loop { break v; }; let mut n = 0; let s = v: loop { n = n + 1; if n == 3 { break (v); } }; let t = v: loop { loop { break v n; } }; [r, s, t]",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					tpl := value.NewTuple([]value.Value{value.NewInteger(5), value.NewInteger(5), value.NewInteger(3)})
+
+					if !reflect.DeepEqual(v, tpl) {
+						return fmt.Errorf("Expected [5, 5, 3], got %v", v)
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "labeled continues skip to the next iteration of outer while loops",
+				text: "let mut a, mut b = 0, 0; outer: while a < 3 { a = a + 1; while true { continue outer; } b = b + 1; }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("a"), value.NewInteger(3)) {
+						return errors.New("Outer while loop should have run 3 times")
+					}
+
+					if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(0)) {
+						return errors.New("Outer while loop body should have been skipped")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "loop expressions yield their break value",
+				text: "let mut a = 0; loop { a = a + 1; if a == 5 { break a * 2; } }",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(10)) {
+						return errors.New("Loop expression should have yielded 10")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "loop expressions without a break value yield bottom",
+				text: "loop { break; }",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, &value.Bottom{}) {
+						return errors.New("Loop expression should have yielded bottom")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "labeled breaks carry values out of nested loops",
+				text: "outer: loop { loop { break outer 'done'; } }",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewString("done")) {
+						return errors.New("Outer loop expression should have yielded 'done'")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "unlabeled breaks only leave the innermost loop",
+				text: "let mut a = 0; outer: loop { loop { break; } a = a + 1; if a == 2 { break outer a; } }",
+				validate: func(v interface{}, _ interpreter.IntpState) error {
+					if !reflect.DeepEqual(v, value.NewInteger(2)) {
+						return errors.New("Outer loop expression should have run twice")
+					}
+
+					return nil
+				},
+			},
+			{
+				name: "loop expressions can be continued",
+				text: "let mut a, mut b = 0, 0; loop { a = a + 1; if a < 3 { continue; } b = b + 1; break; }",
+				validate: func(_ interface{}, i interpreter.IntpState) error {
+					if !reflect.DeepEqual(i.Env.Get("b"), value.NewInteger(1)) {
+						return errors.New("Loop body was not properly continued")
+					}
+
+					return nil
+				},
+			},
+		}
+
+		for _, e := range table {
 			ts, err := scanner.New().Read(e.text)
 
 			if err != nil {
@@ -1646,6 +5219,294 @@ func TestEval(t *testing.T) {
 				name: "functions are not spreadable",
 				text: "[(fn() {})...]",
 			},
+			{
+				name: "uncaught thrown errors",
+				text: "throw 'a';",
+			},
+			{
+				name: "extending a non-proto",
+				text: "proto extends 1 { 'a' -> fn () -> 1 }",
+			},
+			{
+				name: "super method that does not exist",
+				text: "let p = proto { 'a' -> fn () -> super->'a'() }; ('a' < p)->'a'()",
+			},
+			{
+				name: "clashing protos composed with '+'",
+				text: "(proto { 'x' -> fn () -> 1 }) + (proto { 'x' -> fn () -> 1 })",
+			},
+			{
+				name: "clashing protos composed with extends",
+				text: "let A = proto { 'x' -> 1 }; let B = proto { 'x' -> 2 }; proto extends A, B { 'y' -> 1 }",
+			},
+			{
+				name: "proto not implementing protocol through parent",
+				text: "protocol Show { 'show' -> 0 } let Base = proto { 'show' -> fn (a) -> a }; proto extends Base implements Show { 'x' -> 1 }",
+			},
+			{
+				name: "proto implementing protocol method with plain member",
+				text: "protocol Shape { 'compare' -> 1 } proto implements Shape { 'compare' -> 0 }",
+			},
+			{
+				name: "proto implementing protocol with a variable of the wrong arity",
+				text: "protocol Show { 'show' -> 0 } let f = fn (a) -> a; proto implements Show { 'show' -> f }",
+			},
+			{
+				name: "proto implementing a non-protocol",
+				text: "let a = 1; proto implements a { 'x' -> 1 }",
+			},
+			{
+				name: "missing member that is not a method",
+				text: "let v = {} < proto { 'missing' -> 1 }; v->'a'",
+			},
+			{
+				name: "operator without operator method",
+				text: "{} + {}",
+			},
+			{
+				name: "comparison without operator method",
+				text: "{} < 1",
+			},
+			{
+				name: "equality method returning a non-boolean",
+				text: "let a = {} < proto { '==' -> fn (o) -> 1 }; a == a",
+			},
+			{
+				name: "union of a set and a tuple",
+				text: "#{1}->'union'([2])",
+			},
+			{
+				name: "decoding bytes that are not UTF-8",
+				text: "x'ff'->'decodeUtf8'()",
+			},
+			{
+				name: "indexing past the end of bytes",
+				text: "b'a'->'at'(1)",
+			},
+			{
+				name: "bytes from integers out of range",
+				text: "bytes->'of'(256)",
+			},
+			{
+				name: "bytes from malformed base64",
+				text: "bytes->'fromBase64'('a')",
+			},
+			{
+				name: "adding a number to a duration",
+				text: "time->'seconds'(1) + 1",
+			},
+			{
+				name: "adding a number to an instant",
+				text: "time->'now'() + 1",
+			},
+			{
+				name: "comparing a duration with a number",
+				text: "time->'seconds'(1) < 2",
+			},
+			{
+				name: "parsing a time that does not match the layout",
+				text: "time->'parse'('29/02/2024', time->'dateOnly')",
+			},
+			{
+				name: "converting to an unknown time zone",
+				text: "time->'now'()->'in'('Nowhere/Zone')",
+			},
+			{
+				name: "duration overflow",
+				text: "time->'hours'(10000000)",
+			},
+			{
+				name: "errors thrown by generators",
+				text: "let g = fn () { yield 1; throw 'boom'; }; [g()...]",
+			},
+			{
+				name: "generators advancing themselves",
+				text: "let mut it = bottom; let g = fn<> () { yield it->'next'(); }; it = g(); it->'next'()",
+			},
+			{
+				name: "generator while conditions must be boolean",
+				text: "let g = fn () { while 1 { yield 1; } }; [g()...]",
+			},
+			{
+				name: "taking a negative number of values",
+				text: "[1]->'iter'()->'take'(-1)",
+			},
+			{
+				name: "implementing the iterator protocol without next",
+				text: "proto implements Iterator { 'x' -> 1 }",
+			},
+			{
+				name: "compiling an invalid regex",
+				text: "regex->'compile'('(a')",
+			},
+			{
+				name: "regex replacement callback returning a non-string",
+				text: "r'a'->'replace'('abc', fn (m) -> 1)",
+			},
+			{
+				name: "regex methods on non-strings",
+				text: "r'a'->'test'(1)",
+			},
+			{
+				name: "tuple callback returning a non-boolean",
+				text: "[1]->'filter'(fn (x) -> x)",
+			},
+			{
+				name: "sorting mixed kinds without a comparator",
+				text: "[1, 'a']->'sort'()",
+			},
+			{
+				name: "reducing an empty tuple",
+				text: "[]->'reduce'(fn (a, b) -> a)",
+			},
+			{
+				name: "tuple callback raising a runtime error",
+				text: "[1]->'map'(fn (x) -> x + {})",
+			},
+			{
+				name: "merging a record with a non-record",
+				text: "{ 'a' -> 1 }->'merge'([1])",
+			},
+			{
+				name: "record filter callback returning a non-boolean",
+				text: "{ 'a' -> 1 }->'filter'(fn (v) -> v)",
+			},
+			{
+				name: "splitting a string on a non-string",
+				text: "'a b'->'split'(1)",
+			},
+			{
+				name: "repeating a string a negative number of times",
+				text: "'a'->'repeat'(-1)",
+			},
+			{
+				name: "padding a string with an empty string",
+				text: "'a'->'padStart'(3, '')",
+			},
+			{
+				name: "integer division by zero",
+				text: "math->'div'(1, 0)",
+			},
+			{
+				name: "integer modulo of a non-integer",
+				text: "math->'mod'(1.5, 1)",
+			},
+			{
+				name: "math function of a non-number",
+				text: "math->'sqrt'('4')",
+			},
+			{
+				name: "clamping with inverted bounds",
+				text: "(1)->'clamp'(2, 0)",
+			},
+			{
+				name: "integer power that is too large",
+				text: "2 ** 100000000000",
+			},
+			{
+				name: "converting infinity to an integer",
+				text: "(1 / 0)->'toInteger'()",
+			},
+			{
+				name: "calling a value without a call method",
+				text: "let a = {}; a()",
+			},
+			{
+				name: "unary not with non-boolean",
+				text: "!1",
+			},
+			{
+				name: "failed assert",
+				text: "let a = 1; assert a == 2;",
+			},
+			{
+				name: "assert with non-boolean condition",
+				text: "let a = 1; assert a;",
+			},
+			{
+				name: "assert with non-string message",
+				text: "let a = 1; assert a == 2, 3;",
+			},
+			{
+				name: "caught errors can be rethrown",
+				text: "try { throw 'a'; } catch e { throw e; }",
+			},
+			{
+				name: "errors in catch blocks bubble up",
+				text: "try { throw 'a'; } catch { 1 + 'a' }",
+			},
+			{
+				name: "thrown values must be strings or errors",
+				text: "throw 1;",
+			},
+			{
+				name: "errors in deferred expressions bubble up",
+				text: "fn () { defer 1 + 'a'; }()",
+			},
+			{
+				name: "range bounds must be numbers",
+				text: "'a'..'z'",
+			},
+			{
+				name: "range steps must be numbers",
+				text: "0..10 step '1'",
+			},
+			{
+				name: "range steps cannot be zero",
+				text: "0..10 step 0",
+			},
+			{
+				name: "range bounds must be finite",
+				text: "0..(1 / 0)",
+			},
+			{
+				name: "range bounds cannot be NaN",
+				text: "(0 / 0)..1",
+			},
+			{
+				name: "range steps must be finite",
+				text: "0..10 step (1 / 0)",
+			},
+			{
+				name: "ranges too long to make a tuple",
+				text: "(0..1000000000000)->'toTuple'()",
+			},
+			{
+				name: "ranges too long to spread into a tuple",
+				text: "[(0..100000000000)...]",
+			},
+			{
+				name: "ranges too long to spread into a set",
+				text: "#{(0..100000000000)...}",
+			},
+			{
+				name: "ranges too long to spread into arguments",
+				text: "let f = fn (...xs) -> xs; f((0..100000000000)...)",
+			},
+			{
+				name: "ranges too long to concatenate",
+				text: "[]->'concat'(0..100000000000)",
+			},
+			{
+				name: "fixing an integer to too many digits",
+				text: "1->'toFixed'(9223372036854775807)",
+			},
+			{
+				name: "fixing a number to too many digits",
+				text: "1.5->'toFixed'(9223372036854775807)",
+			},
+			{
+				name: "repeating a string past the length limit",
+				text: "'ab'->'repeat'(9223372036854775807)",
+			},
+			{
+				name: "padding the start of a string past the length limit",
+				text: "'a'->'padStart'(9223372036854775807)",
+			},
+			{
+				name: "padding the end of a string past the length limit",
+				text: "'a'->'padEnd'(9223372036854775807, 'xy')",
+			},
 		}
 
 		for _, e := range table {
@@ -1678,4 +5539,76 @@ func TestEval(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("uncaught errors are returned to the host", func(t *testing.T) {
+		ts, _ := scanner.New().Read("let f = fn () { throw 'a', 1; }; f()")
+		ast, _ := parser.New(ts).Parse()
+
+		_, err := interpreter.New().Eval(ast)
+
+		var thr cerrors.ThrowError
+
+		if !errors.As(err, &thr) {
+			t.Fatal("Uncaught error should be returned as a ThrowError")
+		}
+
+		ev, ok := thr.Value.(*value.Error)
+
+		if !ok || ev.Message != "a" || !reflect.DeepEqual(ev.Data, value.NewInteger(1)) {
+			t.Error("ThrowError should carry the thrown error value")
+		}
+	})
+
+	t.Run("deferred expressions run on runtime errors", func(t *testing.T) {
+		text := "let mut log = []; let rec = fn<> (v) { log = log->'push'(v); }; fn<> () { defer rec(1); 1 + 'a' }()"
+
+		ts, _ := scanner.New().Read(text)
+		ast, _ := parser.New(ts).Parse()
+		err := staticanalyzer.New().Analyze(ast)
+
+		if err != nil {
+			t.Errorf("Unexpected static error %q", err)
+		}
+
+		i := interpreter.New()
+		_, err = i.Eval(ast)
+
+		if err == nil {
+			t.Error("Runtime error should still be returned after deferred expressions run")
+		}
+
+		if !reflect.DeepEqual(i.Dump().Env.Get("log"), value.NewTuple([]value.Value{value.NewInteger(1)})) {
+			t.Error("Deferred expression did not run when the function errored")
+		}
+	})
+
+	t.Run("asserts can be disabled", func(t *testing.T) {
+		ts, _ := scanner.New().Read("let a = 1; assert a == 2; a")
+		ast, _ := parser.New(ts).Parse()
+
+		v, err := interpreter.New(interpreter.DisableAsserts()).Eval(ast)
+
+		if err != nil {
+			t.Fatalf("Unexpected runtime error %q", err)
+		}
+
+		if !reflect.DeepEqual(v, value.NewInteger(1)) {
+			t.Error("Disabled asserts should be no-ops")
+		}
+	})
+	t.Run("hosts can freeze the clock", func(t *testing.T) {
+		ts, _ := scanner.New().Read("[time->'now'(), time->'now'() - time->'now'()]")
+		ast, _ := parser.New(ts).Parse()
+
+		frozen := time.Date(2024, 2, 29, 12, 30, 0, 0, time.UTC)
+		v, err := interpreter.New(interpreter.WithClock(func() time.Time { return frozen })).Eval(ast)
+
+		if err != nil {
+			t.Fatalf("Unexpected runtime error %q", err)
+		}
+
+		if !reflect.DeepEqual(v, value.NewTuple([]value.Value{value.NewInstant(frozen), value.NewDuration(0)})) {
+			t.Errorf("'now' should read the host's clock, got %v", v)
+		}
+	})
 }
@@ -1,8 +1,14 @@
 package interpreter
 
 import (
+	"calabash/ast"
+	"calabash/errors"
 	"calabash/internal/tokentype"
 	"calabash/internal/value"
+	"calabash/lexer/tokens"
+	errs "errors"
+	"fmt"
+	"math"
 )
 
 var numericOps map[tokentype.Tokentype]interface{} = map[tokentype.Tokentype]interface{}{
@@ -21,15 +27,26 @@ func isNumericOp(op tokentype.Tokentype) bool {
 	return ok
 }
 
+func isComparisonOp(op tokentype.Tokentype) bool {
+	switch op {
+	case tokentype.EQUAL_EQUAL, tokentype.BANG_EQUAL, tokentype.LESS, tokentype.LESS_EQUAL, tokentype.GREAT, tokentype.GREAT_EQUAL:
+		return true
+	}
+
+	return false
+}
+
 func isBooleanOp(op tokentype.Tokentype) bool {
 	return op == tokentype.AMPERSAND_AMPERSAND || op == tokentype.STROKE_STROKE
 }
 
+// areNumbers tells whether every value is numeric, whether a float or an
+// integer
 func areNumbers(ns ...interface{}) bool {
 	for _, n := range ns {
-		_, ok := n.(*value.Number)
+		v, ok := n.(value.Value)
 
-		if !ok {
+		if !ok || !value.IsNumeric(v) {
 			return false
 		}
 	}
@@ -48,3 +65,167 @@ func areBools(bs ...interface{}) bool {
 
 	return true
 }
+
+func appendSequence(vs []value.Value, seq value.Sequence) []value.Value {
+	for idx := 0; idx < seq.Len(); idx++ {
+		vs = append(vs, seq.At(idx))
+	}
+
+	return vs
+}
+
+// isFinite tells whether `v` is an integer or a number that is neither
+// infinite nor NaN
+func isFinite(v value.Value) bool {
+	n, ok := v.(*value.Number)
+
+	return !ok || (!math.IsNaN(n.Value) && !math.IsInf(n.Value, 0))
+}
+
+// targetsLoop checks whether a `break` or `continue` aimed at `target` should
+// be handled by the loop with the given label. Unlabeled jumps are always
+// handled by the innermost loop.
+func targetsLoop(label *tokens.Token, target string) bool {
+	return target == "" || (label != nil && label.Lexeme == target)
+}
+
+func throw(e *value.Error) errors.ThrowError {
+	msg := e.Message
+
+	if e.Token != nil {
+		msg = fmt.Sprintf("%s at %d:%d", msg, e.Token.Position.Row, e.Token.Position.Col)
+	}
+
+	return errors.ThrowError{Msg: msg, Value: e}
+}
+
+// catchable converts the errors a script is allowed to recover from into
+// error values. Control flow (return/break/continue) and internal errors are
+// never caught.
+func catchable(err error) (*value.Error, bool) {
+	var thr errors.ThrowError
+
+	if errs.As(err, &thr) {
+		ev, ok := thr.Value.(*value.Error)
+
+		return ev, ok
+	}
+
+	var rt errors.RuntimeError
+
+	if errs.As(err, &rt) {
+		return value.NewError(rt.Msg, nil, rt.Token), true
+	}
+
+	return nil, false
+}
+
+// nodeToken gives the token that places `n` in the source, or nil for nodes
+// without one
+func nodeToken(n ast.Node) *tokens.Token {
+	var tk tokens.Token
+
+	switch n := n.(type) {
+	case ast.BinaryExpr:
+		tk = n.Operator
+	case ast.UnaryExpr:
+		tk = n.Operator
+	case ast.RangeExpr:
+		tk = n.Operator
+	case ast.NumericLiteralExpr:
+		tk = n.Value
+	case ast.StringLiteralExpr:
+		tk = n.Value
+	case ast.BytesLiteralExpr:
+		tk = n.Value
+	case ast.RegexLiteralExpr:
+		tk = n.Value
+	case ast.BooleanLiteralExpr:
+		tk = n.Value
+	case ast.BottomLiteralExpr:
+		tk = n.Token
+	case ast.IdentifierExpr:
+		tk = n.Name
+	case ast.CallExpr:
+		tk = n.Paren
+	case ast.GetExpr:
+		tk = n.Arrow
+	case ast.MeExpr:
+		tk = n.Token
+	case ast.SuperExpr:
+		tk = n.Token
+	case ast.QuestionExpr:
+		tk = n.Token
+	case ast.ThrowStmt:
+		tk = n.Token
+	case ast.YieldStmt:
+		tk = n.Token
+	case ast.AssertStmt:
+		tk = n.Token
+	case ast.ProtocolStmt:
+		tk = n.Name
+	case ast.AssignmentStmt:
+		if len(n.Names) == 0 {
+			return nil
+		}
+
+		tk = n.Names[0]
+	default:
+		return nil
+	}
+
+	return &tk
+}
+
+func unwrapGrouping(e ast.Expr) ast.Expr {
+	for {
+		g, ok := e.(ast.GroupingExpr)
+
+		if !ok {
+			return e
+		}
+
+		e = g.Expr
+	}
+}
+
+// operatorMethod looks up one of the reserved proto keys (e.g. "+", "==",
+// "call") for `v` and binds it to `v`
+func operatorMethod(v interface{}, key string) (value.Caller, bool) {
+	vl, ok := v.(value.Value)
+
+	if !ok || vl.Proto() == nil {
+		return nil, false
+	}
+
+	m, ok := value.Member(vl, vl.Proto(), value.NewString(key).Hash())
+
+	if !ok {
+		return nil, false
+	}
+
+	pm, ok := m.(*value.ProtoMethod)
+
+	if !ok {
+		return nil, false
+	}
+
+	return pm.Bind(vl), true
+}
+
+// display renders a value for use in diagnostic messages, using the
+// "stringify" override of its proto when it has one
+func (i *interpreter) display(v value.Value) string {
+	s, err := value.Render(v, func(c value.Caller) (value.Value, error) {
+		r, err := i.call(c, []value.Value{})
+		rv, _ := r.(value.Value)
+
+		return rv, err
+	})
+
+	if err != nil {
+		return value.Display(v)
+	}
+
+	return s
+}
@@ -5,6 +5,7 @@ import (
 	"calabash/internal/tokentype"
 	"calabash/lexer/tokens"
 	"fmt"
+	"regexp"
 )
 
 type scanner struct {
@@ -47,9 +48,20 @@ func (s *scanner) Read(str string) ([]tokens.Token, error) {
 		case '}':
 			ts = append(ts, tokens.New(tokentype.RIGHT_BRACE, "}", s.pos.row, s.pos.col))
 
+		case '#':
+			if s.peek() != '{' {
+				return []tokens.Token{}, errors.ScanError{Msg: fmt.Sprintf("Expected '{' after '#' at (%d, %d)", s.pos.row, s.pos.col)}
+			}
+
+			ts = append(ts, tokens.New(tokentype.HASH_BRACE, "#{", s.pos.row, s.pos.col))
+			s.next() // Move ahead one token since we have a two-character token
+
 		case ',':
 			ts = append(ts, tokens.New(tokentype.COMMA, ",", s.pos.row, s.pos.col))
 
+		case ':':
+			ts = append(ts, tokens.New(tokentype.COLON, ":", s.pos.row, s.pos.col))
+
 		case ';':
 			ts = append(ts, tokens.New(tokentype.SEMICOLON, ";", s.pos.row, s.pos.col))
 
@@ -218,31 +230,58 @@ func (s *scanner) Read(str string) ([]tokens.Token, error) {
 
 		case '.':
 			{
-				s.next()
+				next := s.peek()
 
-				if s.char() != '.' {
-					if s.isEnd() {
-						return []tokens.Token{}, errors.ScanError{Msg: "Incomplete rest/spread token"}
+				if next != '.' {
+					if next == -1 {
+						return []tokens.Token{}, errors.ScanError{Msg: "Incomplete range/spread token"}
 					}
 
 					return []tokens.Token{}, errors.ScanError{Msg: "Unrecognized token '.'"}
 				}
 
-				s.next()
+				col := s.pos.col
+				s.next() // Move ahead one token since we have at least a two-character token
+				next = s.peek()
+
+				if next == '.' {
+					ts = append(ts, tokens.New(tokentype.DOT_DOT_DOT, "...", s.pos.row, col))
+					s.next() // Move ahead one token since we have a three-character token
+				} else if next == '=' {
+					ts = append(ts, tokens.New(tokentype.DOT_DOT_EQUAL, "..=", s.pos.row, col))
+					s.next() // Move ahead one token since we have a three-character token
+				} else {
+					ts = append(ts, tokens.New(tokentype.DOT_DOT, "..", s.pos.row, col))
+				}
+			}
 
-				if s.char() != '.' {
-					if s.isEnd() {
-						return []tokens.Token{}, errors.ScanError{Msg: "Incomplete rest/spread token"}
-					}
+		default:
+			tl := len(ts)
 
-					return []tokens.Token{}, errors.ScanError{Msg: "Unrecognized token '..'"}
+			// A quote right after `b` or `x` begins a bytes literal rather
+			// than an identifier
+			if (s.char() == 'b' || s.char() == 'x') && (s.peek() == '\'' || s.peek() == '"') {
+				tk, err := s.bytes()
+
+				if err != nil {
+					return []tokens.Token{}, err
 				}
 
-				ts = append(ts, tokens.New(tokentype.DOT_DOT_DOT, "...", s.pos.row, s.pos.col))
+				ts = append(ts, tk)
+				break
 			}
 
-		default:
-			tl := len(ts)
+			// Likewise, a quote right after `r` begins a regex literal
+			if s.char() == 'r' && (s.peek() == '\'' || s.peek() == '"') {
+				tk, err := s.regex()
+
+				if err != nil {
+					return []tokens.Token{}, err
+				}
+
+				ts = append(ts, tk)
+				break
+			}
 
 			if isDigit(s.char()) {
 				ds := []rune{s.char()}
@@ -253,7 +292,9 @@ func (s *scanner) Read(str string) ([]tokens.Token, error) {
 					ds = append(ds, s.char())
 				}
 
-				if s.peek() == '.' {
+				// A '.' followed by another '.' begins a range or spread token
+				// rather than a decimal point
+				if s.peek() == '.' && s.peekNext() != '.' {
 					s.next()
 					ds = append(ds, s.char())
 
@@ -308,6 +349,84 @@ func (s *scanner) Read(str string) ([]tokens.Token, error) {
 	return append(ts, tokens.New(tokentype.EOF, "", s.pos.row, s.pos.col)), nil
 }
 
+// bytes scans a bytes literal: `b'...'` holds the UTF-8 encoding of its text
+// and `x'...'` the bytes its pairs of hexadecimal digits spell
+func (s *scanner) bytes() (tokens.Token, error) {
+	row, col := s.pos.row, s.pos.col
+	cs := []rune{s.char()}
+	s.next()
+
+	q := s.char()
+	cs = append(cs, q)
+	s.next()
+
+	for s.char() != q {
+		if s.isEnd() {
+			return tokens.Token{}, errors.ScanError{Msg: "Unterminated bytes literal"}
+		}
+
+		cs = append(cs, s.char())
+		s.next()
+	}
+
+	cs = append(cs, s.char())
+
+	if cs[0] == 'x' {
+		ds := cs[2 : len(cs)-1]
+
+		if len(ds)%2 != 0 {
+			return tokens.Token{}, errors.ScanError{Msg: fmt.Sprintf("Hexadecimal bytes literal at (%d, %d) must have an even number of digits", row, col)}
+		}
+
+		for _, d := range ds {
+			if !isHexDigit(d) {
+				return tokens.Token{}, errors.ScanError{Msg: fmt.Sprintf("Unexpected %q in hexadecimal bytes literal at (%d, %d)", d, row, col)}
+			}
+		}
+	}
+
+	return tokens.New(tokentype.BYTES, string(cs), row, col), nil
+}
+
+// regex scans a regex literal, `r'...'`. Its text is the pattern as written,
+// except that a backslash before the closing quote only keeps the quote from
+// ending the literal. The pattern is checked here so that mistakes are
+// reported where they are made.
+func (s *scanner) regex() (tokens.Token, error) {
+	row, col := s.pos.row, s.pos.col
+	cs := []rune{s.char()}
+	s.next()
+
+	q := s.char()
+	cs = append(cs, q)
+	s.next()
+
+	for s.char() != q {
+		if s.isEnd() {
+			return tokens.Token{}, errors.ScanError{Msg: "Unterminated regex literal"}
+		}
+
+		if s.char() == '\\' && s.peek() != -1 {
+			if s.peek() != q {
+				cs = append(cs, s.char())
+			}
+
+			s.next()
+		}
+
+		cs = append(cs, s.char())
+		s.next()
+	}
+
+	cs = append(cs, s.char())
+
+	if _, err := regexp.Compile(string(cs[2 : len(cs)-1])); err != nil {
+		return tokens.Token{}, errors.ScanError{Msg: fmt.Sprintf("Invalid regex literal at (%d, %d): %s", row, col, err)}
+	}
+
+	return tokens.New(tokentype.REGEX, string(cs), row, col), nil
+}
+
 func (s *scanner) isEnd() bool {
 	return s.cur >= len(s.rs)
 }
@@ -335,6 +454,14 @@ func (s *scanner) peek() rune {
 	return s.rs[s.cur+1]
 }
 
+func (s *scanner) peekNext() rune {
+	if s.cur+2 >= len(s.rs) {
+		return -1
+	}
+
+	return s.rs[s.cur+2]
+}
+
 func New() *scanner {
 	return &scanner{}
 }
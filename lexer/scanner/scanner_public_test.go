@@ -68,6 +68,7 @@ func TestRead(t *testing.T) {
 		{name: "left brace", text: "{", expected: []tokens.Token{tokens.New(tokentype.LEFT_BRACE, "{", 0, 0)}},
 		{name: "right brace", text: "}", expected: []tokens.Token{tokens.New(tokentype.RIGHT_BRACE, "}", 0, 0)}},
 		{name: "comma", text: ",", expected: []tokens.Token{tokens.New(tokentype.COMMA, ",", 0, 0)}},
+		{name: "colon", text: ":", expected: []tokens.Token{tokens.New(tokentype.COLON, ":", 0, 0)}},
 		{name: "semicolon", text: ";", expected: []tokens.Token{tokens.New(tokentype.SEMICOLON, ";", 0, 0)}},
 		{name: "less", text: "<", expected: []tokens.Token{tokens.New(tokentype.LESS, "<", 0, 0)}},
 		{name: "less equal", text: "<=", expected: []tokens.Token{tokens.New(tokentype.LESS_EQUAL, "<=", 0, 0)}},
@@ -102,6 +103,17 @@ func TestRead(t *testing.T) {
 		{name: "string single quotes", text: "'abc'", expected: []tokens.Token{tokens.New(tokentype.STRING, "'abc'", 0, 0)}},
 		{name: "unterminated double string", text: "\"abc", expected: []tokens.Token{}, willError: true},
 		{name: "unterminated single string", text: "'abc", expected: []tokens.Token{}, willError: true},
+		{name: "text bytes", text: "b'abc'", expected: []tokens.Token{tokens.New(tokentype.BYTES, "b'abc'", 0, 0)}},
+		{name: "hexadecimal bytes", text: "x\"00fF\"", expected: []tokens.Token{tokens.New(tokentype.BYTES, "x\"00fF\"", 0, 0)}},
+		{name: "odd hexadecimal bytes", text: "x'abc'", expected: []tokens.Token{}, willError: true},
+		{name: "non-hexadecimal bytes", text: "x'zz'", expected: []tokens.Token{}, willError: true},
+		{name: "unterminated bytes", text: "b'abc", expected: []tokens.Token{}, willError: true},
+		{name: "identifiers named like bytes prefixes", text: "b x", expected: []tokens.Token{tokens.New(tokentype.IDENTIFIER, "b", 0, 0), tokens.New(tokentype.IDENTIFIER, "x", 0, 2)}},
+		{name: "regex", text: "r'\\d+'", expected: []tokens.Token{tokens.New(tokentype.REGEX, "r'\\d+'", 0, 0)}},
+		{name: "regex with an escaped quote", text: "r'it\\'s'", expected: []tokens.Token{tokens.New(tokentype.REGEX, "r'it's'", 0, 0)}},
+		{name: "invalid regex", text: "r'(a'", expected: []tokens.Token{}, willError: true},
+		{name: "unterminated regex", text: "r'a", expected: []tokens.Token{}, willError: true},
+		{name: "identifiers named like the regex prefix", text: "r", expected: []tokens.Token{tokens.New(tokentype.IDENTIFIER, "r", 0, 0)}},
 		{name: "if", text: "if", expected: []tokens.Token{tokens.New(tokentype.IF, "if", 0, 0)}},
 		{name: "else", text: "else", expected: []tokens.Token{tokens.New(tokentype.ELSE, "else", 0, 0)}},
 		{name: "for", text: "for", expected: []tokens.Token{tokens.New(tokentype.FOR, "for", 0, 0)}},
@@ -121,6 +133,24 @@ func TestRead(t *testing.T) {
 		{name: "break", text: "break", expected: []tokens.Token{tokens.New(tokentype.BREAK, "break", 0, 0)}},
 		{name: "continue", text: "continue", expected: []tokens.Token{tokens.New(tokentype.CONTINUE, "continue", 0, 0)}},
 		{name: "spread/rest", text: "...", expected: []tokens.Token{tokens.New(tokentype.DOT_DOT_DOT, "...", 0, 0)}},
+		{name: "range", text: "..", expected: []tokens.Token{tokens.New(tokentype.DOT_DOT, "..", 0, 0)}},
+		{name: "inclusive range", text: "..=", expected: []tokens.Token{tokens.New(tokentype.DOT_DOT_EQUAL, "..=", 0, 0)}},
+		{name: "try", text: "try", expected: []tokens.Token{tokens.New(tokentype.TRY, "try", 0, 0)}},
+		{name: "catch", text: "catch", expected: []tokens.Token{tokens.New(tokentype.CATCH, "catch", 0, 0)}},
+		{name: "throw", text: "throw", expected: []tokens.Token{tokens.New(tokentype.THROW, "throw", 0, 0)}},
+		{name: "super", text: "super", expected: []tokens.Token{tokens.New(tokentype.SUPER, "super", 0, 0)}},
+		{name: "extends", text: "extends", expected: []tokens.Token{tokens.New(tokentype.EXTENDS, "extends", 0, 0)}},
+		{name: "get is an identifier", text: "get", expected: []tokens.Token{tokens.New(tokentype.IDENTIFIER, "get", 0, 0)}},
+		{name: "protocol", text: "protocol", expected: []tokens.Token{tokens.New(tokentype.PROTOCOL, "protocol", 0, 0)}},
+		{name: "implements", text: "implements", expected: []tokens.Token{tokens.New(tokentype.IMPLEMENTS, "implements", 0, 0)}},
+		{name: "assert", text: "assert", expected: []tokens.Token{tokens.New(tokentype.ASSERT, "assert", 0, 0)}},
+		{name: "defer", text: "defer", expected: []tokens.Token{tokens.New(tokentype.DEFER, "defer", 0, 0)}},
+		{name: "yield", text: "yield", expected: []tokens.Token{tokens.New(tokentype.YIELD, "yield", 0, 0)}},
+		{name: "loop", text: "loop", expected: []tokens.Token{tokens.New(tokentype.LOOP, "loop", 0, 0)}},
+		{name: "step is an identifier", text: "step", expected: []tokens.Token{tokens.New(tokentype.IDENTIFIER, "step", 0, 0)}},
+		{name: "single dot", text: ".", expected: []tokens.Token{}, willError: true},
+		{name: "hash brace", text: "#{", expected: []tokens.Token{tokens.New(tokentype.HASH_BRACE, "#{", 0, 0)}},
+		{name: "single hash", text: "#", expected: []tokens.Token{}, willError: true},
 	}
 
 	for _, e := range table {
@@ -153,6 +183,16 @@ func TestRead(t *testing.T) {
 			text:     "<<<",
 			expected: []tokens.Token{tokens.New(tokentype.LESS_LESS, "<<", 0, 0), tokens.New(tokentype.LESS, "<", 0, 0)},
 		},
+		{
+			name:     "range between integers",
+			text:     "0..10",
+			expected: []tokens.Token{tokens.New(tokentype.NUMBER, "0", 0, 0), tokens.New(tokentype.DOT_DOT, "..", 0, 0), tokens.New(tokentype.NUMBER, "10", 0, 0)},
+		},
+		{
+			name:     "inclusive range between decimals",
+			text:     "0.5..=1.5",
+			expected: []tokens.Token{tokens.New(tokentype.NUMBER, "0.5", 0, 0), tokens.New(tokentype.DOT_DOT_EQUAL, "..=", 0, 0), tokens.New(tokentype.NUMBER, "1.5", 0, 0)},
+		},
 		{
 			name:     "double stroke stroke great",
 			text:     "|||>",
@@ -13,24 +13,39 @@ func isDigit(r rune) bool {
 	return '0' <= r && r <= '9'
 }
 
+func isHexDigit(r rune) bool {
+	return isDigit(r) || ('a' <= r && r <= 'f') || ('A' <= r && r <= 'F')
+}
+
 func isAlpha(r rune) bool {
 	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z')
 }
 
 var keywords map[string]tokens.Token = map[string]tokens.Token{
-	"if":       tokens.New(tokentype.IF, "", 0, 0),
-	"else":     tokens.New(tokentype.ELSE, "", 0, 0),
-	"for":      tokens.New(tokentype.FOR, "", 0, 0),
-	"let":      tokens.New(tokentype.LET, "", 0, 0),
-	"true":     tokens.New(tokentype.TRUE, "", 0, 0),
-	"false":    tokens.New(tokentype.FALSE, "", 0, 0),
-	"fn":       tokens.New(tokentype.FN, "", 0, 0),
-	"return":   tokens.New(tokentype.RETURN, "", 0, 0),
-	"bottom":   tokens.New(tokentype.BOTTOM, "", 0, 0),
-	"mut":      tokens.New(tokentype.MUT, "", 0, 0),
-	"me":       tokens.New(tokentype.ME, "", 0, 0),
-	"proto":    tokens.New(tokentype.PROTO, "", 0, 0),
-	"while":    tokens.New(tokentype.WHILE, "", 0, 0),
-	"continue": tokens.New(tokentype.CONTINUE, "", 0, 0),
-	"break":    tokens.New(tokentype.BREAK, "", 0, 0),
+	"if":         tokens.New(tokentype.IF, "", 0, 0),
+	"else":       tokens.New(tokentype.ELSE, "", 0, 0),
+	"for":        tokens.New(tokentype.FOR, "", 0, 0),
+	"let":        tokens.New(tokentype.LET, "", 0, 0),
+	"true":       tokens.New(tokentype.TRUE, "", 0, 0),
+	"false":      tokens.New(tokentype.FALSE, "", 0, 0),
+	"fn":         tokens.New(tokentype.FN, "", 0, 0),
+	"return":     tokens.New(tokentype.RETURN, "", 0, 0),
+	"bottom":     tokens.New(tokentype.BOTTOM, "", 0, 0),
+	"mut":        tokens.New(tokentype.MUT, "", 0, 0),
+	"me":         tokens.New(tokentype.ME, "", 0, 0),
+	"proto":      tokens.New(tokentype.PROTO, "", 0, 0),
+	"super":      tokens.New(tokentype.SUPER, "", 0, 0),
+	"extends":    tokens.New(tokentype.EXTENDS, "", 0, 0),
+	"protocol":   tokens.New(tokentype.PROTOCOL, "", 0, 0),
+	"implements": tokens.New(tokentype.IMPLEMENTS, "", 0, 0),
+	"while":      tokens.New(tokentype.WHILE, "", 0, 0),
+	"continue":   tokens.New(tokentype.CONTINUE, "", 0, 0),
+	"break":      tokens.New(tokentype.BREAK, "", 0, 0),
+	"loop":       tokens.New(tokentype.LOOP, "", 0, 0),
+	"defer":      tokens.New(tokentype.DEFER, "", 0, 0),
+	"try":        tokens.New(tokentype.TRY, "", 0, 0),
+	"catch":      tokens.New(tokentype.CATCH, "", 0, 0),
+	"throw":      tokens.New(tokentype.THROW, "", 0, 0),
+	"assert":     tokens.New(tokentype.ASSERT, "", 0, 0),
+	"yield":      tokens.New(tokentype.YIELD, "", 0, 0),
 }
@@ -15,3 +15,25 @@ var equalityTokens []tokentype.Tokentype = []tokentype.Tokentype{
 	tokentype.EQUAL_EQUAL,
 	tokentype.BANG_EQUAL,
 }
+
+var rangeTokens []tokentype.Tokentype = []tokentype.Tokentype{
+	tokentype.DOT_DOT,
+	tokentype.DOT_DOT_EQUAL,
+}
+
+var labelFollowTokens []tokentype.Tokentype = []tokentype.Tokentype{
+	tokentype.SEMICOLON,
+	tokentype.NUMBER,
+	tokentype.STRING,
+	tokentype.IDENTIFIER,
+	tokentype.TRUE,
+	tokentype.FALSE,
+	tokentype.BOTTOM,
+	tokentype.FN,
+	tokentype.LEFT_BRACKET,
+	tokentype.LEFT_BRACE,
+	tokentype.PROTO,
+	tokentype.ME,
+	tokentype.SUPER,
+	tokentype.LOOP,
+}
@@ -3,6 +3,8 @@ package parser
 import (
 	"calabash/ast"
 	"calabash/internal/tokentype"
+	"calabash/lexer/tokens"
+	"strings"
 )
 
 func (p *parser) varDeclarationNames() ([]ast.Identifier, error) {
@@ -162,20 +164,113 @@ func (p *parser) protoMethod() (ast.ProtoMethod, error) {
 	}
 
 	inherits := p.isThenEat(tokentype.LESS)
+	getter := p.getterModifier()
 
-	_, err = p.eat(tokentype.FN)
+	// Only methods can auto-inherit or be getters; other members may be any
+	// expression
+	if inherits || getter {
+		_, err = p.eat(tokentype.FN)
+
+		if err != nil {
+			return ast.ProtoMethod{}, err
+		}
+
+		m, err := p.function()
+
+		if err != nil {
+			return ast.ProtoMethod{}, err
+		}
+
+		return ast.ProtoMethod{K: k, M: m, I: inherits, G: getter}, nil
+	}
+
+	m, err := p.expression()
 
 	if err != nil {
 		return ast.ProtoMethod{}, err
 	}
 
-	m, err := p.function()
+	return ast.ProtoMethod{K: k, M: m}, nil
+}
+
+// getterModifier consumes the `get` marking a getter. `get` is not reserved:
+// it is only the modifier when a function follows it, so it remains usable as
+// a name elsewhere.
+func (p *parser) getterModifier() bool {
+	if !p.is(tokentype.IDENTIFIER) || p.current().Lexeme != "get" || !p.isAhead(1, tokentype.FN) {
+		return false
+	}
+
+	p.next()
+
+	return true
+}
+
+// stepModifier consumes the `step` introducing the step of a range. Like
+// `get`, `step` is not reserved: after the end of a range it is the modifier
+// unless it is being assigned to, so it remains usable as a name elsewhere.
+func (p *parser) stepModifier() bool {
+	if !p.is(tokentype.IDENTIFIER) || p.current().Lexeme != "step" || p.isAhead(1, tokentype.EQUAL, tokentype.COMMA) {
+		return false
+	}
+
+	p.next()
+
+	return true
+}
+
+// protoRefs parses the comma separated protos or protocols following
+// `extends` or `implements`
+func (p *parser) protoRefs() ([]ast.Expr, error) {
+	ref, err := p.callOrGet()
 
 	if err != nil {
-		return ast.ProtoMethod{}, err
+		return nil, err
+	}
+
+	refs := []ast.Expr{ref}
+
+	for p.isThenEat(tokentype.COMMA) {
+		ref, err = p.callOrGet()
+
+		if err != nil {
+			return nil, err
+		}
+
+		refs = append(refs, ref)
 	}
 
-	return ast.ProtoMethod{K: k, M: m, I: inherits}, nil
+	return refs, nil
+}
+
+func (p *parser) protocolMethods() ([]ast.ProtocolMethod, error) {
+	methods := []ast.ProtocolMethod{}
+
+	for {
+		k, err := p.fundamental()
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.eat(tokentype.MINUS_GREAT)
+
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := p.eat(tokentype.NUMBER)
+
+		if err != nil {
+			return nil, err
+		}
+
+		methods = append(methods, ast.ProtocolMethod{K: k, Arity: n})
+
+		if !p.isThenEat(tokentype.COMMA) {
+			return methods, nil
+		}
+	}
 }
 
 type KeyVal = struct {
@@ -204,3 +299,28 @@ func (p *parser) recordKeyValue() (KeyVal, error) {
 
 	return KeyVal{Key: k, Val: v}, nil
 }
+
+// sourceText rebuilds the text a run of tokens was scanned from, using their
+// positions to restore the spacing between them on the same line
+func sourceText(ts []tokens.Token) string {
+	var sb strings.Builder
+
+	for i, t := range ts {
+		if i > 0 {
+			prev := ts[i-1]
+			gap := 1
+
+			if prev.Position.Row == t.Position.Row {
+				gap = t.Position.Col - prev.Position.Col - len([]rune(prev.Lexeme))
+			}
+
+			if gap > 0 {
+				sb.WriteString(strings.Repeat(" ", gap))
+			}
+		}
+
+		sb.WriteString(t.Lexeme)
+	}
+
+	return sb.String()
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"calabash/ast"
 	"calabash/errors"
+	"calabash/internal/slice"
 	"calabash/internal/tokentype"
 	"calabash/lexer/tokens"
 	"fmt"
@@ -11,6 +12,11 @@ import (
 type parser struct {
 	tokens []tokens.Token
 	i      int
+	// yielded records whether the body of the function being parsed has a
+	// `yield` statement of its own
+	yielded bool
+	// labels holds the labels of the loops around the statement being parsed
+	labels []string
 }
 
 func (p *parser) Parse() ([]ast.Node, error) {
@@ -53,6 +59,27 @@ func (p *parser) is(ts ...tokentype.Tokentype) bool {
 	return false
 }
 
+// isAhead checks the type of the token `n` places past the current one
+// without consuming anything
+func (p *parser) isAhead(n int, ts ...tokentype.Tokentype) bool {
+	if p.i+n >= len(p.tokens) {
+		return false
+	}
+
+	for _, v := range ts {
+		if v == p.tokens[p.i+n].Type {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isLabel checks whether the upcoming tokens are a loop label (`ident:`)
+func (p *parser) isLabel() bool {
+	return p.is(tokentype.IDENTIFIER) && p.isAhead(1, tokentype.COLON)
+}
+
 func (p *parser) isThenEat(ts ...tokentype.Tokentype) bool {
 	is := p.is(ts...)
 
@@ -118,8 +145,22 @@ func (p *parser) stmtOrExpr() (ast.Node, error) {
 		return n, nil
 	}
 
+	if p.isLabel() && p.isAhead(2, tokentype.WHILE) {
+		label, _ := p.eat(tokentype.IDENTIFIER)
+		p.eat(tokentype.COLON)
+		p.eat(tokentype.WHILE)
+
+		n, err := p.whileStmt(&label)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
 	if p.isThenEat(tokentype.WHILE) {
-		n, err := p.whileStmt()
+		n, err := p.whileStmt(nil)
 
 		if err != nil {
 			return nil, err
@@ -148,6 +189,69 @@ func (p *parser) stmtOrExpr() (ast.Node, error) {
 		return n, nil
 	}
 
+	if p.is(tokentype.THROW) {
+		tk, _ := p.eat(tokentype.THROW)
+		n, err := p.throwStmt(tk)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
+	if p.is(tokentype.ASSERT) {
+		tk, _ := p.eat(tokentype.ASSERT)
+		n, err := p.assertStmt(tk)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
+	if p.isThenEat(tokentype.PROTOCOL) {
+		n, err := p.protocolStmt()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
+	if p.isThenEat(tokentype.TRY) {
+		n, err := p.tryStmt()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
+	if p.is(tokentype.YIELD) {
+		tk, _ := p.eat(tokentype.YIELD)
+		n, err := p.yieldStmt(tk)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
+	if p.isThenEat(tokentype.DEFER) {
+		n, err := p.deferStmt()
+
+		if err != nil {
+			return nil, err
+		}
+
+		return n, nil
+	}
+
 	expr, err := p.expression()
 
 	if err != nil {
@@ -344,7 +448,7 @@ func (p *parser) retStmt() (ast.Node, error) {
 	return ast.ReturnStmt{Expr: expr}, nil
 }
 
-func (p *parser) whileStmt() (ast.Node, error) {
+func (p *parser) whileStmt(label *tokens.Token) (ast.Node, error) {
 	var varDecl ast.Node
 	var err error
 	var decls ast.VarDeclStmt
@@ -365,33 +469,247 @@ func (p *parser) whileStmt() (ast.Node, error) {
 		return nil, err
 	}
 
+	defer p.enterLoop(label)()
 	block, err := p.blockStmt()
 
 	if err != nil {
 		return nil, err
 	}
 
-	return ast.WhileStmt{Decls: decls, Condition: expr, Block: block}, nil
+	return ast.WhileStmt{Label: label, Decls: decls, Condition: expr, Block: block}, nil
 }
 
 func (p *parser) contStmt() (ast.Node, error) {
+	var label *tokens.Token
+
+	// `continue` takes no value, so an identifier can only be a label
+	if p.is(tokentype.IDENTIFIER) {
+		l, _ := p.eat(tokentype.IDENTIFIER)
+		label = &l
+	}
+
 	_, err := p.eat(tokentype.SEMICOLON)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return ast.ContinueStmt{}, nil
+	return ast.ContinueStmt{Label: label}, nil
 }
 
 func (p *parser) brkStmt() (ast.Node, error) {
-	_, err := p.eat(tokentype.SEMICOLON)
+	var expr ast.Expr
+
+	label, err := p.jumpLabel()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.is(tokentype.SEMICOLON) {
+		expr, err = p.expression()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.eat(tokentype.SEMICOLON)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.BreakStmt{Label: label, Expr: expr}, nil
+}
+
+func (p *parser) throwStmt(tk tokens.Token) (ast.Node, error) {
+	expr, err := p.expression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional data to attach to the error
+	var data ast.Expr
+
+	if p.isThenEat(tokentype.COMMA) {
+		data, err = p.expression()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.eat(tokentype.SEMICOLON)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return ast.BreakStmt{}, nil
+	return ast.ThrowStmt{Token: tk, Expr: expr, Data: data}, nil
+}
+
+func (p *parser) assertStmt(tk tokens.Token) (ast.Node, error) {
+	start := p.i
+	expr, err := p.expression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	src := sourceText(p.tokens[start:p.i])
+
+	// Optional message to report on failure
+	var msg ast.Expr
+
+	if p.isThenEat(tokentype.COMMA) {
+		msg, err = p.expression()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.eat(tokentype.SEMICOLON)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.AssertStmt{Token: tk, Expr: expr, Message: msg, Source: src}, nil
+}
+
+func (p *parser) protocolStmt() (ast.Node, error) {
+	name, err := p.eat(tokentype.IDENTIFIER)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.eat(tokentype.LEFT_BRACE)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := p.protocolMethods()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.eat(tokentype.RIGHT_BRACE)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.ProtocolStmt{Name: name, Methods: ms}, nil
+}
+
+func (p *parser) tryStmt() (ast.Node, error) {
+	body, err := p.blockStmt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.eat(tokentype.CATCH)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Binding the caught error to a name is optional
+	var name *tokens.Token
+
+	if p.is(tokentype.IDENTIFIER) {
+		n, _ := p.eat(tokentype.IDENTIFIER)
+		name = &n
+	}
+
+	catch, err := p.blockStmt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.TryStmt{Body: body, Name: name, Catch: catch}, nil
+}
+
+func (p *parser) deferStmt() (ast.Node, error) {
+	expr, err := p.expression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.eat(tokentype.SEMICOLON)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.DeferStmt{Expr: expr}, nil
+}
+
+func (p *parser) yieldStmt(tk tokens.Token) (ast.Node, error) {
+	expr, err := p.expression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.eat(tokentype.SEMICOLON)
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.yielded = true
+
+	return ast.YieldStmt{Token: tk, Expr: expr}, nil
+}
+
+// jumpLabel consumes the label targeted by a `break`. An identifier is only
+// treated as a label when it names a loop around the `break` and ends the
+// statement or is directly followed by the start of a break value. Otherwise
+// it starts the break value, so `break v;` breaks with the value of `v`
+// unless a loop labeled `v` is in scope, in which case `break (v);` does.
+// An identifier directly followed by the start of a break value cannot be a
+// value itself, so it is an unknown label; `break v;` is left for the static
+// analyzer to settle, as only it knows whether `v` is a variable.
+func (p *parser) jumpLabel() (*tokens.Token, error) {
+	if !p.is(tokentype.IDENTIFIER) || !p.isAhead(1, labelFollowTokens...) {
+		return nil, nil
+	}
+
+	if !slice.Contains(p.labels, p.current().Lexeme) {
+		if p.isAhead(1, tokentype.SEMICOLON) {
+			return nil, nil
+		}
+
+		tk := p.current()
+
+		return nil, errors.ParseError{Msg: fmt.Sprintf("Label %q does not exist at %d:%d", tk.Lexeme, tk.Position.Row, tk.Position.Col)}
+	}
+
+	label, _ := p.eat(tokentype.IDENTIFIER)
+
+	return &label, nil
+}
+
+// enterLoop makes `label`, if there is one, visible to the jumps in the body
+// of the loop being parsed. It returns a function that hides it again.
+func (p *parser) enterLoop(label *tokens.Token) func() {
+	if label == nil {
+		return func() {}
+	}
+
+	p.labels = append(p.labels, label.Lexeme)
+
+	return func() { p.labels = p.labels[:len(p.labels)-1] }
 }
 
 func (p *parser) expression() (ast.Expr, error) {
@@ -500,7 +818,7 @@ func (p *parser) equality() (ast.Expr, error) {
 }
 
 func (p *parser) comparison() (ast.Expr, error) {
-	left, err := p.addition()
+	left, err := p.rangeExpr()
 
 	if err != nil {
 		return nil, err
@@ -508,7 +826,7 @@ func (p *parser) comparison() (ast.Expr, error) {
 
 	if p.is(comparisonTokens...) {
 		op, _ := p.eat(comparisonTokens...)
-		right, err := p.addition()
+		right, err := p.rangeExpr()
 
 		if err != nil {
 			return nil, err
@@ -524,6 +842,39 @@ func (p *parser) comparison() (ast.Expr, error) {
 	return left, nil
 }
 
+func (p *parser) rangeExpr() (ast.Expr, error) {
+	start, err := p.addition()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.is(rangeTokens...) {
+		return start, nil
+	}
+
+	op, _ := p.eat(rangeTokens...)
+	end, err := p.addition()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Step is optional; when absent the interpreter infers it from the
+	// direction of the range
+	var step ast.Expr
+
+	if p.stepModifier() {
+		step, err = p.addition()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ast.RangeExpr{Start: start, End: end, Step: step, Operator: op}, nil
+}
+
 func (p *parser) addition() (ast.Expr, error) {
 	l, err := p.multiplication()
 
@@ -600,8 +951,8 @@ func (p *parser) exponentiation() (ast.Expr, error) {
 }
 
 func (p *parser) unary() (ast.Expr, error) {
-	if p.is(tokentype.MINUS) {
-		op, _ := p.eat(tokentype.MINUS)
+	if p.is(tokentype.MINUS, tokentype.BANG) {
+		op, _ := p.eat(tokentype.MINUS, tokentype.BANG)
 		expr, err := p.unary()
 
 		if err != nil {
@@ -648,6 +999,9 @@ func (p *parser) callOrGet() (ast.Expr, error) {
 	// we loop through so long as we have a left paren to
 	// consume and nest the functions together.
 	for {
+		// The paren or arrow, kept to place runtime errors
+		op := p.current()
+
 		if p.isThenEat(tokentype.LEFT_PAREN) {
 			args := []ast.Expr{}
 
@@ -664,7 +1018,7 @@ func (p *parser) callOrGet() (ast.Expr, error) {
 				p.isThenEat(tokentype.COMMA)
 			}
 
-			expr = ast.CallExpr{Callee: expr, Arguments: args}
+			expr = ast.CallExpr{Callee: expr, Paren: op, Arguments: args}
 
 			continue
 		}
@@ -676,7 +1030,7 @@ func (p *parser) callOrGet() (ast.Expr, error) {
 				return nil, err
 			}
 
-			expr = ast.GetExpr{Gettee: expr, Field: field}
+			expr = ast.GetExpr{Gettee: expr, Arrow: op, Field: field}
 
 			continue
 		}
@@ -761,6 +1115,13 @@ func (p *parser) function() (ast.Expr, error) {
 
 	var body ast.Block
 
+	// Yields in the body make this function a generator, but not the one
+	// it is nested in
+	yielded := p.yielded
+	p.yielded = false
+
+	defer func() { p.yielded = yielded }()
+
 	// Get function body
 	if p.isThenEat(tokentype.MINUS_GREAT) {
 		expr, err := p.expression()
@@ -780,7 +1141,18 @@ func (p *parser) function() (ast.Expr, error) {
 		}
 	}
 
-	return ast.FuncExpr{Params: idents, Body: body, Depth: depth}, nil
+	return ast.FuncExpr{Params: idents, Body: body, Depth: depth, Generator: p.yielded}, nil
+}
+
+func (p *parser) loop(label *tokens.Token) (ast.Expr, error) {
+	defer p.enterLoop(label)()
+	body, err := p.blockStmt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.LoopExpr{Label: label, Body: body}, nil
 }
 
 func (p *parser) tuple() (ast.Expr, error) {
@@ -803,8 +1175,47 @@ func (p *parser) tuple() (ast.Expr, error) {
 	return ast.TupleLiteralExpr{Contents: items}, nil
 }
 
+func (p *parser) set() (ast.Expr, error) {
+	if p.isThenEat(tokentype.RIGHT_BRACE) {
+		return ast.SetLiteralExpr{}, nil
+	}
+
+	items, err := p.commaExpressions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.eat(tokentype.RIGHT_BRACE)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ast.SetLiteralExpr{Contents: items}, nil
+}
+
 func (p *parser) proto() (ast.Expr, error) {
-	_, err := p.eat(tokentype.LEFT_BRACE)
+	var parents, protocols []ast.Expr
+	var err error
+
+	if p.isThenEat(tokentype.EXTENDS) {
+		parents, err = p.protoRefs()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isThenEat(tokentype.IMPLEMENTS) {
+		protocols, err = p.protoRefs()
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.eat(tokentype.LEFT_BRACE)
 
 	if err != nil {
 		return nil, err
@@ -822,7 +1233,7 @@ func (p *parser) proto() (ast.Expr, error) {
 		return nil, err
 	}
 
-	return ast.ProtoExpr{MethodSet: ms}, nil
+	return ast.ProtoExpr{MethodSet: ms, Parents: parents, Protocols: protocols}, nil
 }
 
 func (p *parser) record() (ast.Expr, error) {
@@ -882,11 +1293,32 @@ func (p *parser) fundamental() (ast.Expr, error) {
 		return ast.StringLiteralExpr{Value: s}, nil
 	}
 
+	if p.is(tokentype.BYTES) {
+		b, _ := p.eat(tokentype.BYTES)
+		return ast.BytesLiteralExpr{Value: b}, nil
+	}
+
+	if p.is(tokentype.REGEX) {
+		r, _ := p.eat(tokentype.REGEX)
+		return ast.RegexLiteralExpr{Value: r}, nil
+	}
+
 	if p.is(tokentype.BOTTOM) {
 		s, _ := p.eat(tokentype.BOTTOM)
 		return ast.BottomLiteralExpr{Token: s}, nil
 	}
 
+	if p.isLabel() {
+		label, _ := p.eat(tokentype.IDENTIFIER)
+		p.eat(tokentype.COLON)
+
+		if !p.isThenEat(tokentype.LOOP) {
+			return nil, errors.ParseError{Msg: fmt.Sprintf("Labels can only be applied to loops at %d:%d", label.Position.Row, label.Position.Col)}
+		}
+
+		return p.loop(&label)
+	}
+
 	if p.is(tokentype.IDENTIFIER) {
 		s, _ := p.eat(tokentype.IDENTIFIER)
 		return ast.IdentifierExpr{Name: s}, nil
@@ -910,6 +1342,11 @@ func (p *parser) fundamental() (ast.Expr, error) {
 		return ast.MeExpr{Token: s}, nil
 	}
 
+	if p.is(tokentype.SUPER) {
+		s, _ := p.eat(tokentype.SUPER)
+		return ast.SuperExpr{Token: s}, nil
+	}
+
 	if p.is(tokentype.QUESTION) {
 		q, _ := p.eat(tokentype.QUESTION)
 		return ast.QuestionExpr{Token: q}, nil
@@ -919,10 +1356,18 @@ func (p *parser) fundamental() (ast.Expr, error) {
 		return p.proto()
 	}
 
+	if p.isThenEat(tokentype.LOOP) {
+		return p.loop(nil)
+	}
+
 	if p.isThenEat(tokentype.LEFT_BRACE) {
 		return p.record()
 	}
 
+	if p.isThenEat(tokentype.HASH_BRACE) {
+		return p.set()
+	}
+
 	t := p.tokens[p.i]
 	return nil, errors.ParseError{Msg: fmt.Sprintf("Malformed expression at %d: %d", t.Position.Row, t.Position.Col)}
 }
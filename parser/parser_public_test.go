@@ -64,6 +64,20 @@ func nodesAreEqual(a ast.Node, b ast.Node) bool {
 		return tA5.Value.Lexeme == tB5.Value.Lexeme
 	}
 
+	tABytes, okA := a.(ast.BytesLiteralExpr)
+	tBBytes, okB := b.(ast.BytesLiteralExpr)
+
+	if okA && okB {
+		return tABytes.Value.Lexeme == tBBytes.Value.Lexeme
+	}
+
+	tARegex, okA := a.(ast.RegexLiteralExpr)
+	tBRegex, okB := b.(ast.RegexLiteralExpr)
+
+	if okA && okB {
+		return tARegex.Value.Lexeme == tBRegex.Value.Lexeme
+	}
+
 	_, okA = a.(ast.BottomLiteralExpr)
 	_, okB = b.(ast.BottomLiteralExpr)
 
@@ -166,6 +180,10 @@ func nodesAreEqual(a ast.Node, b ast.Node) bool {
 	tB12, okB := b.(ast.FuncExpr)
 
 	if okA && okB {
+		if tA12.Generator != tB12.Generator {
+			return false
+		}
+
 		for i, v := range tA12.Params {
 			if v.Name.Lexeme != tB12.Params[i].Name.Lexeme || v.Mut != tB12.Params[i].Mut {
 				return false
@@ -218,6 +236,23 @@ func nodesAreEqual(a ast.Node, b ast.Node) bool {
 		return true
 	}
 
+	tASet, okA := a.(ast.SetLiteralExpr)
+	tBSet, okB := b.(ast.SetLiteralExpr)
+
+	if okA && okB {
+		if len(tASet.Contents) != len(tBSet.Contents) {
+			return false
+		}
+
+		for i, e := range tASet.Contents {
+			if !nodesAreEqual(e, tBSet.Contents[i]) {
+				return false
+			}
+		}
+
+		return true
+	}
+
 	_, okA = a.(ast.MeExpr)
 	_, okB = b.(ast.MeExpr)
 
@@ -225,16 +260,38 @@ func nodesAreEqual(a ast.Node, b ast.Node) bool {
 		return true
 	}
 
+	_, okA = a.(ast.SuperExpr)
+	_, okB = b.(ast.SuperExpr)
+
+	if okA && okB {
+		return true
+	}
+
 	tA16, okA := a.(ast.ProtoExpr)
 	tB16, okB := b.(ast.ProtoExpr)
 
 	if okA && okB {
-		if len(tA16.MethodSet) != len(tB16.MethodSet) {
+		if len(tA16.MethodSet) != len(tB16.MethodSet) ||
+			len(tA16.Parents) != len(tB16.Parents) ||
+			len(tA16.Protocols) != len(tB16.Protocols) {
 			return false
 		}
 
+		for i, p := range tA16.Parents {
+			if !nodesAreEqual(p, tB16.Parents[i]) {
+				return false
+			}
+		}
+
+		for i, p := range tA16.Protocols {
+			if !nodesAreEqual(p, tB16.Protocols[i]) {
+				return false
+			}
+		}
+
 		for i, m := range tA16.MethodSet {
-			if !nodesAreEqual(m.K, tB16.MethodSet[i].K) ||
+			if m.I != tB16.MethodSet[i].I || m.G != tB16.MethodSet[i].G ||
+				!nodesAreEqual(m.K, tB16.MethodSet[i].K) ||
 				!nodesAreEqual(m.M, tB16.MethodSet[i].M) {
 				return false
 			}
@@ -255,25 +312,89 @@ func nodesAreEqual(a ast.Node, b ast.Node) bool {
 	tB18, okB := b.(ast.WhileStmt)
 
 	if okA && okB {
-		return nodesAreEqual(tA18.Decls, tB18.Decls) &&
+		return labelsAreEqual(tA18.Label, tB18.Label) &&
+			nodesAreEqual(tA18.Decls, tB18.Decls) &&
 			nodesAreEqual(tA18.Condition, tB18.Condition) &&
 			nodesAreEqual(tA18.Block, tB18.Block)
 	}
 
-	_, okA = a.(ast.BreakStmt)
-	_, okB = b.(ast.BreakStmt)
+	tA22, okA := a.(ast.BreakStmt)
+	tB22, okB := b.(ast.BreakStmt)
 
 	if okA && okB {
-		return true
+		return labelsAreEqual(tA22.Label, tB22.Label) && nodesAreEqual(tA22.Expr, tB22.Expr)
+	}
+
+	tA23, okA := a.(ast.ContinueStmt)
+	tB23, okB := b.(ast.ContinueStmt)
+
+	if okA && okB {
+		return labelsAreEqual(tA23.Label, tB23.Label)
+	}
+
+	tA25, okA := a.(ast.DeferStmt)
+	tB25, okB := b.(ast.DeferStmt)
+
+	if okA && okB {
+		return nodesAreEqual(tA25.Expr, tB25.Expr)
+	}
+
+	tAYield, okA := a.(ast.YieldStmt)
+	tBYield, okB := b.(ast.YieldStmt)
+
+	if okA && okB {
+		return nodesAreEqual(tAYield.Expr, tBYield.Expr)
 	}
 
-	_, okA = a.(ast.ContinueStmt)
-	_, okB = b.(ast.ContinueStmt)
+	tA26, okA := a.(ast.ThrowStmt)
+	tB26, okB := b.(ast.ThrowStmt)
 
 	if okA && okB {
+		return nodesAreEqual(tA26.Expr, tB26.Expr) && nodesAreEqual(tA26.Data, tB26.Data)
+	}
+
+	tA29, okA := a.(ast.ProtocolStmt)
+	tB29, okB := b.(ast.ProtocolStmt)
+
+	if okA && okB {
+		if tA29.Name.Lexeme != tB29.Name.Lexeme || len(tA29.Methods) != len(tB29.Methods) {
+			return false
+		}
+
+		for i, m := range tA29.Methods {
+			if m.Arity.Lexeme != tB29.Methods[i].Arity.Lexeme || !nodesAreEqual(m.K, tB29.Methods[i].K) {
+				return false
+			}
+		}
+
 		return true
 	}
 
+	tA28, okA := a.(ast.AssertStmt)
+	tB28, okB := b.(ast.AssertStmt)
+
+	if okA && okB {
+		return tA28.Source == tB28.Source &&
+			nodesAreEqual(tA28.Expr, tB28.Expr) &&
+			nodesAreEqual(tA28.Message, tB28.Message)
+	}
+
+	tA27, okA := a.(ast.TryStmt)
+	tB27, okB := b.(ast.TryStmt)
+
+	if okA && okB {
+		return labelsAreEqual(tA27.Name, tB27.Name) &&
+			nodesAreEqual(tA27.Body, tB27.Body) &&
+			nodesAreEqual(tA27.Catch, tB27.Catch)
+	}
+
+	tA24, okA := a.(ast.LoopExpr)
+	tB24, okB := b.(ast.LoopExpr)
+
+	if okA && okB {
+		return labelsAreEqual(tA24.Label, tB24.Label) && nodesAreEqual(tA24.Body, tB24.Body)
+	}
+
 	tA19, okA := a.(ast.RecordLiteralExpr)
 	tB19, okB := b.(ast.RecordLiteralExpr)
 
@@ -309,9 +430,27 @@ func nodesAreEqual(a ast.Node, b ast.Node) bool {
 		return nodesAreEqual(tA20.Expr, tB20.Expr)
 	}
 
+	tA21, okA := a.(ast.RangeExpr)
+	tB21, okB := b.(ast.RangeExpr)
+
+	if okA && okB {
+		return tA21.Operator.Type == tB21.Operator.Type &&
+			nodesAreEqual(tA21.Start, tB21.Start) &&
+			nodesAreEqual(tA21.End, tB21.End) &&
+			nodesAreEqual(tA21.Step, tB21.Step)
+	}
+
 	return false
 }
 
+func labelsAreEqual(a *tokens.Token, b *tokens.Token) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Lexeme == b.Lexeme
+}
+
 func astsAreEqual(as []ast.Node, bs []ast.Node) bool {
 	if len(as) != len(bs) {
 		return false
@@ -329,6 +468,9 @@ func astsAreEqual(as []ast.Node, bs []ast.Node) bool {
 }
 
 func TestParse(t *testing.T) {
+	outerLabel := tokens.New(tokentype.IDENTIFIER, "outer", 0, 0)
+	errLabel := tokens.New(tokentype.IDENTIFIER, "e", 0, 0)
+
 	t.Run("productions", func(t *testing.T) {
 		table := []struct {
 			name     string
@@ -345,6 +487,16 @@ func TestParse(t *testing.T) {
 				text:     "\"abc\"",
 				expected: []ast.Node{ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "\"abc\"", 0, 0)}},
 			},
+			{
+				name:     "fundamental bytes",
+				text:     "x'00ff'",
+				expected: []ast.Node{ast.BytesLiteralExpr{Value: tokens.New(tokentype.BYTES, "x'00ff'", 0, 0)}},
+			},
+			{
+				name:     "fundamental regex",
+				text:     "r'a+'",
+				expected: []ast.Node{ast.RegexLiteralExpr{Value: tokens.New(tokentype.REGEX, "r'a+'", 0, 0)}},
+			},
 			{
 				name:     "fundamental number",
 				text:     "123",
@@ -481,6 +633,25 @@ func TestParse(t *testing.T) {
 					},
 				},
 			},
+			{
+				name: "fundamental set",
+				text: "#{1, a...}",
+				expected: []ast.Node{
+					ast.SetLiteralExpr{
+						Contents: []ast.Expr{
+							ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)},
+							ast.SpreadExpr{Expr: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)}},
+						},
+					},
+				},
+			},
+			{
+				name: "fundamental empty set",
+				text: "#{}",
+				expected: []ast.Node{
+					ast.SetLiteralExpr{},
+				},
+			},
 			{
 				name: "fundamental tuple 3",
 				text: "[1+2, a, 'a']",
@@ -513,6 +684,52 @@ func TestParse(t *testing.T) {
 					},
 				},
 			},
+			{
+				name: "range expression 1",
+				text: "0..10",
+				expected: []ast.Node{
+					ast.RangeExpr{
+						Start:    ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "0", 0, 0)},
+						End:      ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "10", 0, 0)},
+						Operator: tokens.New(tokentype.DOT_DOT, "..", 0, 0),
+					},
+				},
+			},
+			{
+				name: "range expression 2",
+				text: "a..=b + 1 step 2",
+				expected: []ast.Node{
+					ast.RangeExpr{
+						Start: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+						End: ast.BinaryExpr{
+							Left:     ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "b", 0, 0)},
+							Right:    ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)},
+							Operator: tokens.New(tokentype.PLUS, "+", 0, 0),
+						},
+						Step:     ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "2", 0, 0)},
+						Operator: tokens.New(tokentype.DOT_DOT_EQUAL, "..=", 0, 0),
+					},
+				},
+			},
+			{
+				name: "range expression 3",
+				text: "[(0..3)...]",
+				expected: []ast.Node{
+					ast.TupleLiteralExpr{
+						Contents: []ast.Expr{
+							ast.SpreadExpr{
+								Expr: ast.GroupingExpr{
+									Expr: ast.RangeExpr{
+										Start:    ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "0", 0, 0)},
+										End:      ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "3", 0, 0)},
+										Operator: tokens.New(tokentype.DOT_DOT, "..", 0, 0),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
 			{
 				name: "funamental me",
 				text: "me",
@@ -583,6 +800,198 @@ func TestParse(t *testing.T) {
 					},
 				},
 			},
+			{
+				name: "get as a variable",
+				text: "let get = 1;",
+				expected: []ast.Node{
+					ast.VarDeclStmt{
+						Names:  []ast.Identifier{{Name: tokens.New(tokentype.IDENTIFIER, "get", 0, 0)}},
+						Values: []ast.Expr{ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+					},
+				},
+			},
+			{
+				name: "step as a variable",
+				text: "let step = 1;",
+				expected: []ast.Node{
+					ast.VarDeclStmt{
+						Names:  []ast.Identifier{{Name: tokens.New(tokentype.IDENTIFIER, "step", 0, 0)}},
+						Values: []ast.Expr{ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+					},
+				},
+			},
+			{
+				name: "step as the step of a range",
+				text: "0..10 step step",
+				expected: []ast.Node{
+					ast.RangeExpr{
+						Start:    ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "0", 0, 0)},
+						End:      ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "10", 0, 0)},
+						Step:     ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "step", 0, 0)},
+						Operator: tokens.New(tokentype.DOT_DOT, "..", 0, 0),
+					},
+				},
+			},
+			{
+				name: "get as a plain proto member",
+				text: "proto { 'a' -> get }",
+				expected: []ast.Node{
+					ast.ProtoExpr{
+						MethodSet: []ast.ProtoMethod{
+							{
+								K: ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'a'", 0, 0)},
+								M: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "get", 0, 0)},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "proto with getters",
+				text: "proto { 4 -> get fn () -> 1, 5 ->< get fn () -> 2 }",
+				expected: []ast.Node{
+					ast.ProtoExpr{
+						MethodSet: []ast.ProtoMethod{
+							{
+								K: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "4", 0, 0)},
+								M: ast.FuncExpr{Body: ast.Block{
+									Contents: []ast.Node{
+										ast.ReturnStmt{Expr: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+									},
+								}},
+								G: true,
+							},
+							{
+								K: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "5", 0, 0)},
+								M: ast.FuncExpr{Body: ast.Block{
+									Contents: []ast.Node{
+										ast.ReturnStmt{Expr: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "2", 0, 0)}},
+									},
+								}},
+								I: true,
+								G: true,
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "proto extending several protos",
+				text: "proto extends a, b { 4 -> 1 }",
+				expected: []ast.Node{
+					ast.ProtoExpr{
+						Parents: []ast.Expr{
+							ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+							ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "b", 0, 0)},
+						},
+						MethodSet: []ast.ProtoMethod{
+							{
+								K: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "4", 0, 0)},
+								M: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "proto implementing protocols",
+				text: "proto extends a implements b, c { 4 -> 1 }",
+				expected: []ast.Node{
+					ast.ProtoExpr{
+						Parents: []ast.Expr{
+							ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+						},
+						Protocols: []ast.Expr{
+							ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "b", 0, 0)},
+							ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "c", 0, 0)},
+						},
+						MethodSet: []ast.ProtoMethod{
+							{
+								K: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "4", 0, 0)},
+								M: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "protocol statement",
+				text: "protocol Shape { 'stringify' -> 0, 'compare' -> 1 }",
+				expected: []ast.Node{
+					ast.ProtocolStmt{
+						Name: tokens.New(tokentype.IDENTIFIER, "Shape", 0, 0),
+						Methods: []ast.ProtocolMethod{
+							{
+								K:     ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'stringify'", 0, 0)},
+								Arity: tokens.New(tokentype.NUMBER, "0", 0, 0),
+							},
+							{
+								K:     ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'compare'", 0, 0)},
+								Arity: tokens.New(tokentype.NUMBER, "1", 0, 0),
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "proto with plain members",
+				text: "proto { 'a' -> 2, 'b' -> proto { 'c' -> fn () -> 1 } }",
+				expected: []ast.Node{
+					ast.ProtoExpr{
+						MethodSet: []ast.ProtoMethod{
+							{
+								K: ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'a'", 0, 0)},
+								M: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "2", 0, 0)},
+							},
+							{
+								K: ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'b'", 0, 0)},
+								M: ast.ProtoExpr{
+									MethodSet: []ast.ProtoMethod{
+										{
+											K: ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'c'", 0, 0)},
+											M: ast.FuncExpr{Body: ast.Block{
+												Contents: []ast.Node{
+													ast.ReturnStmt{Expr: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+												},
+											}},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			{
+				name: "proto extending another proto",
+				text: "proto extends a->'b' { 4 -> fn () -> super->4() }",
+				expected: []ast.Node{
+					ast.ProtoExpr{
+						Parents: []ast.Expr{
+							ast.GetExpr{
+								Gettee: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+								Field:  ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'b'", 0, 0)},
+							},
+						},
+						MethodSet: []ast.ProtoMethod{
+							{
+								K: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "4", 0, 0)},
+								M: ast.FuncExpr{Body: ast.Block{
+									Contents: []ast.Node{
+										ast.ReturnStmt{Expr: ast.CallExpr{
+											Callee: ast.GetExpr{
+												Gettee: ast.SuperExpr{Token: tokens.New(tokentype.SUPER, "super", 0, 0)},
+												Field:  ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "4", 0, 0)},
+											},
+											Arguments: []ast.Expr{},
+										}},
+									},
+								}},
+							},
+						},
+					},
+				},
+			},
 			{
 				name: "fundamental record 1",
 				text: "{}",
@@ -961,6 +1370,16 @@ func TestParse(t *testing.T) {
 					},
 				},
 			},
+			{
+				name: "unary not",
+				text: "!true",
+				expected: []ast.Node{
+					ast.UnaryExpr{
+						Operator: tokens.New(tokentype.BANG, "!", 0, 0),
+						Expr:     ast.BooleanLiteralExpr{Value: tokens.New(tokentype.TRUE, "true", 0, 0)},
+					},
+				},
+			},
 			{
 				name: "binary exponentiation",
 				text: "1 ** 2",
@@ -1338,6 +1757,245 @@ func TestParse(t *testing.T) {
 					ast.ContinueStmt{},
 				},
 			},
+			{
+				name: "defer",
+				text: "defer a(1);",
+				expected: []ast.Node{
+					ast.DeferStmt{
+						Expr: ast.CallExpr{
+							Callee:    ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+							Arguments: []ast.Expr{ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+						},
+					},
+				},
+			},
+			{
+				name: "yield",
+				text: "yield a;",
+				expected: []ast.Node{
+					ast.YieldStmt{Expr: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)}},
+				},
+			},
+			{
+				name: "functions that yield are generators",
+				text: "fn () { yield 1; }",
+				expected: []ast.Node{
+					ast.FuncExpr{
+						Generator: true,
+						Body: ast.Block{Contents: []ast.Node{
+							ast.YieldStmt{Expr: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+						}},
+					},
+				},
+			},
+			{
+				name: "yields in nested functions do not make the outer function a generator",
+				text: "fn () { fn () { yield 1; } }",
+				expected: []ast.Node{
+					ast.FuncExpr{
+						Body: ast.Block{Contents: []ast.Node{
+							ast.FuncExpr{
+								Generator: true,
+								Body: ast.Block{Contents: []ast.Node{
+									ast.YieldStmt{Expr: ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+								}},
+							},
+						}},
+					},
+				},
+			},
+			{
+				name: "throw",
+				text: "throw 'a';",
+				expected: []ast.Node{
+					ast.ThrowStmt{Expr: ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'a'", 0, 0)}},
+				},
+			},
+			{
+				name: "throw with data",
+				text: "throw 'a', [1];",
+				expected: []ast.Node{
+					ast.ThrowStmt{
+						Expr: ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'a'", 0, 0)},
+						Data: ast.TupleLiteralExpr{
+							Contents: []ast.Expr{ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+						},
+					},
+				},
+			},
+			{
+				name: "assert",
+				text: "assert a;",
+				expected: []ast.Node{
+					ast.AssertStmt{
+						Expr:   ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+						Source: "a",
+					},
+				},
+			},
+			{
+				name: "assert with message",
+				text: "assert a  ==(b + 1), 'oops';",
+				expected: []ast.Node{
+					ast.AssertStmt{
+						Expr: ast.BinaryExpr{
+							Left:     ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+							Operator: tokens.New(tokentype.EQUAL_EQUAL, "==", 0, 0),
+							Right: ast.GroupingExpr{
+								Expr: ast.BinaryExpr{
+									Left:     ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "b", 0, 0)},
+									Operator: tokens.New(tokentype.PLUS, "+", 0, 0),
+									Right:    ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)},
+								},
+							},
+						},
+						Message: ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'oops'", 0, 0)},
+						Source:  "a  ==(b + 1)",
+					},
+				},
+			},
+			{
+				name: "assert spanning lines",
+				text: "assert a\n&& 'b' == b;",
+				expected: []ast.Node{
+					ast.AssertStmt{
+						Expr: ast.BinaryExpr{
+							Left:     ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+							Operator: tokens.New(tokentype.AMPERSAND_AMPERSAND, "&&", 0, 0),
+							Right: ast.BinaryExpr{
+								Left:     ast.StringLiteralExpr{Value: tokens.New(tokentype.STRING, "'b'", 0, 0)},
+								Operator: tokens.New(tokentype.EQUAL_EQUAL, "==", 0, 0),
+								Right:    ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "b", 0, 0)},
+							},
+						},
+						Source: "a && 'b' == b",
+					},
+				},
+			},
+			{
+				name: "try/catch",
+				text: "try { 1 } catch e { e }",
+				expected: []ast.Node{
+					ast.TryStmt{
+						Body: ast.Block{
+							Contents: []ast.Node{ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)}},
+						},
+						Name: &errLabel,
+						Catch: ast.Block{
+							Contents: []ast.Node{ast.IdentifierExpr{Name: errLabel}},
+						},
+					},
+				},
+			},
+			{
+				name: "try/catch without binding",
+				text: "try {} catch {}",
+				expected: []ast.Node{
+					ast.TryStmt{Body: ast.Block{}, Catch: ast.Block{}},
+				},
+			},
+			{
+				name: "labeled while",
+				text: "outer: while true { break outer; }",
+				expected: []ast.Node{
+					ast.WhileStmt{
+						Label:     &outerLabel,
+						Decls:     ast.VarDeclStmt{},
+						Condition: ast.BooleanLiteralExpr{Value: tokens.New(tokentype.TRUE, "true", 0, 0)},
+						Block: ast.Block{
+							Contents: []ast.Node{ast.BreakStmt{Label: &outerLabel}},
+						},
+					},
+				},
+			},
+			{
+				name: "labeled continue",
+				text: "continue outer;",
+				expected: []ast.Node{
+					ast.ContinueStmt{Label: &outerLabel},
+				},
+			},
+			{
+				name: "break with value",
+				text: "break 1 + 2;",
+				expected: []ast.Node{
+					ast.BreakStmt{
+						Expr: ast.BinaryExpr{
+							Left:     ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "1", 0, 0)},
+							Right:    ast.NumericLiteralExpr{Value: tokens.New(tokentype.NUMBER, "2", 0, 0)},
+							Operator: tokens.New(tokentype.PLUS, "+", 0, 0),
+						},
+					},
+				},
+			},
+			{
+				name: "break with identifier value",
+				text: "break (a);",
+				expected: []ast.Node{
+					ast.BreakStmt{
+						Expr: ast.GroupingExpr{Expr: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)}},
+					},
+				},
+			},
+			{
+				name: "labeled break with value",
+				text: "outer: loop { break outer a; }",
+				expected: []ast.Node{
+					ast.LoopExpr{
+						Label: &outerLabel,
+						Body: ast.Block{Contents: []ast.Node{
+							ast.BreakStmt{
+								Label: &outerLabel,
+								Expr:  ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)},
+							},
+						}},
+					},
+				},
+			},
+			{
+				name: "break with the value of a variable",
+				text: "loop { break a; }",
+				expected: []ast.Node{
+					ast.LoopExpr{
+						Body: ast.Block{Contents: []ast.Node{
+							ast.BreakStmt{Expr: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0)}},
+						}},
+					},
+				},
+			},
+			{
+				name: "break with the value of a variable named like a label out of scope",
+				text: "outer: loop {} loop { break outer; }",
+				expected: []ast.Node{
+					ast.LoopExpr{Label: &outerLabel, Body: ast.Block{}},
+					ast.LoopExpr{
+						Body: ast.Block{Contents: []ast.Node{
+							ast.BreakStmt{Expr: ast.IdentifierExpr{Name: tokens.New(tokentype.IDENTIFIER, "outer", 0, 0)}},
+						}},
+					},
+				},
+			},
+			{
+				name: "loop expression",
+				text: "loop { break; }",
+				expected: []ast.Node{
+					ast.LoopExpr{
+						Body: ast.Block{Contents: []ast.Node{ast.BreakStmt{}}},
+					},
+				},
+			},
+			{
+				name: "labeled loop expression",
+				text: "let a = outer: loop {};",
+				expected: []ast.Node{
+					ast.VarDeclStmt{
+						Names: []ast.Identifier{
+							{Name: tokens.New(tokentype.IDENTIFIER, "a", 0, 0), Mut: false},
+						},
+						Values: []ast.Expr{ast.LoopExpr{Label: &outerLabel, Body: ast.Block{}}},
+					},
+				},
+			},
 		}
 
 		for _, e := range table {
@@ -1594,12 +2252,43 @@ func TestParse(t *testing.T) {
 			{name: "malformed proto expression 1", text: "proto { }"},
 			{name: "malformed proto expression 2", text: "proto 'a' -> fn () -> 1 }"},
 			{name: "malformed proto expression 3", text: "proto { 'a' -> fn () -> 1"},
-			{name: "malformed proto expression 4", text: "proto { 'a' -> 2 }"},
-			{name: "malformed proto expression 5", text: "proto { 'a' -> fn () -> 1, 'b' -> 3 }"},
+			{name: "malformed proto expression 4", text: "proto { 'a' ->< 2 }"},
+			{name: "malformed proto expression 5", text: "proto { 'a' -> fn () -> 1, 'b' -> }"},
 			{name: "malformed proto expression 6", text: "proto { 'a' fn () -> 1 }"},
 			{name: "malformed proto expression 7", text: "proto { 'a' -> fn -> 1 }"},
+			{name: "malformed proto expression 10", text: "proto { 'a' -> get 1 }"},
+			{name: "malformed proto expression 12", text: "proto extends a, { 'a' -> 1 }"},
+			{name: "malformed proto expression 13", text: "proto implements { 'a' -> 1 }"},
+			{name: "malformed proto expression 14", text: "proto implements a extends b { 'a' -> 1 }"},
+			{name: "malformed protocol 1", text: "protocol { 'a' -> 1 }"},
+			{name: "malformed protocol 2", text: "protocol A { 'a' -> b }"},
+			{name: "malformed protocol 3", text: "protocol A { }"},
+			{name: "malformed protocol 4", text: "protocol A { 'a' -> 1, }"},
+			{name: "malformed proto expression 8", text: "proto extends { 'a' -> fn () -> 1 }"},
+			{name: "malformed proto expression 9", text: "proto extends a 'a' -> fn () -> 1 }"},
+			{name: "malformed range expression 1", text: "0.."},
+			{name: "malformed range expression 2", text: "0..10 step"},
+			{name: "break to an unknown label with a value", text: "loop { break typo 1; }"},
 			{name: "malformed break", text: "break"},
 			{name: "malformed continue", text: "continue"},
+			{name: "malformed labeled continue", text: "continue outer 1;"},
+			{name: "malformed loop", text: "loop"},
+			{name: "malformed throw 1", text: "throw;"},
+			{name: "malformed throw 2", text: "throw 'a'"},
+			{name: "malformed throw 3", text: "throw 'a', ;"},
+			{name: "malformed assert 1", text: "assert;"},
+			{name: "malformed assert 2", text: "assert a"},
+			{name: "malformed assert 3", text: "assert a, ;"},
+			{name: "malformed unary not", text: "!"},
+			{name: "malformed try 1", text: "try {}"},
+			{name: "malformed try 2", text: "try {} catch"},
+			{name: "malformed try 3", text: "try catch {}"},
+			{name: "malformed defer 1", text: "defer a()"},
+			{name: "malformed defer 2", text: "defer;"},
+			{name: "malformed yield 1", text: "yield a"},
+			{name: "malformed yield 2", text: "yield;"},
+			{name: "label on non-loop", text: "outer: 1"},
+			{name: "label on if statement", text: "outer: if true {}"},
 		}
 
 		for _, e := range table {
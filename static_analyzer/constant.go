@@ -0,0 +1,143 @@
+package staticanalyzer
+
+import (
+	"calabash/ast"
+	"calabash/internal/tokentype"
+	"calabash/internal/value"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+type bottom struct{}
+
+// literal resolves an expression built only from literals to its Go value
+func literal(e ast.Expr) (interface{}, bool) {
+	switch e := e.(type) {
+	case ast.GroupingExpr:
+		return literal(e.Expr)
+
+	case ast.NumericLiteralExpr:
+		// Integers and floats that are equal compare equal, so both fold to
+		// floats, except for integer literals a float cannot hold exactly
+		if !strings.Contains(e.Value.Lexeme, ".") {
+			i, ok := new(big.Int).SetString(e.Value.Lexeme, 10)
+
+			if !ok {
+				return nil, false
+			}
+
+			n, acc := new(big.Float).SetInt(i).Float64()
+
+			return n, acc == big.Exact
+		}
+
+		n, err := strconv.ParseFloat(e.Value.Lexeme, 64)
+
+		return n, err == nil
+
+	case ast.StringLiteralExpr:
+		rs := []rune(e.Value.Lexeme)
+
+		return string(rs[1 : len(rs)-1]), true
+
+	case ast.BooleanLiteralExpr:
+		return e.Value.Type == tokentype.TRUE, true
+
+	case ast.BottomLiteralExpr:
+		return bottom{}, true
+
+	case ast.UnaryExpr:
+		v, ok := literal(e.Expr)
+
+		if !ok {
+			return nil, false
+		}
+
+		switch v := v.(type) {
+		case float64:
+			if e.Operator.Type == tokentype.MINUS {
+				return -v, true
+			}
+
+		case bool:
+			if e.Operator.Type == tokentype.BANG {
+				return !v, true
+			}
+		}
+
+	case ast.BinaryExpr:
+		return constantBinary(e)
+	}
+
+	return nil, false
+}
+
+// displayConstant renders a constant the way the interpreter displays the
+// value it stands for, so that static and runtime errors name it alike
+func displayConstant(c interface{}) string {
+	switch c := c.(type) {
+	case string:
+		return value.Display(value.NewString(c))
+
+	case bottom:
+		return value.Display(&value.Bottom{})
+	}
+
+	return fmt.Sprint(c)
+}
+
+func constantBinary(e ast.BinaryExpr) (interface{}, bool) {
+	l, okl := literal(e.Left)
+	r, okr := literal(e.Right)
+
+	if !okl || !okr {
+		return nil, false
+	}
+
+	switch e.Operator.Type {
+	case tokentype.EQUAL_EQUAL:
+		return l == r, true
+
+	case tokentype.BANG_EQUAL:
+		return l != r, true
+
+	case tokentype.AMPERSAND_AMPERSAND, tokentype.STROKE_STROKE:
+		lb, okl := l.(bool)
+		rb, okr := r.(bool)
+
+		if !okl || !okr {
+			return nil, false
+		}
+
+		if e.Operator.Type == tokentype.AMPERSAND_AMPERSAND {
+			return lb && rb, true
+		}
+
+		return lb || rb, true
+	}
+
+	ln, okl := l.(float64)
+	rn, okr := r.(float64)
+
+	if !okl || !okr {
+		return nil, false
+	}
+
+	switch e.Operator.Type {
+	case tokentype.LESS:
+		return ln < rn, true
+
+	case tokentype.LESS_EQUAL:
+		return ln <= rn, true
+
+	case tokentype.GREAT:
+		return ln > rn, true
+
+	case tokentype.GREAT_EQUAL:
+		return ln >= rn, true
+	}
+
+	return nil, false
+}
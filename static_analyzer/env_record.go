@@ -1,5 +1,13 @@
 package staticanalyzer
 
+import "calabash/ast"
+
 type identRecord struct {
-	mut bool
+	mut      bool
+	protocol *ast.ProtocolStmt // Set when the identifier was declared by a protocol statement
+}
+
+type loopRecord struct {
+	label string
+	loc   staticloc
 }
@@ -5,7 +5,9 @@ import (
 	"calabash/errors"
 	"calabash/internal/environment"
 	"calabash/internal/stack"
+	"calabash/internal/value"
 	"calabash/internal/visitor"
+	"calabash/lexer/tokens"
 	"fmt"
 	"strconv"
 )
@@ -19,7 +21,9 @@ const (
 	proto_method
 	pipe
 	while
+	loop
 	tuple
+	set
 	call
 )
 
@@ -30,7 +34,9 @@ const (
 type analyzer struct {
 	env           *environment.Environment[identRecord]
 	loc           *stack.Stack[staticloc]
+	labels        *stack.Stack[loopRecord]
 	satisfactions *stack.Stack[satisfaction]
+	resumable     map[tokens.Token]bool // Yields a generator can pause at
 }
 
 func (a *analyzer) Analyze(ast []ast.Node) error {
@@ -59,6 +65,79 @@ func (a *analyzer) endScope() {
 	a.env = a.env.Parent
 }
 
+func (a *analyzer) enterLoop(label *tokens.Token, l staticloc) {
+	r := loopRecord{loc: l}
+
+	if label != nil {
+		r.label = label.Lexeme
+	}
+
+	a.loc.Push(l)
+	a.labels.Push(r)
+}
+
+func (a *analyzer) exitLoop() {
+	a.loc.Pop()
+	a.labels.Pop()
+}
+
+// jumpTarget resolves the loop a `break` or `continue` statement would jump
+// out of. Only loops found on the `loc` stack before reaching a function
+// boundary can be targeted.
+func (a *analyzer) jumpTarget(label *tokens.Token, stmt string) (loopRecord, error) {
+	if a.loc.Size() == 0 {
+		return loopRecord{}, errors.StaticError{Msg: fmt.Sprintf("top level %s statements are not allowed", stmt)}
+	}
+
+	reachable := 0
+
+	a.loc.Walk(func(l staticloc) bool {
+		if l == function {
+			return false
+		}
+
+		if l == while || l == loop {
+			reachable++
+		}
+
+		return true
+	})
+
+	name := ""
+
+	if label != nil {
+		name = label.Lexeme
+	}
+
+	var target loopRecord
+	found := false
+	depth := 0
+
+	a.labels.Walk(func(r loopRecord) bool {
+		if name == "" || r.label == name {
+			target, found = r, true
+			return false
+		}
+
+		depth++
+		return true
+	})
+
+	if !found && name == "" {
+		return loopRecord{}, errors.StaticError{Msg: fmt.Sprintf("%s statements are only allowed in loops", stmt)}
+	}
+
+	if !found {
+		return loopRecord{}, errors.StaticError{Msg: fmt.Sprintf("label %q does not exist for %s statement", name, stmt)}
+	}
+
+	if depth >= reachable {
+		return loopRecord{}, errors.StaticError{Msg: fmt.Sprintf("%s statements cannot cross function boundaries", stmt)}
+	}
+
+	return target, nil
+}
+
 func (a *analyzer) VisitBinaryExpr(e ast.BinaryExpr) (_ interface{}, err error) {
 	err = a.analyzeNode(e.Left)
 
@@ -103,6 +182,14 @@ func (a *analyzer) VisitUnaryExpr(e ast.UnaryExpr) (interface{}, error) {
 	return nil, a.analyzeNode(e.Expr)
 }
 
+func (a *analyzer) VisitBytesLitExpr(e ast.BytesLiteralExpr) (interface{}, error) {
+	return nil, nil
+}
+
+func (a *analyzer) VisitRegexLitExpr(e ast.RegexLiteralExpr) (interface{}, error) {
+	return nil, nil
+}
+
 func (a *analyzer) VisitBottomLitExpr(e ast.BottomLiteralExpr) (interface{}, error) {
 	return nil, nil
 }
@@ -126,6 +213,21 @@ func (a *analyzer) VisitTupleLitExpr(e ast.TupleLiteralExpr) (interface{}, error
 	return nil, nil
 }
 
+func (a *analyzer) VisitSetLitExpr(e ast.SetLiteralExpr) (interface{}, error) {
+	a.loc.Push(set)
+	defer a.loc.Pop()
+
+	for _, e := range e.Contents {
+		err := a.analyzeNode(e)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
 func (a *analyzer) VisitRecordLitExpr(e ast.RecordLiteralExpr) (interface{}, error) {
 	for _, v := range e.Contents {
 		k := v.Key
@@ -148,8 +250,8 @@ func (a *analyzer) VisitRecordLitExpr(e ast.RecordLiteralExpr) (interface{}, err
 }
 
 func (a *analyzer) VisitSpreadExpr(e ast.SpreadExpr) (interface{}, error) {
-	if a.loc.Size() == 0 || (a.loc.Peek() != tuple && a.loc.Peek() != call) {
-		return nil, errors.StaticError{Msg: "Spread expressions can only appear immediately inside tuple literals or call expressions"}
+	if a.loc.Size() == 0 || (a.loc.Peek() != tuple && a.loc.Peek() != set && a.loc.Peek() != call) {
+		return nil, errors.StaticError{Msg: "Spread expressions can only appear immediately inside tuple literals, set literals or call expressions"}
 	}
 
 	err := a.analyzeNode(e.Expr)
@@ -162,7 +264,9 @@ func (a *analyzer) VisitSpreadExpr(e ast.SpreadExpr) (interface{}, error) {
 }
 
 func (a *analyzer) VisitIdentifierExpr(e ast.IdentifierExpr) (interface{}, error) {
-	if !a.env.Has(e.Name.Lexeme) {
+	_, global := value.Globals[e.Name.Lexeme]
+
+	if !a.env.Has(e.Name.Lexeme) && !global {
 		return nil, errors.StaticError{Msg: "Cannot reference an undeclared identifier."}
 	}
 
@@ -196,6 +300,10 @@ func (a *analyzer) VisitFuncExpr(e ast.FuncExpr) (interface{}, error) {
 		a.env.Add(n.Name.Lexeme, identRecord{mut: n.Mut})
 	}
 
+	if e.Generator {
+		a.markResumable(e.Body)
+	}
+
 	a.loc.Push(function)
 	defer a.loc.Pop()
 
@@ -241,7 +349,33 @@ func (a *analyzer) VisitMeExpr(e ast.MeExpr) (interface{}, error) {
 	return nil, nil
 }
 
+func (a *analyzer) VisitSuperExpr(e ast.SuperExpr) (interface{}, error) {
+	return nil, errors.StaticError{Msg: "'super' can only be used to access proto methods"}
+}
+
 func (a *analyzer) VisitProtoExpr(e ast.ProtoExpr) (interface{}, error) {
+	for _, parent := range e.Parents {
+		err := a.analyzeNode(parent)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pc := range e.Protocols {
+		err := a.analyzeNode(pc)
+
+		if err != nil {
+			return nil, err
+		}
+
+		err = a.checkProtocol(e, pc)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	for _, m := range e.MethodSet {
 		err := a.analyzeNode(m.K)
 
@@ -250,12 +384,23 @@ func (a *analyzer) VisitProtoExpr(e ast.ProtoExpr) (interface{}, error) {
 		}
 
 		// Set static location to be `proto_method` to ensure any `me` references
-		// are accepted
-		a.loc.Push(proto_method)
-		defer a.loc.Pop()
+		// are accepted. Plain members are not methods so they do not get `me`.
+		fn, method := m.M.(ast.FuncExpr)
+
+		if m.G && len(fn.Params) > 0 {
+			return nil, errors.StaticError{Msg: "getters cannot take parameters"}
+		}
+
+		if method {
+			a.loc.Push(proto_method)
+		}
 
 		err = a.analyzeNode(m.M)
 
+		if method {
+			a.loc.Pop()
+		}
+
 		if err != nil {
 			return nil, err
 		}
@@ -275,7 +420,17 @@ func (a *analyzer) VisitQuestionExpr(e ast.QuestionExpr) (interface{}, error) {
 }
 
 func (a *analyzer) VisitGetExpr(e ast.GetExpr) (interface{}, error) {
-	err := a.analyzeNode(e.Gettee)
+	var err error
+
+	// `super` is only meaningful as the target of a get expression so it is
+	// checked here rather than when visited on its own
+	if _, ok := e.Gettee.(ast.SuperExpr); ok {
+		if !a.loc.HasWith(func(a staticloc) bool { return a == proto_method }) {
+			return nil, errors.StaticError{Msg: "'super' can only be referenced in proto methods"}
+		}
+	} else {
+		err = a.analyzeNode(e.Gettee)
+	}
 
 	if err != nil {
 		return nil, err
@@ -290,6 +445,30 @@ func (a *analyzer) VisitGetExpr(e ast.GetExpr) (interface{}, error) {
 	return nil, nil
 }
 
+func (a *analyzer) VisitRangeExpr(e ast.RangeExpr) (interface{}, error) {
+	err := a.analyzeNode(e.Start)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.analyzeNode(e.End)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Step != nil {
+		err = a.analyzeNode(e.Step)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
 func (a *analyzer) VisitVarDeclStmt(s ast.VarDeclStmt) (interface{}, error) {
 	if len(s.Names) != len(s.Values) && len(s.Values) > 0 {
 		return nil, errors.StaticError{Msg: "If any variable is initialized, they all must be."}
@@ -321,6 +500,12 @@ func (a *analyzer) VisitAssignStmt(s ast.AssignmentStmt) (interface{}, error) {
 	}
 
 	for _, n := range s.Names {
+		_, global := value.Globals[n.Lexeme]
+
+		if !a.env.Has(n.Lexeme) && global {
+			return nil, errors.StaticError{Msg: fmt.Sprintf("Cannot re-assign global %q", n.Lexeme)}
+		}
+
 		if !a.env.Has(n.Lexeme) {
 			return nil, errors.StaticError{Msg: fmt.Sprintf("Cannot assign to undeclared variable %q", n.Lexeme)}
 		}
@@ -429,8 +614,8 @@ func (a *analyzer) VisitWhileStmt(s ast.WhileStmt) (interface{}, error) {
 		return nil, err
 	}
 
-	a.loc.Push(while)
-	defer a.loc.Pop()
+	a.enterLoop(s.Label, while)
+	defer a.exitLoop()
 
 	err = a.analyzeNode(s.Block)
 
@@ -441,34 +626,267 @@ func (a *analyzer) VisitWhileStmt(s ast.WhileStmt) (interface{}, error) {
 	return nil, nil
 }
 
-func (a *analyzer) VisitContStmt(s ast.ContinueStmt) (interface{}, error) {
-	if a.loc.Size() == 0 {
-		return nil, errors.StaticError{Msg: "top level continue statements are not allowed"}
+func (a *analyzer) VisitLoopExpr(e ast.LoopExpr) (interface{}, error) {
+	a.enterLoop(e.Label, loop)
+	defer a.exitLoop()
+
+	_, err := a.VisitBlock(e.Body)
+
+	if err != nil {
+		return nil, err
 	}
 
-	if a.loc.Peek() != while {
-		return nil, errors.StaticError{Msg: "continue statements are only allow in while loops"}
+	return nil, nil
+}
+
+func (a *analyzer) VisitContStmt(s ast.ContinueStmt) (interface{}, error) {
+	_, err := a.jumpTarget(s.Label, "continue")
+
+	if err != nil {
+		return nil, err
 	}
 
 	return nil, nil
 }
 
 func (a *analyzer) VisitBrkStmt(s ast.BreakStmt) (interface{}, error) {
+	label := s.Label
+
+	// The parser reads `break x;` as breaking with the value of `x` when no
+	// loop around it is labeled `x`. If there is no variable `x` either, `x`
+	// was meant as a label.
+	if id, ok := s.Expr.(ast.IdentifierExpr); ok && label == nil {
+		if _, global := value.Globals[id.Name.Lexeme]; !global && !a.env.Has(id.Name.Lexeme) {
+			label = &id.Name
+		}
+	}
+
+	target, err := a.jumpTarget(label, "break")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Expr == nil {
+		return nil, nil
+	}
+
+	if target.loc != loop {
+		return nil, errors.StaticError{Msg: "break statements can only carry a value out of loop expressions"}
+	}
+
+	return nil, a.analyzeNode(s.Expr)
+}
+
+func (a *analyzer) VisitDeferStmt(s ast.DeferStmt) (interface{}, error) {
 	if a.loc.Size() == 0 {
-		return nil, errors.StaticError{Msg: "top level break statements are not allowed"}
+		return nil, errors.StaticError{Msg: "top-level defer statements not allowed"}
+	}
+
+	if !a.loc.HasWith(func(v staticloc) bool { return v == function }) {
+		return nil, errors.StaticError{Msg: "defer statements can only be in functions and proto methods"}
+	}
+
+	return nil, a.analyzeNode(s.Expr)
+}
+
+func (a *analyzer) VisitYieldStmt(s ast.YieldStmt) (interface{}, error) {
+	if a.loc.Size() == 0 {
+		return nil, errors.StaticError{Msg: "top-level yield statements not allowed"}
+	}
+
+	if !a.loc.HasWith(func(v staticloc) bool { return v == function }) {
+		return nil, errors.StaticError{Msg: "yield statements can only be in functions and proto methods"}
+	}
+
+	if !a.resumable[s.Token] {
+		return nil, errors.StaticError{Msg: "yield statements cannot be nested in expressions"}
+	}
+
+	return nil, a.analyzeNode(s.Expr)
+}
+
+// markResumable records the yields of a generator body that are reached only
+// through statements. A generator is suspended between statements, so these
+// are the only yields it can pause at: a yield inside an expression, such as
+// the body of a `loop` being assigned, would be part way through evaluating it.
+func (a *analyzer) markResumable(n ast.Node) {
+	switch n := n.(type) {
+	case ast.YieldStmt:
+		a.resumable[n.Token] = true
+
+	case ast.Block:
+		for _, c := range n.Contents {
+			a.markResumable(c)
+		}
+
+	case ast.IfStmt:
+		a.markResumable(n.Then)
+
+		if n.Else != nil {
+			a.markResumable(n.Else)
+		}
+
+	case ast.WhileStmt:
+		a.markResumable(n.Block)
+
+	case ast.LoopExpr:
+		a.markResumable(n.Body)
+
+	case ast.TryStmt:
+		a.markResumable(n.Body)
+		a.markResumable(n.Catch)
+	}
+}
+
+func (a *analyzer) VisitThrowStmt(s ast.ThrowStmt) (interface{}, error) {
+	err := a.analyzeNode(s.Expr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Data != nil {
+		return nil, a.analyzeNode(s.Data)
+	}
+
+	return nil, nil
+}
+
+func (a *analyzer) VisitAssertStmt(s ast.AssertStmt) (interface{}, error) {
+	// Assertions whose outcome is fixed by literals are almost certainly mistakes
+	if v, ok := literal(s.Expr); ok {
+		if b, ok := v.(bool); ok {
+			return nil, errors.StaticError{Msg: fmt.Sprintf("assertion `%s` is always %t", s.Source, b)}
+		}
+	}
+
+	err := a.analyzeNode(s.Expr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Message != nil {
+		return nil, a.analyzeNode(s.Message)
+	}
+
+	return nil, nil
+}
+
+func (a *analyzer) VisitProtocolStmt(s ast.ProtocolStmt) (interface{}, error) {
+	for _, m := range s.Methods {
+		err := a.analyzeNode(m.K)
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = strconv.ParseUint(m.Arity.Lexeme, 10, 64)
+
+		if err != nil {
+			return nil, errors.StaticError{Msg: "Protocol method arity must be a non-negative integer"}
+		}
 	}
 
-	if a.loc.Peek() != while {
-		return nil, errors.StaticError{Msg: "break statements are only allow in while loops"}
+	if a.env.HasDirectly(s.Name.Lexeme) {
+		return nil, errors.StaticError{Msg: fmt.Sprintf("Cannot redeclare variable %q", s.Name.Lexeme)}
 	}
 
+	a.env.Add(s.Name.Lexeme, identRecord{mut: false, protocol: &s})
+
 	return nil, nil
 }
 
+// checkProtocol verifies that a proto literal implements a protocol when both
+// the protocol's declaration and all of the relevant keys can be seen
+// statically. Protos with parents are left to the interpreter.
+func (a *analyzer) checkProtocol(e ast.ProtoExpr, ref ast.Expr) error {
+	id, ok := ref.(ast.IdentifierExpr)
+
+	if !ok || len(e.Parents) > 0 || !a.env.Has(id.Name.Lexeme) {
+		return nil
+	}
+
+	pc := a.env.Get(id.Name.Lexeme).protocol
+
+	if pc == nil {
+		return nil
+	}
+
+	members := map[interface{}]ast.ProtoMethod{}
+
+	for _, m := range e.MethodSet {
+		k, ok := literal(m.K)
+
+		if !ok {
+			return nil
+		}
+
+		members[k] = m
+	}
+
+	for _, pm := range pc.Methods {
+		k, ok := literal(pm.K)
+
+		if !ok {
+			return nil
+		}
+
+		m, ok := members[k]
+
+		if !ok {
+			return errors.StaticError{Msg: fmt.Sprintf("proto does not implement protocol %q: method %s is missing", pc.Name.Lexeme, displayConstant(k))}
+		}
+
+		// Members that are not function literals, such as a function held
+		// in a variable, are only known at runtime, where they are checked
+		fn, isFn := m.M.(ast.FuncExpr)
+
+		if !isFn {
+			continue
+		}
+
+		n, _ := strconv.Atoi(pm.Arity.Lexeme)
+		arity := len(fn.Params)
+		rest := arity > 0 && fn.Params[arity-1].Rest
+
+		if rest {
+			arity--
+		}
+
+		if arity != n && !(rest && arity <= n) {
+			return errors.StaticError{Msg: fmt.Sprintf("proto does not implement protocol %q: method %s takes %d parameters, expected %d", pc.Name.Lexeme, displayConstant(k), arity, n)}
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) VisitTryStmt(s ast.TryStmt) (interface{}, error) {
+	err := a.analyzeNode(s.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The caught error is bound in its own scope surrounding the `catch` block
+	a.newScope()
+	defer a.endScope()
+
+	if s.Name != nil {
+		a.env.Add(s.Name.Lexeme, identRecord{mut: false})
+	}
+
+	return nil, a.analyzeNode(s.Catch)
+}
+
 func New() *analyzer {
 	return &analyzer{
 		env:           environment.New[identRecord](nil),
 		loc:           stack.New[staticloc](),
+		labels:        stack.New[loopRecord](),
 		satisfactions: stack.New[satisfaction](),
+		resumable:     map[tokens.Token]bool{},
 	}
 }
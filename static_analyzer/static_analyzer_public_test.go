@@ -4,6 +4,7 @@ import (
 	"calabash/lexer/scanner"
 	"calabash/parser"
 	staticanalyzer "calabash/static_analyzer"
+	"strings"
 	"testing"
 )
 
@@ -65,6 +66,14 @@ func TestAnalyze(t *testing.T) {
 				name: "identifier expressions",
 				text: "let a; a",
 			},
+			{
+				name: "globals are visible in functions without closures",
+				text: "fn (x) -> math->'sqrt'(x)",
+			},
+			{
+				name: "globals can be shadowed",
+				text: "let math = 1; math",
+			},
 			{
 				name: "function expressions",
 				text: "fn (a, mut b) -> a + b",
@@ -85,6 +94,38 @@ func TestAnalyze(t *testing.T) {
 				name: "proto expression",
 				text: "proto { true -> fn(a) -> a }",
 			},
+			{
+				name: "proto extending another proto",
+				text: "let a = proto { true -> fn() -> 1 }; proto extends a { true -> fn() -> super->true() }",
+			},
+			{
+				name: "proto with plain members",
+				text: "let a = 1; proto { 'a' -> a + 1, 'b' -> proto { 'c' -> fn () -> me } }",
+			},
+			{
+				name: "proto with getters and missing method",
+				text: "proto { 'a' -> get fn () -> me, 'missing' ->< fn (k) -> k }",
+			},
+			{
+				name: "proto implementing protocol",
+				text: "protocol Shape { 'stringify' -> 0, 'compare' -> 1 } proto implements Shape { 'stringify' -> fn () -> 'a', 'compare' -> fn (o) -> 0, 'other' -> 1 }",
+			},
+			{
+				name: "proto implementing protocol with rest parameter",
+				text: "protocol Shape { 'compare' -> 2 } proto implements Shape { 'compare' -> fn (o, ...os) -> 0 }",
+			},
+			{
+				name: "proto implementing protocol with a member held in a variable",
+				text: "protocol Show { 'show' -> 0 } let f = fn () -> 'x'; proto implements Show { 'show' -> f }",
+			},
+			{
+				name: "proto implementing protocol with a plain member, left to the runtime check",
+				text: "protocol Shape { 'compare' -> 1 } proto implements Shape { 'compare' -> 0 }",
+			},
+			{
+				name: "proto implementing protocol through parent",
+				text: "protocol Shape { 'compare' -> 1 } let a = proto { 'compare' -> fn (o) -> 0 }; proto extends a implements Shape { 'x' -> 1 }",
+			},
 			{
 				name: "me expression",
 				text: "proto { true -> fn() -> me }",
@@ -121,6 +162,18 @@ func TestAnalyze(t *testing.T) {
 				name: "spread expression in function call",
 				text: "fn(a, b, c) {}([1,2,3]...)",
 			},
+			{
+				name: "spread expression in set literal",
+				text: "#{1, [2, 3]...}",
+			},
+			{
+				name: "range expression",
+				text: "let a, b = 1, 10; a..=b step 2",
+			},
+			{
+				name: "spread range expression",
+				text: "[(0..3)...]",
+			},
 			{
 				name: "assignment statement",
 				text: "let mut a; a = 1;",
@@ -169,6 +222,30 @@ func TestAnalyze(t *testing.T) {
 				name: "while statement with variable lookup in block",
 				text: "let a = 1; while a == 1 { a }",
 			},
+			{
+				name: "labeled break out of nested while statements",
+				text: "outer: while true { while true { break outer; } }",
+			},
+			{
+				name: "labeled continue out of nested while statements",
+				text: "outer: while true { while true { continue outer; } }",
+			},
+			{
+				name: "loop expression with break value",
+				text: "let a = loop { break 1; };",
+			},
+			{
+				name: "labeled break with value out of nested loops",
+				text: "let a = outer: loop { while true { break outer 1; } };",
+			},
+			{
+				name: "break in loop inside a function inside a loop",
+				text: "while true { fn () { loop { break; } } }",
+			},
+			{
+				name: "loop expression nested in a tuple",
+				text: "outer: while true { [loop { break outer; }] }",
+			},
 			{
 				name: "return statement in function",
 				text: "fn () { return 1; }",
@@ -177,6 +254,58 @@ func TestAnalyze(t *testing.T) {
 				name: "return statement in proto methods",
 				text: "proto { 'a' -> fn() { return 2; } }",
 			},
+			{
+				name: "defer statement in function",
+				text: "fn () { defer 1; }",
+			},
+			{
+				name: "defer statement in proto methods",
+				text: "proto { 'a' -> fn() { defer me; } }",
+			},
+			{
+				name: "defer statement in loop inside function",
+				text: "fn () { while true { defer 1; } }",
+			},
+			{
+				name: "yield statement in function",
+				text: "fn () { yield 1; }",
+			},
+			{
+				name: "yield statement in proto methods",
+				text: "proto { 'a' -> fn() { yield me; } }",
+			},
+			{
+				name: "yield statements in nested statements",
+				text: "fn () { while true { if true { try { yield 1; } catch { loop { yield 2; } } } else { yield 3; } } }",
+			},
+			{
+				name: "top level throw statement",
+				text: "throw 'a', [1];",
+			},
+			{
+				name: "assert statement",
+				text: "let a = 1; assert a == 1, 'a is one';",
+			},
+			{
+				name: "assert statement comparing literal with variable",
+				text: "let a = 1; assert !(a < 0);",
+			},
+			{
+				name: "assert statement comparing integers a float cannot tell apart",
+				text: "assert 9007199254740993 == 9007199254740992;",
+			},
+			{
+				name: "break with the value of a variable",
+				text: "let v = 1; loop { break v; }",
+			},
+			{
+				name: "try/catch referencing caught error",
+				text: "try { throw 'a'; } catch e { e }",
+			},
+			{
+				name: "try/catch shadowing caught error",
+				text: "let e = 1; try {} catch e { let e = 2; }",
+			},
 			{
 				name: "referencing variable declared in full closure",
 				text: "let a, b = 3, fn<> () -> a;",
@@ -261,6 +390,62 @@ func TestAnalyze(t *testing.T) {
 				name: "me used outside of proto",
 				text: "me",
 			},
+			{
+				name: "step modifier without a range",
+				text: "0 step 2",
+			},
+			{
+				name: "getter modifier after the inherit marker",
+				text: "proto { 'a' -> get < fn () -> 1 }",
+			},
+			{
+				name: "getter with parameters",
+				text: "proto { 'a' -> get fn (b) -> b }",
+			},
+			{
+				name: "proto missing protocol method",
+				text: "protocol Shape { 'stringify' -> 0, 'compare' -> 1 } proto implements Shape { 'stringify' -> fn () -> 'a' }",
+			},
+			{
+				name: "proto implementing protocol method with wrong arity",
+				text: "protocol Shape { 'compare' -> 1 } proto implements Shape { 'compare' -> fn () -> 0 }",
+			},
+			{
+				name: "protocol with non-integer arity",
+				text: "protocol Shape { 'compare' -> 1.5 }",
+			},
+			{
+				name: "protocol redeclaring variable",
+				text: "let Shape = 1; protocol Shape { 'compare' -> 1 }",
+			},
+			{
+				name: "proto implementing undeclared protocol",
+				text: "proto implements Shape { 'compare' -> 0 }",
+			},
+			{
+				name: "me used in plain proto member",
+				text: "proto { 'a' -> me }",
+			},
+			{
+				name: "me used as key after proto method",
+				text: "proto { 'a' -> fn () -> 1, me -> 2 }",
+			},
+			{
+				name: "super used outside of proto",
+				text: "let a = 'a'; super->'upper'",
+			},
+			{
+				name: "super used on its own",
+				text: "proto { 'a' -> fn () -> super }",
+			},
+			{
+				name: "proto extending several protos with undeclared identifier",
+				text: "let a = proto { 'a' -> 1 }; proto extends a, b { 'a' -> fn() -> 1 }",
+			},
+			{
+				name: "proto extending undeclared identifier",
+				text: "proto extends a { 'a' -> fn() -> 1 }",
+			},
 			{
 				name: "proto with 'me' used as key",
 				text: "proto { me -> fn () -> 1 }",
@@ -305,6 +490,14 @@ func TestAnalyze(t *testing.T) {
 				name: "question mark not referenced in an inner pipe expression",
 				text: "1 |> ? + (1 |> 1)",
 			},
+			{
+				name: "range expression with undeclared identifier",
+				text: "0..a",
+			},
+			{
+				name: "range expression with undeclared identifier step",
+				text: "0..10 step a",
+			},
 			{
 				name: "var declaration with undeclared identifier expression",
 				text: "let a = b;",
@@ -321,6 +514,10 @@ func TestAnalyze(t *testing.T) {
 				name: "assignment to immutable variable",
 				text: "let a; a = 1;",
 			},
+			{
+				name: "assignment to a global",
+				text: "math = 1;",
+			},
 			{
 				name: "assignment with undeclared identifier expression",
 				text: "let mut a; a = b;",
@@ -369,6 +566,82 @@ func TestAnalyze(t *testing.T) {
 				name: "return statements in loop",
 				text: "while true { return 3; }",
 			},
+			{
+				name: "top level defer statements",
+				text: "defer 3;",
+			},
+			{
+				name: "defer statements in loop",
+				text: "while true { defer 3; }",
+			},
+			{
+				name: "top level yield statements",
+				text: "yield 3;",
+			},
+			{
+				name: "yield statements in loop",
+				text: "while true { yield 3; }",
+			},
+			{
+				name: "yield statements in loop expressions",
+				text: "fn () { let a = loop { yield 1; }; }",
+			},
+			{
+				name: "yield statements in tuples",
+				text: "fn () { [1, loop { yield 1; }] }",
+			},
+			{
+				name: "yield statement with undeclared identifier",
+				text: "fn () { yield a; }",
+			},
+			{
+				name: "defer statement with undeclared identifier",
+				text: "fn () { defer a; }",
+			},
+			{
+				name: "throw statement with undeclared identifier",
+				text: "throw a;",
+			},
+			{
+				name: "throw statement with undeclared identifier in data",
+				text: "throw 'a', a;",
+			},
+			{
+				name: "assert statement with undeclared identifier",
+				text: "assert a;",
+			},
+			{
+				name: "assert statement with undeclared identifier in message",
+				text: "assert 1 == 1 + 0, a;",
+			},
+			{
+				name: "assert statement that is always true",
+				text: "assert true;",
+			},
+			{
+				name: "assert statement that is always false",
+				text: "assert 1 > 2, 'never';",
+			},
+			{
+				name: "assert statement that is always true with literal equality",
+				text: "assert !('a' == 'b') && (-1 <= 0);",
+			},
+			{
+				name: "assert statement that is always true at the largest exact float integer",
+				text: "assert 9007199254740992 == 9007199254740992.0;",
+			},
+			{
+				name: "caught error referenced outside of catch block",
+				text: "try {} catch e {} e",
+			},
+			{
+				name: "caught error is immutable",
+				text: "try {} catch e { e = 1; }",
+			},
+			{
+				name: "try block with undeclared identifier",
+				text: "try { a } catch {}",
+			},
 			{
 				name: "top level continue statement",
 				text: "continue;",
@@ -393,6 +666,34 @@ func TestAnalyze(t *testing.T) {
 				name: "break statement not directly in a loop",
 				text: "while true { fn () { break; } }",
 			},
+			{
+				name: "break to an undeclared label",
+				text: "while true { break outer; }",
+			},
+			{
+				name: "continue to an undeclared label",
+				text: "while true { continue outer; }",
+			},
+			{
+				name: "break to the label of a sibling loop",
+				text: "outer: while true {} while true { break outer; }",
+			},
+			{
+				name: "labeled break crossing a function boundary",
+				text: "outer: while true { fn () { break outer; } }",
+			},
+			{
+				name: "labeled break crossing a function boundary from an inner loop",
+				text: "outer: while true { fn () { loop { break outer; } } }",
+			},
+			{
+				name: "break with a value out of a while statement",
+				text: "while true { break 1; }",
+			},
+			{
+				name: "break value with undeclared identifier",
+				text: "loop { break (a); }",
+			},
 			{
 				name: "closures with non-integer limits",
 				text: "fn<1.2> () {}",
@@ -427,4 +728,15 @@ func TestAnalyze(t *testing.T) {
 			}
 		}
 	})
+	t.Run("breaks to unknown labels report the label", func(t *testing.T) {
+		for _, text := range []string{"while true { break typo; }", "outer: loop {} loop { break outer; }"} {
+			ts, _ := scanner.New().Read(text)
+			ast, _ := parser.New(ts).Parse()
+			err := staticanalyzer.New().Analyze(ast)
+
+			if err == nil || !strings.Contains(err.Error(), "does not exist for break statement") {
+				t.Errorf("%q: expected an unknown label error, got %v", text, err)
+			}
+		}
+	})
 }